@@ -2,18 +2,16 @@ package app
 
 import (
 	"context"
-	"net/http"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
 	"time"
 
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/stellar/go/clients/horizonclient"
-	"github.com/stellar/go/keypair"
 	"github.com/stellar/go/support/db"
 	"github.com/stellar/go/support/log"
 
@@ -21,6 +19,7 @@ import (
 	"github.com/stellar/starbridge/controllers"
 	"github.com/stellar/starbridge/ethereum"
 	"github.com/stellar/starbridge/httpx"
+	"github.com/stellar/starbridge/stellar/horizonpool"
 	"github.com/stellar/starbridge/stellar/signer"
 	"github.com/stellar/starbridge/stellar/txbuilder"
 	"github.com/stellar/starbridge/stellar/txobserver"
@@ -34,7 +33,11 @@ type App struct {
 	httpServer      *httpx.Server
 	worker          *backend.Worker
 	session         *db.Session
+	horizonPool     *horizonpool.Pool
+	notifier        *backend.SignatureNotifier
 	stellarObserver *txobserver.Observer
+	assetMappings   *backend.AssetMappings
+	transferLimits  backend.TransferLimits
 
 	prometheusRegistry *prometheus.Registry
 }
@@ -45,20 +48,80 @@ type Config struct {
 
 	PostgresDSN string `toml:"postgres_dsn" valid:"-"`
 
-	HorizonURL           string `toml:"horizon_url" valid:"-"`
+	HorizonURL string `toml:"horizon_url" valid:"-"`
+	// HorizonURLs are additional Horizon instances used when the previous
+	// ones are unhealthy
+	HorizonURLs []string `toml:"horizon_urls" valid:"-"`
+	// HorizonTimeout is the timeout of a single request to Horizon, 10s by
+	// default
+	HorizonTimeout string `toml:"horizon_timeout" valid:"-"`
+	// HorizonCrossCheck requires two sources to agree on every ingested
+	// Stellar ledger. With the horizon ledger backend at least two Horizon
+	// urls are required, other backends are checked against Horizon.
+	HorizonCrossCheck    bool   `toml:"horizon_cross_check" valid:"-"`
 	NetworkPassphrase    string `toml:"network_passphrase" valid:"-"`
 	StellarBridgeAccount string `toml:"stellar_bridge_account" valid:"stellar_accountid"`
 	StellarPrivateKey    string `toml:"stellar_private_key" valid:"stellar_seed"`
-
-	EthereumRPCURL              string `toml:"ethereum_rpc_url" valid:"-"`
-	EthereumBridgeAddress       string `toml:"ethereum_bridge_address" valid:"-"`
+	// StellarKeyBackend configures where the Stellar validator key is
+	// stored. StellarPrivateKey is used if it is not set.
+	StellarKeyBackend KeyBackendConfig `toml:"stellar_key_backend" valid:"-"`
+	// StellarWithdrawalMode is one of payment (default) or claimable_balance.
+	// It must be the same on all validators.
+	StellarWithdrawalMode string `toml:"stellar_withdrawal_mode" valid:"-"`
+	// WorkerConcurrency is the number of signature requests processed
+	// concurrently, 4 by default
+	WorkerConcurrency int `toml:"worker_concurrency" valid:"-"`
+	// StellarLedgerBackend is the source of ingested Stellar ledgers. It is
+	// one of horizon (default), archive or captive_core.
+	StellarLedgerBackend      string   `toml:"stellar_ledger_backend" valid:"-"`
+	StellarHistoryArchiveURLs []string `toml:"stellar_history_archive_urls" valid:"-"`
+	// StellarCaptiveCoreBinaryPath and StellarCaptiveCoreConfigPath are
+	// used by the captive_core ledger backend
+	StellarCaptiveCoreBinaryPath string `toml:"stellar_captive_core_binary_path" valid:"-"`
+	StellarCaptiveCoreConfigPath string `toml:"stellar_captive_core_config_path" valid:"-"`
+	// StellarIngestStartLedger is the ledger from which Stellar ingestion
+	// starts when the database is empty. Ingestion is replayed from the
+	// checkpoint containing it. It is required by the archive and
+	// captive_core ledger backends.
+	StellarIngestStartLedger uint32 `toml:"stellar_ingest_start_ledger" valid:"-"`
+
+	EthereumRPCURL string `toml:"ethereum_rpc_url" valid:"-"`
+	// EthereumRPCURLs are additional ethereum nodes. Results of queries
+	// must match on EthereumRPCQuorum nodes, a majority by default.
+	EthereumRPCURLs       []string `toml:"ethereum_rpc_urls" valid:"-"`
+	EthereumRPCQuorum     int      `toml:"ethereum_rpc_quorum" valid:"-"`
+	EthereumBridgeAddress string   `toml:"ethereum_bridge_address" valid:"-"`
+	// EthereumBridgeConfigVersion is the bridge contract version used until
+	// the first RegisterSigners event is ingested. Afterwards the version
+	// is taken from the most recent RegisterSigners event.
 	EthereumBridgeConfigVersion uint32 `toml:"ethereum_bridge_config_version" valid:"-"`
 	EthereumPrivateKey          string `toml:"ethereum_private_key" valid:"-"`
-
+	// EthereumKeyBackend configures where the ethereum validator key is
+	// stored. EthereumPrivateKey is used if it is not set.
+	EthereumKeyBackend KeyBackendConfig `toml:"ethereum_key_backend" valid:"-"`
+	// EthereumStartBlock is the block from which the ethereum ingester starts
+	// indexing bridge events, typically the block containing the deployment
+	// of the bridge smart contract
+	EthereumStartBlock uint64 `toml:"ethereum_start_block" valid:"-"`
+	// EthereumFinalityMode determines when ethereum blocks are considered
+	// final. It is one of fixed_depth, safe or finalized.
+	EthereumFinalityMode string `toml:"ethereum_finality_mode" valid:"-"`
+	// EthereumFinalityBuffer is the number of confirmations required for
+	// an ethereum block to be final in fixed_depth mode
+	EthereumFinalityBuffer uint64 `toml:"ethereum_finality_buffer" valid:"-"`
+	// StellarAssetArtifactPath is the path to the hardhat compilation
+	// artifact of the StellarAsset contract. It is optional and used to
+	// compute the address of tokens created by registerStellarAsset.
+	StellarAssetArtifactPath string `toml:"stellar_asset_artifact_path" valid:"-"`
+
+	// AssetMapping contains the asset mappings which are always active.
+	// Additional mappings can be added at runtime through the admin api.
 	AssetMapping []backend.AssetMappingConfigEntry `toml:"asset_mapping" valid:"-"`
+	// TransferLimits configures the transfer sizes and volume caps
+	// enforced on deposits
+	TransferLimits backend.TransferLimitsConfig `toml:"transfer_limits" valid:"-"`
 
-	EthereumFinalityBuffer uint64        `toml:"-" valid:"-"`
-	WithdrawalWindow       time.Duration `toml:"-" valid:"-"`
+	WithdrawalWindow time.Duration `toml:"-" valid:"-"`
 }
 
 func NewApp(config Config) *App {
@@ -66,29 +129,63 @@ func NewApp(config Config) *App {
 		prometheusRegistry: prometheus.NewRegistry(),
 	}
 
-	client := &horizonclient.Client{
-		HorizonURL: config.HorizonURL,
-		// TODO set proper timeouts
-		HTTP: http.DefaultClient,
+	horizonTimeout, err := parseHorizonTimeout(config)
+	if err != nil {
+		log.Fatal(err)
 	}
+	app.horizonPool, err = horizonpool.NewPool(horizonURLs(config), horizonTimeout)
+	if err != nil {
+		log.Fatalf("cannot create horizon pool: %v", err)
+	}
+	client := app.horizonPool.Client()
 
 	app.initDB(config)
 	app.initGracefulShutdown()
+	assetMappings, err := backend.NewAssetMappings(app.appCtx, app.NewStore(), config.AssetMapping)
+	if err != nil {
+		log.Fatalf("unable to load asset mappings: %v", err)
+	}
+	app.assetMappings = assetMappings
+	app.transferLimits, err = backend.NewTransferLimits(config.TransferLimits)
+	if err != nil {
+		log.Fatalf("invalid transfer limits: %v", err)
+	}
+	ledgerBackend, err := NewStellarLedgerBackend(config, client, horizonTimeout)
+	if err != nil {
+		log.Fatalf("cannot create stellar ledger backend: %v", err)
+	}
 	app.stellarObserver = txobserver.NewObserver(
 		config.StellarBridgeAccount,
-		client,
+		ledgerBackend,
 		app.NewStore(),
+		config.StellarIngestStartLedger,
 	)
-	ethRPCClient, err := ethclient.Dial(config.EthereumRPCURL)
+	ethRPCEndpoints, err := NewEthereumRPCEndpoints(config)
 	if err != nil {
-		log.WithField("err", err).Fatal("could not dial ethereum node")
+		log.WithField("err", err).Fatal("could not dial ethereum nodes")
 	}
-	ethObserver, err := ethereum.NewObserver(ethRPCClient, config.EthereumBridgeAddress)
+	ethObserver, err := ethereum.NewObserver(
+		ethRPCEndpoints,
+		ethereumRPCQuorum(config),
+		config.EthereumBridgeAddress,
+		ethereum.Finality{
+			Mode:  ethereum.FinalityMode(config.EthereumFinalityMode),
+			Depth: config.EthereumFinalityBuffer,
+		},
+	)
 	if err != nil {
 		log.WithField("err", err).Fatal("could not create ethereum observer")
 	}
-	app.initHTTP(config, client, ethObserver)
-	app.initWorker(config, client, ethObserver)
+	ethSigner, err := NewEthereumSigner(config)
+	if err != nil {
+		log.Fatalf("cannot create ethereum signer: %v", err)
+	}
+	stellarSigner, err := NewStellarSigner(config)
+	if err != nil {
+		log.Fatalf("cannot create stellar signer: %v", err)
+	}
+	app.initHTTP(config, client, ethObserver, ethSigner, stellarSigner)
+	app.initWorker(config, client, ethObserver, ethSigner, stellarSigner)
 	app.initLogger()
 	app.initPrometheus()
 
@@ -114,6 +211,8 @@ func (a *App) initGracefulShutdown() {
 
 func (a *App) initPrometheus() {
 	a.httpServer.RegisterMetrics(a.prometheusRegistry)
+	a.worker.EthereumIngester.RegisterMetrics(a.prometheusRegistry)
+	a.worker.EthereumObserver.RegisterMetrics(a.prometheusRegistry)
 }
 
 func (a *App) initLogger() {
@@ -127,62 +226,62 @@ func (a *App) initDB(config Config) {
 	}
 
 	a.session = session
+	a.notifier = backend.NewSignatureNotifier(config.PostgresDSN)
 	err = store.InitSchema(session.DB.DB)
 	if err != nil {
 		log.Fatalf("cannot init DB: %v", err)
 	}
 }
 
-func (a *App) initWorker(config Config, client *horizonclient.Client, ethObserver ethereum.Observer) {
-	var (
-		signerKey *keypair.Full
-		err       error
-	)
-	if config.StellarPrivateKey != "" {
-		signerKey, err = keypair.ParseFull(config.StellarPrivateKey)
-		if err != nil {
-			log.Fatalf("cannot pase signer secret key: %v", err)
-		}
+func (a *App) initWorker(
+	config Config,
+	client *horizonclient.Client,
+	ethObserver ethereum.Observer,
+	ethSigner ethereum.Signer,
+	stellarSigner *signer.Signer,
+) {
+	withdrawalMode := backend.StellarWithdrawalMode(config.StellarWithdrawalMode)
+	switch withdrawalMode {
+	case "":
+		withdrawalMode = backend.PaymentWithdrawalMode
+	case backend.PaymentWithdrawalMode, backend.ClaimableBalanceWithdrawalMode:
+	default:
+		log.Fatalf("invalid stellar withdrawal mode: %s", config.StellarWithdrawalMode)
 	}
-
-	converter, err := backend.NewAssetConverter(config.AssetMapping)
-	if err != nil {
-		log.Fatal("unable to create asset converter", err)
-	}
-
-	ethSigner, err := ethereum.NewSigner(config.EthereumPrivateKey, config.EthereumBridgeConfigVersion)
-	if err != nil {
-		log.Fatalf("cannot create ethereum signer: %v", err)
-	}
-
 	a.worker = &backend.Worker{
 		Store:         a.NewStore(),
 		StellarClient: client,
 		StellarBuilder: &txbuilder.Builder{
 			BridgeAccount: config.StellarBridgeAccount,
 		},
-		StellarSigner: &signer.Signer{
-			NetworkPassphrase: config.NetworkPassphrase,
-			Signer:            signerKey,
-		},
-		StellarObserver: a.stellarObserver,
-		EthereumSigner:  ethSigner,
+		StellarSigner:    stellarSigner,
+		StellarObserver:  a.stellarObserver,
+		EthereumObserver: ethObserver,
+		EthereumIngester: ethereum.NewIngester(
+			ethObserver,
+			a.NewStore(),
+			config.EthereumStartBlock,
+		),
+		EthereumSigner:        ethSigner,
+		StellarWithdrawalMode: withdrawalMode,
+		Concurrency:           config.WorkerConcurrency,
 		StellarWithdrawalValidator: backend.StellarWithdrawalValidator{
 			Session:          a.session.Clone(),
 			WithdrawalWindow: config.WithdrawalWindow,
-			Converter:        converter,
+			AssetMappings:    a.assetMappings,
+			TransferLimits:   a.transferLimits,
 		},
 		StellarRefundValidator: backend.StellarRefundValidator{
-			Session:                a.session.Clone(),
-			WithdrawalWindow:       config.WithdrawalWindow,
-			Observer:               ethObserver,
-			EthereumFinalityBuffer: config.EthereumFinalityBuffer,
+			Session:          a.session.Clone(),
+			WithdrawalWindow: config.WithdrawalWindow,
+			Observer:         ethObserver,
 		},
 		EthereumWithdrawalValidator: backend.EthereumWithdrawalValidator{
-			Observer:               ethObserver,
-			EthereumFinalityBuffer: config.EthereumFinalityBuffer,
-			WithdrawalWindow:       config.WithdrawalWindow,
-			Converter:              converter,
+			Session:          a.session.Clone(),
+			Observer:         ethObserver,
+			WithdrawalWindow: config.WithdrawalWindow,
+			AssetMappings:    a.assetMappings,
+			TransferLimits:   a.transferLimits,
 		},
 		EthereumRefundValidator: backend.EthereumRefundValidator{
 			Session:          a.session.Clone(),
@@ -191,10 +290,26 @@ func (a *App) initWorker(config Config, client *horizonclient.Client, ethObserve
 	}
 }
 
-func (a *App) initHTTP(config Config, client *horizonclient.Client, ethObserver ethereum.Observer) {
-	converter, err := backend.NewAssetConverter(config.AssetMapping)
-	if err != nil {
-		log.Fatal("unable to create asset converter", err)
+func (a *App) initHTTP(
+	config Config,
+	client *horizonclient.Client,
+	ethObserver ethereum.Observer,
+	ethSigner ethereum.Signer,
+	stellarSigner *signer.Signer,
+) {
+	var stellarAssetBytecode []byte
+	if config.StellarAssetArtifactPath != "" {
+		var err error
+		stellarAssetBytecode, err = ethereum.LoadContractBytecode(config.StellarAssetArtifactPath)
+		if err != nil {
+			log.Fatalf("cannot load StellarAsset bytecode: %v", err)
+		}
+	}
+
+	// The demo deposit handler uses the first asset mapping from the config file
+	var testDepositToken string
+	if len(config.AssetMapping) > 0 {
+		testDepositToken = config.AssetMapping[0].EthereumToken
 	}
 
 	httpServer, err := httpx.NewServer(httpx.ServerConfig{
@@ -209,18 +324,21 @@ func (a *App) initHTTP(config Config, client *horizonclient.Client, ethObserver
 			StellarWithdrawalValidator: backend.StellarWithdrawalValidator{
 				Session:          a.session.Clone(),
 				WithdrawalWindow: config.WithdrawalWindow,
-				Converter:        converter,
+				AssetMappings:    a.assetMappings,
+				TransferLimits:   a.transferLimits,
 			},
-			EthereumFinalityBuffer: config.EthereumFinalityBuffer,
+			SignatureNotifier: a.notifier,
 		},
 		EthereumWithdrawalHandler: &controllers.EthereumWithdrawalHandler{
 			Store: a.NewStore(),
 			EthereumWithdrawalValidator: backend.EthereumWithdrawalValidator{
-				Observer:               ethObserver,
-				EthereumFinalityBuffer: config.EthereumFinalityBuffer,
-				WithdrawalWindow:       config.WithdrawalWindow,
-				Converter:              converter,
+				Session:          a.session.Clone(),
+				Observer:         ethObserver,
+				WithdrawalWindow: config.WithdrawalWindow,
+				AssetMappings:    a.assetMappings,
+				TransferLimits:   a.transferLimits,
 			},
+			SignatureNotifier: a.notifier,
 		},
 		EthereumRefundHandler: &controllers.EthereumRefundHandler{
 			Observer: ethObserver,
@@ -229,23 +347,75 @@ func (a *App) initHTTP(config Config, client *horizonclient.Client, ethObserver
 				Session:          a.session.Clone(),
 				WithdrawalWindow: config.WithdrawalWindow,
 			},
-			EthereumFinalityBuffer: config.EthereumFinalityBuffer,
+			SignatureNotifier: a.notifier,
 		},
 		StellarRefundHandler: &controllers.StellarRefundHandler{
 			StellarClient: client,
 			Store:         a.NewStore(),
 			StellarRefundValidator: backend.StellarRefundValidator{
-				Session:                a.session.Clone(),
-				WithdrawalWindow:       config.WithdrawalWindow,
-				Observer:               ethObserver,
-				EthereumFinalityBuffer: config.EthereumFinalityBuffer,
+				Session:          a.session.Clone(),
+				WithdrawalWindow: config.WithdrawalWindow,
+				Observer:         ethObserver,
 			},
+			SignatureNotifier: a.notifier,
+		},
+		StellarDepositStatusHandler: &controllers.StellarDepositStatusHandler{
+			Store:            a.NewStore(),
+			WithdrawalWindow: config.WithdrawalWindow,
+		},
+		EthereumDepositStatusHandler: &controllers.EthereumDepositStatusHandler{
+			Observer:         ethObserver,
+			Store:            a.NewStore(),
+			WithdrawalWindow: config.WithdrawalWindow,
+		},
+		InvalidStellarDepositsHandler: &controllers.InvalidStellarDepositsHandler{
+			Store: a.NewStore(),
 		},
 		TestDepositHandler: &controllers.TestDeposit{
 			Store: a.NewStore(),
-			// This will crash if no asset mappings - probably fine for a demo
-			// because it requires at least one mapping.
-			Token: config.AssetMapping[0].EthereumToken,
+			Token: testDepositToken,
+		},
+		SetPausedHandler: &controllers.SetPausedHandler{
+			Store:          a.NewStore(),
+			EthereumSigner: ethSigner,
+		},
+		UpdateSignersHandler: &controllers.UpdateSignersHandler{
+			Store:          a.NewStore(),
+			EthereumSigner: ethSigner,
+		},
+		StellarSetSignersHandler: &controllers.StellarSetSignersHandler{
+			StellarClient: client,
+			StellarBuilder: &txbuilder.Builder{
+				BridgeAccount: config.StellarBridgeAccount,
+			},
+			StellarSigner: stellarSigner,
+		},
+		RegisterStellarAssetHandler: &controllers.RegisterStellarAssetHandler{
+			Store:                a.NewStore(),
+			EthereumSigner:       ethSigner,
+			BridgeAddress:        common.HexToAddress(config.EthereumBridgeAddress),
+			StellarAssetBytecode: stellarAssetBytecode,
+		},
+		ListAssetMappingsHandler: &controllers.ListAssetMappingsHandler{
+			AssetMappings: a.assetMappings,
+		},
+		AddAssetMappingHandler: &controllers.AddAssetMappingHandler{
+			AssetMappings: a.assetMappings,
+		},
+		DisableAssetMappingHandler: &controllers.DisableAssetMappingHandler{
+			AssetMappings: a.assetMappings,
+		},
+		ListSignatureRequestsHandler: &controllers.ListSignatureRequestsHandler{
+			Store: a.NewStore(),
+		},
+		GetSignatureRequestHandler: &controllers.GetSignatureRequestHandler{
+			Store: a.NewStore(),
+		},
+		RetrySignatureRequestHandler: &controllers.RetrySignatureRequestHandler{
+			Store: a.NewStore(),
+		},
+		CancelSignatureRequestHandler: &controllers.CancelSignatureRequestHandler{
+			Store: a.NewStore(),
 		},
 	})
 	if err != nil {
@@ -275,6 +445,24 @@ func (a *App) Run() {
 		wg.Done()
 	}()
 
+	wg.Add(1)
+	go func() {
+		a.assetMappings.Run(a.appCtx)
+		wg.Done()
+	}()
+
+	wg.Add(1)
+	go func() {
+		a.horizonPool.Run(a.appCtx)
+		wg.Done()
+	}()
+
+	wg.Add(1)
+	go func() {
+		a.notifier.Run(a.appCtx)
+		wg.Done()
+	}()
+
 	wg.Wait()
 	log.Info("Bye")
 }
@@ -26,6 +26,8 @@ func TestParseConfig(t *testing.T) {
 		EthereumBridgeAddress:       "0xD0675839A6C2c3412a3026Aa5F521Ea1e948E526",
 		EthereumBridgeConfigVersion: 0,
 		EthereumPrivateKey:          "2aecee1800342bae06228ed990a152563b8dedf5fe15e3eab4b44854c9e001e5",
+		EthereumFinalityMode:        "fixed_depth",
+		EthereumFinalityBuffer:      12,
 		AssetMapping: []backend.AssetMappingConfigEntry{
 			{
 				StellarAsset:      "ETH:GAJKCRY6CIOXRIVK55ALOOJA327XN4JZ5KKN7YCTT3WM5W6BMFXMVQC2",
@@ -38,6 +40,21 @@ func TestParseConfig(t *testing.T) {
 				StellarToEthereum: "1",
 			},
 		},
+		TransferLimits: backend.TransferLimitsConfig{
+			Window:       "24h",
+			MaxTransfers: 1000,
+			MaxVolume:    "500000",
+			Assets: []backend.TransferLimitConfigEntry{
+				{
+					Asset:           "native",
+					MinAmount:       "1",
+					MaxAmount:       "10000",
+					MaxVolume:       "100000",
+					MaxSenderVolume: "20000",
+					Value:           "1",
+				},
+			},
+		},
 	}
 	require.Equal(t, expected, cfg)
 }
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"net/url"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stellar/go/support/errors"
+
+	"github.com/stellar/starbridge/ethereum"
+)
+
+// ethereumRPCURLs returns the configured ethereum rpc urls
+func ethereumRPCURLs(config Config) []string {
+	var urls []string
+	if config.EthereumRPCURL != "" {
+		urls = append(urls, config.EthereumRPCURL)
+	}
+	return append(urls, config.EthereumRPCURLs...)
+}
+
+// ethereumRPCQuorum returns the configured quorum or a majority of the
+// endpoints if it is not set
+func ethereumRPCQuorum(config Config) int {
+	if config.EthereumRPCQuorum != 0 {
+		return config.EthereumRPCQuorum
+	}
+	return len(ethereumRPCURLs(config))/2 + 1
+}
+
+// NewEthereumRPCEndpoints dials all configured ethereum rpc urls. Endpoints
+// are named after the host of the url so that credentials which can be
+// part of the url path do not end up in logs or metrics.
+func NewEthereumRPCEndpoints(config Config) ([]ethereum.RPCEndpoint, error) {
+	var endpoints []ethereum.RPCEndpoint
+	for _, rawURL := range ethereumRPCURLs(config) {
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, errors.Errorf("invalid ethereum rpc url")
+		}
+		client, err := rpc.Dial(rawURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not dial ethereum node %s", parsed.Host)
+		}
+		endpoints = append(endpoints, ethereum.RPCEndpoint{
+			Name:   parsed.Host,
+			Client: client,
+		})
+	}
+	return endpoints, nil
+}
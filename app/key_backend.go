@@ -0,0 +1,159 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/support/errors"
+
+	"github.com/stellar/starbridge/ethereum"
+	"github.com/stellar/starbridge/hsm"
+	"github.com/stellar/starbridge/remotesigner"
+	"github.com/stellar/starbridge/stellar/signer"
+)
+
+const (
+	keystoreKeyBackend = "keystore"
+	pkcs11KeyBackend   = "pkcs11"
+	remoteKeyBackend   = "remote"
+)
+
+// KeyBackendConfig configures where a validator private key is stored
+type KeyBackendConfig struct {
+	// Type is one of keystore, pkcs11 or remote
+	Type string `toml:"type" valid:"-"`
+
+	// KeystorePath is the path to the encrypted keystore file
+	KeystorePath string `toml:"keystore_path" valid:"-"`
+	// PasswordPath is the path to the file containing the keystore
+	// password or the PKCS#11 user pin
+	PasswordPath string `toml:"password_path" valid:"-"`
+
+	PKCS11Module     string `toml:"pkcs11_module" valid:"-"`
+	PKCS11TokenLabel string `toml:"pkcs11_token_label" valid:"-"`
+	PKCS11KeyLabel   string `toml:"pkcs11_key_label" valid:"-"`
+
+	RemoteURL   string `toml:"remote_url" valid:"-"`
+	RemoteKeyID string `toml:"remote_key_id" valid:"-"`
+	// RemoteAuthTokenPath is the path to the file containing the bearer
+	// token used to authenticate with the remote signer
+	RemoteAuthTokenPath string `toml:"remote_auth_token_path" valid:"-"`
+}
+
+func readSecretFile(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(contents), "\r\n"), nil
+}
+
+func (c KeyBackendConfig) pkcs11Config() (hsm.Config, error) {
+	pin, err := readSecretFile(c.PasswordPath)
+	if err != nil {
+		return hsm.Config{}, errors.Wrap(err, "error reading pkcs11 pin")
+	}
+	return hsm.Config{
+		ModulePath: c.PKCS11Module,
+		TokenLabel: c.PKCS11TokenLabel,
+		KeyLabel:   c.PKCS11KeyLabel,
+		PIN:        pin,
+	}, nil
+}
+
+func (c KeyBackendConfig) remoteClient() (*remotesigner.Client, error) {
+	authToken, err := readSecretFile(c.RemoteAuthTokenPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading remote signer auth token")
+	}
+	return remotesigner.NewClient(c.RemoteURL, c.RemoteKeyID, authToken), nil
+}
+
+// NewEthereumSigner creates the ethereum signer of the validator using the
+// configured key backend
+func NewEthereumSigner(config Config) (ethereum.Signer, error) {
+	backend, err := newEthereumKeyBackend(config)
+	if err != nil {
+		return ethereum.Signer{}, err
+	}
+	return ethereum.NewSignerWithBackend(backend, config.EthereumBridgeConfigVersion), nil
+}
+
+// NewStellarSigner creates the Stellar signer of the validator using the
+// configured key backend
+func NewStellarSigner(config Config) (*signer.Signer, error) {
+	backend, err := newStellarKeyBackend(config)
+	if err != nil {
+		return nil, err
+	}
+	return &signer.Signer{
+		NetworkPassphrase: config.NetworkPassphrase,
+		Signer:            backend,
+	}, nil
+}
+
+// newEthereumKeyBackend creates the backend holding the ethereum validator
+// key. The plaintext private key is used if no backend is configured.
+func newEthereumKeyBackend(config Config) (ethereum.KeyBackend, error) {
+	backendConfig := config.EthereumKeyBackend
+	switch backendConfig.Type {
+	case "":
+		return ethereum.NewPrivateKeyBackend(config.EthereumPrivateKey)
+	case keystoreKeyBackend:
+		return ethereum.NewKeystoreBackend(backendConfig.KeystorePath, backendConfig.PasswordPath)
+	case pkcs11KeyBackend:
+		pkcs11Config, err := backendConfig.pkcs11Config()
+		if err != nil {
+			return nil, err
+		}
+		return hsm.NewEthereumBackend(pkcs11Config)
+	case remoteKeyBackend:
+		client, err := backendConfig.remoteClient()
+		if err != nil {
+			return nil, err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return remotesigner.NewEthereumBackend(ctx, client)
+	default:
+		return nil, fmt.Errorf("invalid key backend type: %s", backendConfig.Type)
+	}
+}
+
+// newStellarKeyBackend creates the backend holding the Stellar validator
+// key. The plaintext secret key is used if no backend is configured.
+func newStellarKeyBackend(config Config) (signer.KeyBackend, error) {
+	backendConfig := config.StellarKeyBackend
+	switch backendConfig.Type {
+	case "":
+		if config.StellarPrivateKey == "" {
+			return nil, nil
+		}
+		return keypair.ParseFull(config.StellarPrivateKey)
+	case keystoreKeyBackend:
+		return signer.NewKeystoreBackend(backendConfig.KeystorePath, backendConfig.PasswordPath)
+	case pkcs11KeyBackend:
+		pkcs11Config, err := backendConfig.pkcs11Config()
+		if err != nil {
+			return nil, err
+		}
+		return hsm.NewStellarBackend(pkcs11Config)
+	case remoteKeyBackend:
+		client, err := backendConfig.remoteClient()
+		if err != nil {
+			return nil, err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return remotesigner.NewStellarBackend(ctx, client)
+	default:
+		return nil, fmt.Errorf("invalid key backend type: %s", backendConfig.Type)
+	}
+}
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/historyarchive"
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/support/errors"
+
+	"github.com/stellar/starbridge/stellar/horizonpool"
+	"github.com/stellar/starbridge/stellar/txobserver"
+)
+
+const (
+	horizonLedgerBackend     = "horizon"
+	archiveLedgerBackend     = "archive"
+	captiveCoreLedgerBackend = "captive_core"
+)
+
+// horizonURLs returns the configured Horizon urls in order of preference
+func horizonURLs(config Config) []string {
+	var urls []string
+	if config.HorizonURL != "" {
+		urls = append(urls, config.HorizonURL)
+	}
+	return append(urls, config.HorizonURLs...)
+}
+
+func parseHorizonTimeout(config Config) (time.Duration, error) {
+	if config.HorizonTimeout == "" {
+		return horizonpool.DefaultTimeout, nil
+	}
+	timeout, err := time.ParseDuration(config.HorizonTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("%s is not a valid horizon timeout", config.HorizonTimeout)
+	}
+	return timeout, nil
+}
+
+// NewStellarLedgerBackend creates the backend from which the Stellar
+// observer ingests ledgers. Horizon is used if no backend is configured.
+// In cross check mode ledgers must be confirmed by a second source.
+func NewStellarLedgerBackend(
+	config Config,
+	client *horizonclient.Client,
+	horizonTimeout time.Duration,
+) (txobserver.LedgerBackend, error) {
+	backend, err := newLedgerBackend(config, client)
+	if err != nil || !config.HorizonCrossCheck {
+		return backend, err
+	}
+
+	if _, ok := backend.(*txobserver.HorizonBackend); !ok {
+		return &txobserver.CrossCheckBackend{
+			Backends: []txobserver.LedgerBackend{
+				backend,
+				&txobserver.HorizonBackend{Client: client},
+			},
+		}, nil
+	}
+
+	// Each Horizon instance is a separate source so the pool, which can
+	// fail over to any instance, is not used
+	urls := horizonURLs(config)
+	if len(urls) < 2 {
+		return nil, errors.New("horizon_cross_check requires at least two horizon urls")
+	}
+	crossCheckBackend := &txobserver.CrossCheckBackend{}
+	for _, url := range urls {
+		crossCheckBackend.Backends = append(crossCheckBackend.Backends, &txobserver.HorizonBackend{
+			Client: &horizonclient.Client{
+				HorizonURL: url,
+				HTTP:       &http.Client{Timeout: horizonTimeout},
+			},
+		})
+	}
+	return crossCheckBackend, nil
+}
+
+func newLedgerBackend(config Config, client *horizonclient.Client) (txobserver.LedgerBackend, error) {
+	switch config.StellarLedgerBackend {
+	case "", horizonLedgerBackend:
+		return &txobserver.HorizonBackend{Client: client}, nil
+	case archiveLedgerBackend:
+		if len(config.StellarHistoryArchiveURLs) == 0 {
+			return nil, errors.New("stellar_history_archive_urls is required")
+		}
+		archive, err := historyarchive.Connect(
+			config.StellarHistoryArchiveURLs[0],
+			historyarchive.ConnectOptions{NetworkPassphrase: config.NetworkPassphrase},
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "error connecting to history archive")
+		}
+		return &txobserver.ArchiveBackend{
+			Archive:           archive,
+			NetworkPassphrase: config.NetworkPassphrase,
+		}, nil
+	case captiveCoreLedgerBackend:
+		params := ledgerbackend.CaptiveCoreTomlParams{
+			NetworkPassphrase:  config.NetworkPassphrase,
+			HistoryArchiveURLs: config.StellarHistoryArchiveURLs,
+			Strict:             true,
+		}
+		toml, err := ledgerbackend.NewCaptiveCoreTomlFromFile(config.StellarCaptiveCoreConfigPath, params)
+		if err != nil {
+			return nil, errors.Wrap(err, "error loading captive core config")
+		}
+		core, err := ledgerbackend.NewCaptive(ledgerbackend.CaptiveCoreConfig{
+			BinaryPath:         config.StellarCaptiveCoreBinaryPath,
+			NetworkPassphrase:  config.NetworkPassphrase,
+			HistoryArchiveURLs: config.StellarHistoryArchiveURLs,
+			Toml:               toml,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating captive core")
+		}
+		return &txobserver.MetaBackend{
+			Backend:           core,
+			NetworkPassphrase: config.NetworkPassphrase,
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid ledger backend type: %s", config.StellarLedgerBackend)
+	}
+}
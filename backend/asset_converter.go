@@ -23,6 +23,13 @@ var (
 		Detail: "Withdrawing the requested asset is not supported by the bridge." +
 			"Refund the deposit once the withdrawal period has expired.",
 	}
+	WithdrawalAmountBelowFee = problem.P{
+		Type:   "withdrawal_amount_below_fee",
+		Title:  "Withdrawal Amount Below Fee",
+		Status: http.StatusBadRequest,
+		Detail: "The deposited amount does not cover the bridge fee." +
+			"Refund the deposit once the withdrawal period has expired.",
+	}
 	WithdrawalAmountInvalid = problem.P{
 		Type:   "withdrawal_amount_invalid",
 		Title:  "Withdrawal Amount Invalid",
@@ -35,9 +42,26 @@ var (
 // AssetMappingConfigEntry is the toml representation of
 // a mapping between a Stellar asset and an Ethereum token
 type AssetMappingConfigEntry struct {
-	StellarAsset      string `toml:"stellar_asset" valid:"-"`
-	EthereumToken     string `toml:"ethereum_token" valid:"-"`
-	StellarToEthereum string `toml:"stellar_to_ethereum" valid:"-"`
+	StellarAsset      string `toml:"stellar_asset" json:"stellar_asset" valid:"-"`
+	EthereumToken     string `toml:"ethereum_token" json:"ethereum_token" valid:"-"`
+	StellarToEthereum string `toml:"stellar_to_ethereum" json:"stellar_to_ethereum" valid:"-"`
+	// FlatFee is the fixed fee in Stellar units (e.g. "0.5") which is
+	// deducted from every withdrawal of the asset
+	FlatFee string `toml:"flat_fee" json:"flat_fee,omitempty" valid:"-"`
+	// FeeBasisPoints is the proportional fee in basis points of the
+	// withdrawn amount
+	FeeBasisPoints uint32 `toml:"fee_basis_points" json:"fee_basis_points,omitempty" valid:"-"`
+}
+
+// maxFeeBasisPoints corresponds to a fee of 100%
+const maxFeeBasisPoints = 10000
+
+// feeSchedule is the fee charged on withdrawals of a Stellar asset. Fees
+// are always computed on the amount in stroops so that they are identical
+// on all validators regardless of the withdrawal direction.
+type feeSchedule struct {
+	flat        int64
+	basisPoints int64
 }
 
 type stellarRate struct {
@@ -55,6 +79,7 @@ type ethereumRate struct {
 type AssetConverter struct {
 	ethereumToStellar map[common.Address]stellarRate
 	stellarToEthereum map[string]ethereumRate
+	fees              map[string]feeSchedule
 }
 
 func isAsset(assetString string) bool {
@@ -89,13 +114,17 @@ func isAsset(assetString string) bool {
 
 // NewAssetConverter constructs a new instance of AssetConverter
 func NewAssetConverter(configEntries []AssetMappingConfigEntry) (AssetConverter, error) {
+	if len(configEntries) == 0 {
+		return AssetConverter{}, fmt.Errorf("config entries are empty")
+	}
+	return newAssetConverter(configEntries)
+}
+
+func newAssetConverter(configEntries []AssetMappingConfigEntry) (AssetConverter, error) {
 	converter := AssetConverter{
 		ethereumToStellar: map[common.Address]stellarRate{},
 		stellarToEthereum: map[string]ethereumRate{},
-	}
-
-	if len(configEntries) == 0 {
-		return converter, fmt.Errorf("config entries are empty")
+		fees:              map[string]feeSchedule{},
 	}
 
 	for _, entry := range configEntries {
@@ -106,7 +135,7 @@ func NewAssetConverter(configEntries []AssetMappingConfigEntry) (AssetConverter,
 			return converter, fmt.Errorf("%s is not a valid ethereum address", entry.EthereumToken)
 		}
 		multiplier, ok := new(big.Int).SetString(entry.StellarToEthereum, 10)
-		if !ok {
+		if !ok || multiplier.Sign() <= 0 {
 			return converter, fmt.Errorf("%s is not a valid multiplier", entry.StellarToEthereum)
 		}
 		token := common.HexToAddress(entry.EthereumToken)
@@ -118,6 +147,19 @@ func NewAssetConverter(configEntries []AssetMappingConfigEntry) (AssetConverter,
 		if exists {
 			return converter, fmt.Errorf("token %v is repeated in the asset mapping ", entry.EthereumToken)
 		}
+		var fee feeSchedule
+		if entry.FlatFee != "" {
+			flat, err := amount.ParseInt64(entry.FlatFee)
+			if err != nil || flat < 0 {
+				return converter, fmt.Errorf("%s is not a valid flat fee", entry.FlatFee)
+			}
+			fee.flat = flat
+		}
+		if entry.FeeBasisPoints > maxFeeBasisPoints {
+			return converter, fmt.Errorf("%d is not a valid fee in basis points", entry.FeeBasisPoints)
+		}
+		fee.basisPoints = int64(entry.FeeBasisPoints)
+		converter.fees[entry.StellarAsset] = fee
 		converter.stellarToEthereum[entry.StellarAsset] = ethereumRate{
 			token: token,
 			rate:  new(big.Rat).SetFrac(multiplier, big.NewInt(1)),
@@ -182,3 +224,22 @@ func (c AssetConverter) ToEthereum(asset string, assetAmount string) (common.Add
 
 	return entry.token, nil, WithdrawalAmountInvalid
 }
+
+// DeductFee returns the amount in stroops which remains after deducting the
+// fee for withdrawing the given amount of the Stellar asset, along with the
+// fee. WithdrawalAmountBelowFee is returned if nothing remains.
+func (c AssetConverter) DeductFee(asset string, stellarAmount int64) (int64, int64, error) {
+	schedule, ok := c.fees[asset]
+	if !ok {
+		return 0, 0, WithdrawalAssetInvalid
+	}
+
+	proportional := new(big.Int).Mul(big.NewInt(stellarAmount), big.NewInt(schedule.basisPoints))
+	proportional.Quo(proportional, big.NewInt(maxFeeBasisPoints))
+	fee := schedule.flat + proportional.Int64()
+	if fee < 0 || fee >= stellarAmount {
+		return 0, 0, WithdrawalAmountBelowFee
+	}
+
+	return stellarAmount - fee, fee, nil
+}
@@ -0,0 +1,78 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeductFee(t *testing.T) {
+	const (
+		flatAsset  = "USDC:GABPNFVU2NHSHRCJ4HLW6BAMVOT7YIHMJBBQ3DMSSPRKEYDSYGRE5JWH"
+		bpsAsset   = "EURC:GABPNFVU2NHSHRCJ4HLW6BAMVOT7YIHMJBBQ3DMSSPRKEYDSYGRE5JWH"
+		mixedAsset = "native"
+		freeAsset  = "BTC:GABPNFVU2NHSHRCJ4HLW6BAMVOT7YIHMJBBQ3DMSSPRKEYDSYGRE5JWH"
+	)
+	converter, err := NewAssetConverter([]AssetMappingConfigEntry{
+		{
+			StellarAsset:      flatAsset,
+			EthereumToken:     "0x0000000000000000000000000000000000000001",
+			StellarToEthereum: "1",
+			FlatFee:           "0.5",
+		},
+		{
+			StellarAsset:      bpsAsset,
+			EthereumToken:     "0x0000000000000000000000000000000000000002",
+			StellarToEthereum: "1",
+			FeeBasisPoints:    30,
+		},
+		{
+			StellarAsset:      mixedAsset,
+			EthereumToken:     "0x0000000000000000000000000000000000000003",
+			StellarToEthereum: "1",
+			FlatFee:           "0.0000010",
+			FeeBasisPoints:    25,
+		},
+		{
+			StellarAsset:      freeAsset,
+			EthereumToken:     "0x0000000000000000000000000000000000000004",
+			StellarToEthereum: "1",
+		},
+	})
+	require.NoError(t, err)
+
+	for _, testCase := range []struct {
+		name           string
+		asset          string
+		amount         int64
+		expectedAmount int64
+		expectedFee    int64
+		expectedErr    error
+	}{
+		{"no fee", freeAsset, 1, 1, 0, nil},
+		{"flat fee", flatAsset, 10000000, 5000000, 5000000, nil},
+		{"flat fee equal to amount", flatAsset, 5000000, 0, 0, WithdrawalAmountBelowFee},
+		{"flat fee above amount", flatAsset, 4999999, 0, 0, WithdrawalAmountBelowFee},
+		{"flat fee just below amount", flatAsset, 5000001, 1, 5000000, nil},
+		{"basis points", bpsAsset, 10000000, 9970000, 30000, nil},
+		// 0.3% of 3333 stroops is 9.999 stroops which is rounded down
+		{"basis points rounded down", bpsAsset, 3333, 3324, 9, nil},
+		{"basis points below one stroop", bpsAsset, 333, 333, 0, nil},
+		// 10 stroops flat and 0.25% of 10000000 stroops
+		{"flat and basis points", mixedAsset, 10000000, 9974990, 25010, nil},
+		{"flat and basis points rounded down", mixedAsset, 1999, 1985, 14, nil},
+		{"flat and basis points equal to amount", mixedAsset, 10, 0, 0, WithdrawalAmountBelowFee},
+		{"unknown asset", "XYZ:GABPNFVU2NHSHRCJ4HLW6BAMVOT7YIHMJBBQ3DMSSPRKEYDSYGRE5JWH", 100, 0, 0, WithdrawalAssetInvalid},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			remaining, fee, err := converter.DeductFee(testCase.asset, testCase.amount)
+			assert.Equal(t, testCase.expectedErr, err)
+			assert.Equal(t, testCase.expectedAmount, remaining)
+			assert.Equal(t, testCase.expectedFee, fee)
+			if err == nil {
+				assert.Equal(t, testCase.amount, remaining+fee)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/go/support/render/problem"
+
+	"github.com/stellar/starbridge/store"
+)
+
+const (
+	// MinAssetMappingDelay is the minimum amount of time between adding or
+	// disabling an asset mapping and the time it takes effect. It gives
+	// operators time to apply the same change on all validators.
+	MinAssetMappingDelay = 10 * time.Minute
+	// assetMappingsReloadInterval is how often asset mappings are reloaded
+	// from the database
+	assetMappingsReloadInterval = time.Minute
+)
+
+var (
+	InvalidAssetMapping = problem.P{
+		Type:   "invalid_asset_mapping",
+		Title:  "Invalid Asset Mapping",
+		Status: http.StatusBadRequest,
+		Detail: "The asset mapping must contain a valid Stellar asset, Ethereum token and a positive multiplier.",
+	}
+	AssetMappingConflict = problem.P{
+		Type:   "asset_mapping_conflict",
+		Title:  "Asset Mapping Conflict",
+		Status: http.StatusBadRequest,
+		Detail: "The Stellar asset or Ethereum token is already mapped during the requested period.",
+	}
+	InvalidAssetMappingTime = problem.P{
+		Type:   "invalid_asset_mapping_time",
+		Title:  "Invalid Asset Mapping Time",
+		Status: http.StatusBadRequest,
+		Detail: "Asset mappings can only be activated or disabled at least 10 minutes in the future.",
+	}
+	AssetMappingNotFound = problem.P{
+		Type:   "asset_mapping_not_found",
+		Title:  "Asset Mapping Not Found",
+		Status: http.StatusNotFound,
+		Detail: "The asset mapping does not exist or was already disabled.",
+	}
+)
+
+// AssetMappings holds the asset mappings from the config file along with the
+// mappings added through the admin api. Mappings stored in the database
+// become active at a fixed timestamp which is compared against the time of
+// each deposit so that all validators switch mappings for the same deposits.
+type AssetMappings struct {
+	store         *store.DB
+	configEntries []AssetMappingConfigEntry
+
+	lock    sync.RWMutex
+	entries []store.AssetMapping
+
+	log *log.Entry
+}
+
+// NewAssetMappings constructs an AssetMappings instance and loads all
+// mappings stored in the database
+func NewAssetMappings(ctx context.Context, db *store.DB, configEntries []AssetMappingConfigEntry) (*AssetMappings, error) {
+	if _, err := newAssetConverter(configEntries); err != nil {
+		return nil, err
+	}
+	m := &AssetMappings{
+		store:         db,
+		configEntries: configEntries,
+		log:           log.WithField("service", "asset_mappings"),
+	}
+	if err := m.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Reload loads all asset mappings from the database
+func (m *AssetMappings) Reload(ctx context.Context) error {
+	entries, err := m.store.GetAssetMappings(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error loading asset mappings")
+	}
+	m.lock.Lock()
+	m.entries = entries
+	m.lock.Unlock()
+	return nil
+}
+
+// Run periodically reloads asset mappings from the database until the
+// context is cancelled
+func (m *AssetMappings) Run(ctx context.Context) {
+	ticker := time.NewTicker(assetMappingsReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Reload(ctx); err != nil {
+				m.log.WithField("err", err).Error("cannot reload asset mappings")
+			}
+		}
+	}
+}
+
+// Entries returns the asset mappings stored in the database
+func (m *AssetMappings) Entries() []store.AssetMapping {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return append([]store.AssetMapping(nil), m.entries...)
+}
+
+// ConfigEntries returns the asset mappings from the config file which are
+// always active
+func (m *AssetMappings) ConfigEntries() []AssetMappingConfigEntry {
+	return append([]AssetMappingConfigEntry(nil), m.configEntries...)
+}
+
+// ConverterAt returns an AssetConverter for deposits made at the given time
+func (m *AssetMappings) ConverterAt(t time.Time) (AssetConverter, error) {
+	entries := m.ConfigEntries()
+	for _, entry := range m.Entries() {
+		if entry.ActiveAt(t.Unix()) {
+			entries = append(entries, configEntry(entry))
+		}
+	}
+	return newAssetConverter(entries)
+}
+
+// Add validates and stores a new asset mapping which becomes active at the
+// given activation time
+func (m *AssetMappings) Add(ctx context.Context, entry AssetMappingConfigEntry, activationTime, now time.Time) (store.AssetMapping, error) {
+	entry.EthereumToken = strings.ToLower(entry.EthereumToken)
+	if _, err := newAssetConverter([]AssetMappingConfigEntry{entry}); err != nil {
+		return store.AssetMapping{}, InvalidAssetMapping
+	}
+	if activationTime.Before(now.Add(MinAssetMappingDelay)) {
+		return store.AssetMapping{}, InvalidAssetMappingTime
+	}
+
+	// Mappings may have been added by another instance sharing the database.
+	// Concurrent additions of mappings which are never disabled are rejected
+	// by the database.
+	if err := m.Reload(ctx); err != nil {
+		return store.AssetMapping{}, err
+	}
+	for _, existing := range m.ConfigEntries() {
+		if conflicts(existing, entry) {
+			return store.AssetMapping{}, AssetMappingConflict
+		}
+	}
+	for _, existing := range m.Entries() {
+		stillActive := !existing.DeactivationTime.Valid ||
+			existing.DeactivationTime.Int64 > activationTime.Unix()
+		if stillActive && conflicts(configEntry(existing), entry) {
+			return store.AssetMapping{}, AssetMappingConflict
+		}
+	}
+
+	mapping := store.AssetMapping{
+		StellarAsset:      entry.StellarAsset,
+		EthereumToken:     entry.EthereumToken,
+		StellarToEthereum: entry.StellarToEthereum,
+		FlatFee:           entry.FlatFee,
+		FeeBasisPoints:    entry.FeeBasisPoints,
+		ActivationTime:    activationTime.Unix(),
+	}
+	id, err := m.store.InsertAssetMapping(ctx, mapping)
+	if err == store.ErrAssetMappingConflict {
+		return store.AssetMapping{}, AssetMappingConflict
+	} else if err != nil {
+		return store.AssetMapping{}, errors.Wrap(err, "error inserting asset mapping")
+	}
+	mapping.ID = id
+
+	return mapping, m.Reload(ctx)
+}
+
+// Disable disables the given asset mapping from the given deactivation time
+func (m *AssetMappings) Disable(ctx context.Context, id int64, deactivationTime, now time.Time) (store.AssetMapping, error) {
+	if deactivationTime.Before(now.Add(MinAssetMappingDelay)) {
+		return store.AssetMapping{}, InvalidAssetMappingTime
+	}
+
+	err := m.store.DisableAssetMapping(ctx, id, deactivationTime.Unix())
+	if err == sql.ErrNoRows {
+		return store.AssetMapping{}, AssetMappingNotFound
+	} else if err != nil {
+		return store.AssetMapping{}, errors.Wrap(err, "error disabling asset mapping")
+	}
+
+	mapping, err := m.store.GetAssetMapping(ctx, id)
+	if err != nil {
+		return store.AssetMapping{}, errors.Wrap(err, "error getting asset mapping")
+	}
+
+	return mapping, m.Reload(ctx)
+}
+
+func configEntry(mapping store.AssetMapping) AssetMappingConfigEntry {
+	return AssetMappingConfigEntry{
+		StellarAsset:      mapping.StellarAsset,
+		EthereumToken:     mapping.EthereumToken,
+		StellarToEthereum: mapping.StellarToEthereum,
+		FlatFee:           mapping.FlatFee,
+		FeeBasisPoints:    mapping.FeeBasisPoints,
+	}
+}
+
+func conflicts(a, b AssetMappingConfigEntry) bool {
+	return a.StellarAsset == b.StellarAsset ||
+		common.HexToAddress(a.EthereumToken) == common.HexToAddress(b.EthereumToken)
+}
@@ -7,6 +7,9 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/support/render/problem"
 	"github.com/stellar/starbridge/ethereum"
 	"github.com/stellar/starbridge/store"
@@ -17,7 +20,8 @@ var (
 		Type:   "invalid_ethereum_recipient",
 		Title:  "Invalid Ethereum Recipient",
 		Status: http.StatusBadRequest,
-		Detail: "The recipient of the deposit is not a valid Ethereum address.",
+		Detail: "The recipient of the deposit is not a valid Ethereum address. " +
+			"The deposit can be refunded immediately.",
 	}
 	EthereumNodeBehind = problem.P{
 		Type:   "ethereum_node_behind",
@@ -30,10 +34,11 @@ var (
 // EthereumWithdrawalValidator checks if it is possible to
 // withdraw a deposit to the Stellar bridge account.
 type EthereumWithdrawalValidator struct {
-	Observer               ethereum.Observer
-	EthereumFinalityBuffer uint64
-	WithdrawalWindow       time.Duration
-	Converter              AssetConverter
+	Session          db.SessionInterface
+	Observer         ethereum.Observer
+	WithdrawalWindow time.Duration
+	AssetMappings    *AssetMappings
+	TransferLimits   TransferLimits
 }
 
 // EthereumWithdrawalDetails includes metadata about the
@@ -49,30 +54,45 @@ type EthereumWithdrawalDetails struct {
 	// transferred to the recipient.
 	Token common.Address
 	// Amount is the amount of tokens which will be transferred to
-	// the recipient.
+	// the recipient after deducting the bridge fee.
 	Amount *big.Int
+	// Fee is the amount of tokens deducted from the deposit as the
+	// bridge fee.
+	Fee *big.Int
 }
 
 func (s EthereumWithdrawalValidator) CanWithdraw(ctx context.Context, deposit store.StellarDeposit) (EthereumWithdrawalDetails, error) {
-	if !common.IsHexAddress(deposit.Destination) {
+	if deposit.InvalidReason != "" || !common.IsHexAddress(deposit.Destination) {
 		return EthereumWithdrawalDetails{}, InvalidEthereumRecipient
 	}
 
-	tokenAddress, amount, err := s.Converter.ToEthereum(deposit.Asset, deposit.Amount)
+	converter, err := s.AssetMappings.ConverterAt(time.Unix(deposit.LedgerTime, 0))
+	if err != nil {
+		return EthereumWithdrawalDetails{}, errors.Wrap(err, "error loading asset mappings")
+	}
+	tokenAddress, grossAmount, err := converter.ToEthereum(deposit.Asset, deposit.Amount)
 	if err != nil {
 		return EthereumWithdrawalDetails{}, err
 	}
-
-	latest, err := s.Observer.GetLatestBlock(ctx)
+	// the fee is computed in stroops before converting to token units so
+	// that all validators deduct exactly the same amount
+	stroops, err := amount.ParseInt64(deposit.Amount)
+	if err != nil {
+		return EthereumWithdrawalDetails{}, WithdrawalAmountInvalid
+	}
+	netStroops, _, err := converter.DeductFee(deposit.Asset, stroops)
 	if err != nil {
 		return EthereumWithdrawalDetails{}, err
 	}
-	if latest.Number <= s.EthereumFinalityBuffer {
-		return EthereumWithdrawalDetails{}, EthereumNodeBehind
+	_, netAmount, err := converter.ToEthereum(deposit.Asset, amount.StringFromInt64(netStroops))
+	if err != nil {
+		return EthereumWithdrawalDetails{}, err
 	}
 
-	latestFinalBlock, err := s.Observer.GetBlockByNumber(ctx, latest.Number-s.EthereumFinalityBuffer)
-	if err != nil {
+	latestFinalBlock, err := s.Observer.GetLatestFinalBlock(ctx)
+	if err == ethereum.ErrNoFinalBlock {
+		return EthereumWithdrawalDetails{}, EthereumNodeBehind
+	} else if err != nil {
 		return EthereumWithdrawalDetails{}, err
 	}
 
@@ -81,10 +101,18 @@ func (s EthereumWithdrawalValidator) CanWithdraw(ctx context.Context, deposit st
 		return EthereumWithdrawalDetails{}, WithdrawalWindowExpired
 	}
 
+	if s.TransferLimits.enabled() {
+		dbStore := &store.DB{Session: s.Session.Clone()}
+		if err = s.TransferLimits.CheckStellarDeposit(ctx, dbStore, deposit); err != nil {
+			return EthereumWithdrawalDetails{}, err
+		}
+	}
+
 	return EthereumWithdrawalDetails{
 		Deadline:  withdrawalDeadline,
 		Recipient: common.HexToAddress(deposit.Destination),
 		Token:     tokenAddress,
-		Amount:    amount,
+		Amount:    netAmount,
+		Fee:       new(big.Int).Sub(grossAmount, netAmount),
 	}, nil
 }
@@ -0,0 +1,107 @@
+package backend
+
+import (
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/stellar/go/support/render/problem"
+
+	"github.com/stellar/starbridge/solidity-go"
+)
+
+const (
+	// PauseDeposits is the bridge paused bit which disables deposits
+	PauseDeposits uint8 = 1 << 0
+	// PauseWithdrawals is the bridge paused bit which disables withdrawals
+	PauseWithdrawals uint8 = 1 << 1
+	// PauseDepositsAndWithdrawals disables both deposits and withdrawals
+	PauseDepositsAndWithdrawals = PauseDeposits | PauseWithdrawals
+
+	// MaxGovernanceRequestValidity is the maximum amount of time a signed
+	// governance request can remain valid
+	MaxGovernanceRequestValidity = 7 * 24 * time.Hour
+
+	// StellarAmountDecimals is the number of decimal places of Stellar amounts
+	StellarAmountDecimals = 7
+	// maxTokenDecimals is the largest number of decimals for which the
+	// stellar_to_ethereum multiplier fits in a uint256
+	maxTokenDecimals = 77 + StellarAmountDecimals
+)
+
+var (
+	InvalidPausedValue = problem.P{
+		Type:   "invalid_paused_value",
+		Title:  "Invalid Paused Value",
+		Status: http.StatusBadRequest,
+		Detail: "The paused value must be a bitmask of deposits (1) and withdrawals (2).",
+	}
+	InvalidGovernanceNonce = problem.P{
+		Type:   "invalid_governance_nonce",
+		Title:  "Invalid Governance Nonce",
+		Status: http.StatusBadRequest,
+		Detail: "The nonce must be a non-negative 256 bit integer.",
+	}
+	InvalidGovernanceExpiration = problem.P{
+		Type:   "invalid_governance_expiration",
+		Title:  "Invalid Governance Expiration",
+		Status: http.StatusBadRequest,
+		Detail: "The expiration must be in the future and at most 7 days from now.",
+	}
+	InvalidRegisterStellarAssetRequest = problem.P{
+		Type:   "invalid_register_stellar_asset_request",
+		Title:  "Invalid Register Stellar Asset Request",
+		Status: http.StatusBadRequest,
+		Detail: "The asset must be a valid Stellar asset, the name and symbol must not be empty " +
+			"and the token must have at least 7 decimals.",
+	}
+)
+
+// ValidateSetPausedRequest checks that the given setPaused request can be
+// signed by the validator
+func ValidateSetPausedRequest(request solidity.SetPausedRequest, now time.Time) error {
+	if request.Value > PauseDepositsAndWithdrawals {
+		return InvalidPausedValue
+	}
+	if request.Nonce == nil || request.Nonce.Sign() < 0 || request.Nonce.Cmp(math.MaxBig256) > 0 {
+		return InvalidGovernanceNonce
+	}
+	return validateGovernanceExpiration(request.Expiration, now)
+}
+
+func validateGovernanceExpiration(expiration *big.Int, now time.Time) error {
+	if expiration == nil || !expiration.IsInt64() {
+		return InvalidGovernanceExpiration
+	}
+	deadline := time.Unix(expiration.Int64(), 0)
+	if !deadline.After(now) || deadline.After(now.Add(MaxGovernanceRequestValidity)) {
+		return InvalidGovernanceExpiration
+	}
+	return nil
+}
+
+// ValidateRegisterStellarAssetRequest checks that the given registerStellarAsset
+// request creates a token which can represent the given Stellar asset
+func ValidateRegisterStellarAssetRequest(asset string, request solidity.RegisterStellarAssetRequest) error {
+	if !isAsset(asset) || request.Name == "" || request.Symbol == "" {
+		return InvalidRegisterStellarAssetRequest
+	}
+	if request.Decimals < StellarAmountDecimals || request.Decimals > maxTokenDecimals {
+		return InvalidRegisterStellarAssetRequest
+	}
+	return nil
+}
+
+// StellarAssetMappingEntry returns the asset mapping between the given Stellar
+// asset and the token deployed by registerStellarAsset
+func StellarAssetMappingEntry(asset string, token common.Address, decimals uint8) AssetMappingConfigEntry {
+	multiplier := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals-StellarAmountDecimals)), nil)
+	return AssetMappingConfigEntry{
+		StellarAsset:      asset,
+		EthereumToken:     strings.ToLower(token.String()),
+		StellarToEthereum: multiplier.String(),
+	}
+}
@@ -2,9 +2,12 @@ package backend
 
 import (
 	"context"
+	"database/sql"
 	"encoding/hex"
 	"fmt"
 	"math/big"
+	"net/http"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -12,6 +15,7 @@ import (
 
 	"github.com/stellar/go/amount"
 	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/protocols/horizon"
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/support/log"
 	"github.com/stellar/go/support/render/problem"
@@ -25,6 +29,64 @@ import (
 	"github.com/stellar/starbridge/store"
 )
 
+var (
+	EthereumDepositReorged = problem.P{
+		Type:   "ethereum_deposit_reorged",
+		Title:  "Ethereum Deposit Reorged",
+		Status: http.StatusBadRequest,
+		Detail: "The block containing the deposit is no longer part of the canonical Ethereum chain.",
+	}
+	StellarRecipientNotFound = problem.P{
+		Type:   "stellar_recipient_not_found",
+		Title:  "Stellar Recipient Not Found",
+		Status: http.StatusBadRequest,
+		Detail: "The Stellar recipient account does not exist. Create the account and request the withdrawal again.",
+	}
+	StellarTrustlineNotAuthorized = problem.P{
+		Type:   "stellar_trustline_not_authorized",
+		Title:  "Stellar Trustline Not Authorized",
+		Status: http.StatusBadRequest,
+		Detail: "The asset issuer has not authorized the trustline of the Stellar recipient.",
+	}
+	StellarTrustlineLimitExceeded = problem.P{
+		Type:   "stellar_trustline_limit_exceeded",
+		Title:  "Stellar Trustline Limit Exceeded",
+		Status: http.StatusBadRequest,
+		Detail: "The trustline of the Stellar recipient cannot hold the withdrawal amount.",
+	}
+)
+
+// StellarWithdrawalMode determines how withdrawals are delivered to the
+// recipient on Stellar
+type StellarWithdrawalMode string
+
+const (
+	// PaymentWithdrawalMode pays the recipient directly in a transaction
+	// sourced from the recipient account
+	PaymentWithdrawalMode StellarWithdrawalMode = "payment"
+	// ClaimableBalanceWithdrawalMode creates a claimable balance for the
+	// recipient in a transaction sourced from the bridge account
+	ClaimableBalanceWithdrawalMode StellarWithdrawalMode = "claimable_balance"
+)
+
+const (
+	// DefaultWorkerConcurrency is the default number of signature requests
+	// processed concurrently
+	DefaultWorkerConcurrency = 4
+	// maxRetryBackoff is the maximum delay between attempts to process a
+	// signature request
+	maxRetryBackoff = 5 * time.Minute
+	// maxSignatureRequestAttempts is the number of attempts after which a
+	// signature request which keeps failing is marked as failed
+	maxSignatureRequestAttempts = 20
+	// sequenceBlockBits and sequenceLogIndexBits are the bits of the
+	// Ethereum block number and log index of a deposit used to reserve a
+	// sequence number of the bridge account. The block number wraps around
+	// after about half a year, much longer than the withdrawal window.
+	sequenceBlockBits    = 20
+	sequenceLogIndexBits = 10
+)
+
 type Worker struct {
 	Store *store.DB
 
@@ -35,10 +97,19 @@ type Worker struct {
 	StellarWithdrawalValidator StellarWithdrawalValidator
 	StellarRefundValidator     StellarRefundValidator
 
+	EthereumObserver            ethereum.Observer
+	EthereumIngester            *ethereum.Ingester
 	EthereumRefundValidator     EthereumRefundValidator
 	EthereumWithdrawalValidator EthereumWithdrawalValidator
 	EthereumSigner              ethereum.Signer
 
+	// StellarWithdrawalMode determines the transaction signed for
+	// withdrawals to Stellar. It must be the same on all validators.
+	StellarWithdrawalMode StellarWithdrawalMode
+	// Concurrency is the number of signature requests processed
+	// concurrently, DefaultWorkerConcurrency if not set
+	Concurrency int
+
 	log *log.Entry
 }
 
@@ -47,119 +118,204 @@ func (w *Worker) Run(ctx context.Context) {
 
 	w.log.Info("Starting worker")
 
+	// Signature requests are processed concurrently so that a slow request
+	// does not delay other requests
+	concurrency := w.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultWorkerConcurrency
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.processSignatureRequests(ctx)
+		}()
+	}
+
 	for ctx.Err() == nil {
-		// Process all new ledgers before processing signature requests
 		w.StellarObserver.ProcessNewLedgers(ctx)
+		w.EthereumIngester.ProcessNewBlocks(ctx)
+		sleep(ctx, time.Second)
+	}
+	wg.Wait()
+}
 
-		signatureRequests, err := w.Store.GetSignatureRequests(ctx)
+// processSignatureRequests processes signature requests until the context
+// is cancelled
+func (w *Worker) processSignatureRequests(ctx context.Context) {
+	for ctx.Err() == nil {
+		processed, err := w.processNextSignatureRequest(ctx)
 		if err != nil {
-			w.log.WithField("err", err).Error("cannot get signature requests")
-			time.Sleep(time.Second)
-			continue
+			w.log.WithField("err", err).Error("cannot process next signature request")
 		}
+		if !processed {
+			sleep(ctx, time.Second)
+		}
+	}
+}
 
-		if len(signatureRequests) == 0 {
-			time.Sleep(time.Second)
-			continue
+// processNextSignatureRequest claims a signature request which is due and
+// processes it. Requests which fail because of temporary errors are retried
+// with an exponential backoff until they run out of attempts. It returns
+// false if there was no request to process.
+func (w *Worker) processNextSignatureRequest(ctx context.Context) (bool, error) {
+	claimStore := &store.DB{Session: w.Store.Session.Clone()}
+	if err := claimStore.Session.Begin(); err != nil {
+		return false, errors.Wrap(err, "error starting a transaction")
+	}
+	defer func() {
+		// explicitly ignore return value to make the linter happy
+		_ = claimStore.Session.Rollback()
+	}()
+
+	sr, err := claimStore.ClaimSignatureRequest(ctx, time.Now().Unix())
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "error claiming signature request")
+	}
+
+	// Signing for a deposit must be serial so requests for the same
+	// deposit with different actions wait for each other
+	if err = claimStore.LockDeposit(ctx, sr.DepositChain, sr.DepositID); err != nil {
+		return false, errors.Wrap(err, "error locking deposit")
+	}
+
+	// Requests are processed in the claim transaction. Using another
+	// connection would block on the row lock of the claimed request when
+	// the request is updated, for example when a deposit is invalidated.
+	// The savepoint discards partial writes of failed attempts, such as a
+	// bridge fee without its signature, and recovers the transaction after
+	// a failed statement so the attempt can be recorded.
+	if _, err = claimStore.Session.ExecRaw(ctx, "SAVEPOINT process_signature_request"); err != nil {
+		return true, errors.Wrap(err, "error creating savepoint")
+	}
+	err = w.processSignatureRequest(ctx, claimStore, sr)
+	now := time.Now()
+	sr.UpdatedAt = now.Unix()
+	if err != nil {
+		sr.Attempts++
+		sr.LastError = err.Error()
+		// Writes made before rejecting a request are kept, for example
+		// the invalidation of a reorged deposit
+		if p, ok := errors.Cause(err).(problem.P); ok && p.Status >= 400 && p.Status < 500 {
+			sr.Status = store.SignatureRequestRejected
+		} else if _, rollbackErr := claimStore.Session.ExecRaw(ctx, "ROLLBACK TO SAVEPOINT process_signature_request"); rollbackErr != nil {
+			return true, errors.Wrap(rollbackErr, "error rolling back to savepoint")
+		} else if sr.Attempts >= maxSignatureRequestAttempts {
+			sr.Status = store.SignatureRequestFailed
+		} else {
+			sr.NextAttemptAt = now.Add(retryBackoff(sr.Attempts)).Unix()
 		}
+		w.log.WithFields(log.F{"err": err, "request": sr}).
+			Error("Cannot process signature request")
+	} else {
+		sr.Status = store.SignatureRequestSucceeded
+		sr.LastError = ""
+		w.log.WithField("request", sr).
+			Info("Processed signature request successfully")
+	}
+	err = claimStore.UpdateSignatureRequest(ctx, sr)
+	if err != nil {
+		return true, errors.Wrap(err, "error updating signature request")
+	}
+	if sr.Status != store.SignatureRequestPending {
+		if err = claimStore.NotifySignatureRequest(ctx, sr); err != nil {
+			return true, errors.Wrap(err, "error notifying signature request")
+		}
+	}
 
-		w.log.Infof("Processing %d signature requests", len(signatureRequests))
-
-		for _, sr := range signatureRequests {
-			var err error
-			switch sr.Action {
-			case store.Withdraw:
-				switch sr.DepositChain {
-				case store.Ethereum:
-					err = w.processStellarWithdrawalRequest(ctx, sr)
-				case store.Stellar:
-					err = w.processEthereumWithdrawalRequest(ctx, sr)
-				default:
-					err = fmt.Errorf("withdrawals for deposit chain %v is not supported", sr.DepositChain)
-				}
-			case store.Refund:
-				switch sr.DepositChain {
-				case store.Ethereum:
-					err = w.processEthereumRefundRequest(ctx, sr)
-				case store.Stellar:
-					err = w.processStellarRefundRequest(ctx, sr)
-				default:
-					err = fmt.Errorf("refunds for deposit chain %v is not supported", sr.DepositChain)
-				}
-			default:
-				err = fmt.Errorf("action %v is not supported", sr.Action)
-			}
-
-			if err != nil {
-				w.log.WithFields(log.F{"err": err, "request": sr}).
-					Error("Cannot process signature request")
-				if p, ok := err.(problem.P); ok && p.Status >= 400 && p.Status < 500 {
-					w.deleteRequest(ctx, sr)
-				}
-			} else {
-				w.log.WithField("request", sr).
-					Info("Processed signature request successfully")
-				w.deleteRequest(ctx, sr)
-			}
+	if err = claimStore.Session.Commit(); err != nil {
+		return true, errors.Wrap(err, "error committing a transaction")
+	}
+	return true, nil
+}
+
+func (w *Worker) processSignatureRequest(ctx context.Context, db *store.DB, sr store.SignatureRequest) error {
+	switch sr.Action {
+	case store.Withdraw:
+		switch sr.DepositChain {
+		case store.Ethereum:
+			return w.processStellarWithdrawalRequest(ctx, db, sr)
+		case store.Stellar:
+			return w.processEthereumWithdrawalRequest(ctx, db, sr)
+		default:
+			return fmt.Errorf("withdrawals for deposit chain %v is not supported", sr.DepositChain)
+		}
+	case store.Refund:
+		switch sr.DepositChain {
+		case store.Ethereum:
+			return w.processEthereumRefundRequest(ctx, db, sr)
+		case store.Stellar:
+			return w.processStellarRefundRequest(ctx, db, sr)
+		default:
+			return fmt.Errorf("refunds for deposit chain %v is not supported", sr.DepositChain)
 		}
+	default:
+		return fmt.Errorf("action %v is not supported", sr.Action)
 	}
 }
 
-func (w *Worker) deleteRequest(ctx context.Context, sr store.SignatureRequest) {
-	err := w.Store.DeleteSignatureRequest(ctx, sr)
-	if err != nil {
-		w.log.WithFields(log.F{"err": err, "request": sr}).
-			Error("Error removing signature request")
+// retryBackoff returns the delay before the given attempt to process a
+// signature request
+func retryBackoff(attempt int) time.Duration {
+	if attempt >= 16 {
+		return maxRetryBackoff
+	}
+	backoff := time.Second << attempt
+	if backoff > maxRetryBackoff {
+		return maxRetryBackoff
+	}
+	return backoff
+}
+
+// sleep waits for the given duration or until the context is cancelled
+func sleep(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
 	}
 }
 
-func (w *Worker) processStellarWithdrawalRequest(ctx context.Context, sr store.SignatureRequest) error {
+func (w *Worker) processStellarWithdrawalRequest(ctx context.Context, db *store.DB, sr store.SignatureRequest) error {
 	if sr.DepositChain != store.Ethereum {
 		return fmt.Errorf("deposits from %v are not supported", sr.DepositChain)
 	}
-	deposit, err := w.Store.GetEthereumDeposit(ctx, sr.DepositID)
+	deposit, err := db.GetEthereumDeposit(ctx, sr.DepositID)
 	if err != nil {
 		return errors.Wrap(err, "error getting ethereum deposit")
 	}
 
+	if err = w.verifyEthereumDeposit(ctx, db, deposit); err != nil {
+		return errors.Wrap(err, "error verifying ethereum deposit")
+	}
+
 	details, err := w.StellarWithdrawalValidator.CanWithdraw(ctx, deposit)
 	if err != nil {
 		return errors.Wrap(err, "error validating withdraw conditions")
 	}
 
-	// Load source account sequence
-	sourceAccount, err := w.StellarClient.AccountDetail(horizonclient.AccountRequest{
-		AccountID: details.Recipient,
-	})
+	depositIDBytes, err := hex.DecodeString(deposit.ID)
 	if err != nil {
-		return errors.Wrap(err, "error getting account details")
+		return errors.Wrap(err, "error decoding deposit id")
 	}
-	if sourceAccount.SequenceLedger > 0 {
-		if details.LedgerSequence < sourceAccount.SequenceLedger {
-			return errors.New("skipping, account sequence ledger is higher than last ledger ingested")
+
+	var tx xdr.TransactionEnvelope
+	var txSource string
+	if w.StellarWithdrawalMode == ClaimableBalanceWithdrawalMode {
+		txSource = w.StellarBuilder.BridgeAccount
+		var pending bool
+		tx, pending, err = w.buildClaimableBalanceWithdrawal(ctx, db, deposit, details, depositIDBytes)
+		if err == nil && pending {
+			return nil
 		}
 	} else {
-		if details.LedgerSequence < sourceAccount.LastModifiedLedger {
-			return errors.New("skipping, account sequence possibly bumped after last ledger ingested")
-		}
-	}
-
-	depositIDBytes, err := hex.DecodeString(deposit.ID)
-	if err != nil {
-		return errors.Wrap(err, "error decoding deposit id")
+		txSource = details.Recipient
+		tx, err = w.buildPaymentWithdrawal(details, depositIDBytes)
 	}
-	tx, err := w.StellarBuilder.BuildTransaction(
-		details.Asset,
-		details.Recipient,
-		details.Recipient,
-		amount.StringFromInt64(details.Amount),
-		sourceAccount.Sequence+1,
-		// TODO: ensure using WithdrawExpiration without any time buffer is safe
-		details.Deadline.Unix(),
-		depositIDBytes,
-	)
 	if err != nil {
-		return errors.Wrap(err, "error building outgoing stellar transaction")
+		return err
 	}
 
 	signature, err := w.StellarSigner.Sign(tx)
@@ -176,14 +332,26 @@ func (w *Worker) processStellarWithdrawalRequest(ctx context.Context, sr store.S
 		return errors.Wrap(err, "error marshaling outgoing stellar transaction")
 	}
 
+	// The fee is committed together with the outgoing transaction
+	err = db.UpsertBridgeFee(ctx, store.BridgeFee{
+		DepositChain:     sr.DepositChain,
+		DepositID:        sr.DepositID,
+		Asset:            details.Asset,
+		Amount:           amount.StringFromInt64(details.Fee),
+		WithdrawalAmount: amount.StringFromInt64(details.Amount),
+	})
+	if err != nil {
+		return errors.Wrap(err, "error upserting bridge fee")
+	}
+
 	outgoingTx := store.OutgoingStellarTransaction{
 		Envelope:      txBase64,
 		Action:        sr.Action,
 		DepositID:     sr.DepositID,
-		SourceAccount: details.Recipient,
+		SourceAccount: txSource,
 		Sequence:      tx.SeqNum(),
 	}
-	err = w.Store.UpsertOutgoingStellarTransaction(ctx, outgoingTx)
+	err = db.UpsertOutgoingStellarTransaction(ctx, outgoingTx)
 	if err != nil {
 		return errors.Wrap(err, "error upserting outgoing stellar transaction")
 	}
@@ -191,11 +359,136 @@ func (w *Worker) processStellarWithdrawalRequest(ctx context.Context, sr store.S
 	return nil
 }
 
-func (w *Worker) processStellarRefundRequest(ctx context.Context, sr store.SignatureRequest) error {
+// buildPaymentWithdrawal builds a withdrawal transaction sourced from the
+// recipient which pays the asset from the bridge account
+func (w *Worker) buildPaymentWithdrawal(details StellarWithdrawalDetails, memoHash []byte) (xdr.TransactionEnvelope, error) {
+	// Load source account sequence along with the recipient trustline
+	recipient, err := txbuilder.LoadRecipient(w.StellarClient, details.Asset, details.Recipient)
+	if err != nil {
+		return xdr.TransactionEnvelope{}, err
+	}
+	// The recipient is the transaction source so it must exist
+	if !recipient.Exists {
+		return xdr.TransactionEnvelope{}, StellarRecipientNotFound
+	}
+	if err = checkSourceAccount(details.LedgerSequence, recipient.Account); err != nil {
+		return xdr.TransactionEnvelope{}, err
+	}
+
+	operations, err := w.StellarBuilder.WithdrawalOperations(
+		recipient,
+		details.Asset,
+		amount.StringFromInt64(details.Amount),
+	)
+	switch err {
+	case nil:
+	case txbuilder.ErrRecipientNotFound:
+		return xdr.TransactionEnvelope{}, StellarRecipientNotFound
+	case txbuilder.ErrTrustlineNotAuthorized:
+		return xdr.TransactionEnvelope{}, StellarTrustlineNotAuthorized
+	case txbuilder.ErrTrustlineLimitExceeded:
+		return xdr.TransactionEnvelope{}, StellarTrustlineLimitExceeded
+	default:
+		return xdr.TransactionEnvelope{}, errors.Wrap(err, "error checking withdrawal recipient")
+	}
+	tx, err := w.StellarBuilder.BuildWithdrawalTransaction(
+		details.Recipient,
+		operations,
+		recipient.Account.Sequence+1,
+		// TODO: ensure using WithdrawExpiration without any time buffer is safe
+		details.Deadline.Unix(),
+		memoHash,
+	)
+	if err != nil {
+		return xdr.TransactionEnvelope{}, errors.Wrap(err, "error building outgoing stellar transaction")
+	}
+	return tx, nil
+}
+
+// buildClaimableBalanceWithdrawal builds a withdrawal transaction sourced
+// from the bridge account which creates a claimable balance for the
+// recipient. The transaction does not depend on the state of the recipient
+// account. It uses the sequence number reserved for the deposit by
+// claimableBalanceSequence and remains valid until a bridge transaction with
+// a higher sequence number is executed, in which case the withdrawal is
+// signed again with a new reservation. pending is true if the previously
+// signed transaction of the deposit can still be executed, signing another
+// transaction could result in the withdrawal being executed twice.
+func (w *Worker) buildClaimableBalanceWithdrawal(
+	ctx context.Context,
+	db *store.DB,
+	deposit store.EthereumDeposit,
+	details StellarWithdrawalDetails,
+	memoHash []byte,
+) (tx xdr.TransactionEnvelope, pending bool, err error) {
+	bridgeAccount, err := w.StellarClient.AccountDetail(horizonclient.AccountRequest{
+		AccountID: w.StellarBuilder.BridgeAccount,
+	})
+	if err != nil {
+		return xdr.TransactionEnvelope{}, false, errors.Wrap(err, "error getting account details")
+	}
+	if err = checkSourceAccount(details.LedgerSequence, bridgeAccount); err != nil {
+		return xdr.TransactionEnvelope{}, false, err
+	}
+
+	outgoingTx, err := db.GetOutgoingStellarTransaction(ctx, store.Withdraw, deposit.ID)
+	if err != nil && err != sql.ErrNoRows {
+		return xdr.TransactionEnvelope{}, false, errors.Wrap(err, "error getting outgoing stellar transaction")
+	}
+	if err == nil &&
+		outgoingTx.SourceAccount == w.StellarBuilder.BridgeAccount &&
+		bridgeAccount.Sequence < outgoingTx.Sequence {
+		return xdr.TransactionEnvelope{}, true, nil
+	}
+
+	tx, err = w.StellarBuilder.BuildClaimableBalanceTransaction(
+		details.Asset,
+		details.Recipient,
+		amount.StringFromInt64(details.Amount),
+		claimableBalanceSequence(bridgeAccount.Sequence, deposit),
+		bridgeAccount.Sequence,
+		details.Deadline.Unix(),
+		memoHash,
+	)
+	if err != nil {
+		return xdr.TransactionEnvelope{}, false, errors.Wrap(err, "error building outgoing stellar transaction")
+	}
+	return tx, false, nil
+}
+
+// claimableBalanceSequence reserves a sequence number of the bridge account
+// for the claimable balance withdrawal of the given deposit. Reservations are
+// ordered like the deposits so withdrawals executed in deposit order do not
+// invalidate each other, and withdrawals of deposits in the same block are
+// told apart by their log index. The reservation only depends on the bridge
+// account and the deposit so all validators sign the same transaction.
+func claimableBalanceSequence(bridgeSequence int64, deposit store.EthereumDeposit) int64 {
+	offset := int64(deposit.BlockNumber%(1<<sequenceBlockBits))<<sequenceLogIndexBits |
+		int64(deposit.LogIndex%(1<<sequenceLogIndexBits))
+	return bridgeSequence + 1 + offset
+}
+
+// checkSourceAccount ensures the sequence number of the transaction source
+// was not bumped after the last ledger ingested by the validator so that
+// all validators sign the same transaction
+func checkSourceAccount(lastLedgerSequence uint32, sourceAccount horizon.Account) error {
+	if sourceAccount.SequenceLedger > 0 {
+		if lastLedgerSequence < sourceAccount.SequenceLedger {
+			return errors.New("skipping, account sequence ledger is higher than last ledger ingested")
+		}
+	} else {
+		if lastLedgerSequence < sourceAccount.LastModifiedLedger {
+			return errors.New("skipping, account sequence possibly bumped after last ledger ingested")
+		}
+	}
+	return nil
+}
+
+func (w *Worker) processStellarRefundRequest(ctx context.Context, db *store.DB, sr store.SignatureRequest) error {
 	if sr.DepositChain != store.Stellar {
 		return fmt.Errorf("deposits from %v are not supported", sr.DepositChain)
 	}
-	deposit, err := w.Store.GetStellarDeposit(ctx, sr.DepositID)
+	deposit, err := db.GetStellarDeposit(ctx, sr.DepositID)
 	if err != nil {
 		return errors.Wrap(err, "error getting ethereum deposit")
 	}
@@ -262,7 +555,7 @@ func (w *Worker) processStellarRefundRequest(ctx context.Context, sr store.Signa
 		SourceAccount: deposit.Sender,
 		Sequence:      tx.SeqNum(),
 	}
-	err = w.Store.UpsertOutgoingStellarTransaction(ctx, outgoingTx)
+	err = db.UpsertOutgoingStellarTransaction(ctx, outgoingTx)
 	if err != nil {
 		return errors.Wrap(err, "error upserting outgoing stellar transaction")
 	}
@@ -270,15 +563,19 @@ func (w *Worker) processStellarRefundRequest(ctx context.Context, sr store.Signa
 	return nil
 }
 
-func (w *Worker) processEthereumRefundRequest(ctx context.Context, sr store.SignatureRequest) error {
+func (w *Worker) processEthereumRefundRequest(ctx context.Context, db *store.DB, sr store.SignatureRequest) error {
 	if sr.DepositChain != store.Ethereum {
 		return fmt.Errorf("deposits from %v are not supported", sr.DepositChain)
 	}
-	deposit, err := w.Store.GetEthereumDeposit(ctx, sr.DepositID)
+	deposit, err := db.GetEthereumDeposit(ctx, sr.DepositID)
 	if err != nil {
 		return errors.Wrap(err, "error getting ethereum deposit")
 	}
 
+	if err = w.verifyEthereumDeposit(ctx, db, deposit); err != nil {
+		return errors.Wrap(err, "error verifying ethereum deposit")
+	}
+
 	if err = w.EthereumRefundValidator.CanRefund(ctx, deposit); err != nil {
 		return errors.Wrap(err, "error validating refund conditions")
 	}
@@ -288,8 +585,13 @@ func (w *Worker) processEthereumRefundRequest(ctx context.Context, sr store.Sign
 		return errors.Errorf("cannot convert value in wei to bit.Rat: %s", deposit.Amount)
 	}
 
+	ethSigner, err := LatestEthereumSigner(ctx, db, w.EthereumSigner)
+	if err != nil {
+		return err
+	}
+
 	expiration := int64(math.MaxInt64)
-	sig, err := w.EthereumSigner.SignWithdrawal(
+	sig, err := ethSigner.SignWithdrawal(
 		common.HexToHash(deposit.ID),
 		expiration,
 		common.HexToAddress(deposit.Sender),
@@ -300,14 +602,15 @@ func (w *Worker) processEthereumRefundRequest(ctx context.Context, sr store.Sign
 		return errors.Wrap(err, "error signing refund")
 	}
 
-	err = w.Store.UpsertEthereumSignature(ctx, store.EthereumSignature{
-		Address:    w.EthereumSigner.Address().String(),
+	err = db.UpsertEthereumSignature(ctx, store.EthereumSignature{
+		Address:    ethSigner.Address().String(),
 		Signature:  hex.EncodeToString(sig),
 		Action:     sr.Action,
 		DepositID:  sr.DepositID,
 		Expiration: expiration,
 		Token:      deposit.Token,
 		Amount:     deposit.Amount,
+		Version:    ethSigner.Version(),
 	})
 	if err != nil {
 		return errors.Wrap(err, "error upserting etherum signature")
@@ -316,11 +619,11 @@ func (w *Worker) processEthereumRefundRequest(ctx context.Context, sr store.Sign
 	return nil
 }
 
-func (w *Worker) processEthereumWithdrawalRequest(ctx context.Context, sr store.SignatureRequest) error {
+func (w *Worker) processEthereumWithdrawalRequest(ctx context.Context, db *store.DB, sr store.SignatureRequest) error {
 	if sr.DepositChain != store.Stellar {
 		return fmt.Errorf("deposits from %v are not supported", sr.DepositChain)
 	}
-	deposit, err := w.Store.GetStellarDeposit(ctx, sr.DepositID)
+	deposit, err := db.GetStellarDeposit(ctx, sr.DepositID)
 	if err != nil {
 		return errors.Wrap(err, "error getting stellar deposit")
 	}
@@ -330,7 +633,12 @@ func (w *Worker) processEthereumWithdrawalRequest(ctx context.Context, sr store.
 		return errors.Wrap(err, "error validating withdrawal conditions")
 	}
 
-	sig, err := w.EthereumSigner.SignWithdrawal(
+	ethSigner, err := LatestEthereumSigner(ctx, db, w.EthereumSigner)
+	if err != nil {
+		return err
+	}
+
+	sig, err := ethSigner.SignWithdrawal(
 		common.HexToHash(deposit.ID),
 		details.Deadline.Unix(),
 		details.Recipient,
@@ -341,14 +649,27 @@ func (w *Worker) processEthereumWithdrawalRequest(ctx context.Context, sr store.
 		return errors.Wrap(err, "error signing withdrawal")
 	}
 
-	err = w.Store.UpsertEthereumSignature(ctx, store.EthereumSignature{
-		Address:    w.EthereumSigner.Address().String(),
+	// The fee is committed together with the signature
+	err = db.UpsertBridgeFee(ctx, store.BridgeFee{
+		DepositChain:     sr.DepositChain,
+		DepositID:        sr.DepositID,
+		Asset:            details.Token.String(),
+		Amount:           details.Fee.String(),
+		WithdrawalAmount: details.Amount.String(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "error upserting bridge fee")
+	}
+
+	err = db.UpsertEthereumSignature(ctx, store.EthereumSignature{
+		Address:    ethSigner.Address().String(),
 		Signature:  hex.EncodeToString(sig),
 		Action:     sr.Action,
 		DepositID:  sr.DepositID,
 		Expiration: details.Deadline.Unix(),
 		Token:      details.Token.String(),
 		Amount:     details.Amount.String(),
+		Version:    ethSigner.Version(),
 	})
 	if err != nil {
 		return errors.Wrap(err, "error upserting etherum signature")
@@ -356,3 +677,33 @@ func (w *Worker) processEthereumWithdrawalRequest(ctx context.Context, sr store.
 
 	return nil
 }
+
+// verifyEthereumDeposit ensures that the block containing the deposit is still
+// part of the canonical chain. If the block was reorged out the deposit and all
+// its pending signature requests are invalidated.
+func (w *Worker) verifyEthereumDeposit(ctx context.Context, db *store.DB, deposit store.EthereumDeposit) error {
+	// Deposits stored before block hashes were recorded cannot be verified
+	if deposit.BlockHash == "" {
+		return nil
+	}
+
+	err := w.EthereumObserver.VerifyBlock(
+		ctx,
+		deposit.BlockNumber,
+		common.HexToHash(deposit.BlockHash),
+	)
+	if err != ethereum.ErrBlockNotCanonical {
+		return err
+	}
+
+	w.log.WithFields(log.F{"deposit": deposit.ID, "block": deposit.BlockNumber}).
+		Warn("Ethereum deposit was reorged out, invalidating")
+
+	// db is the transaction in which the signature request was claimed so
+	// the invalidation is committed along with the request status
+	if err = db.InvalidateEthereumDeposit(ctx, deposit.ID); err != nil {
+		return errors.Wrap(err, "error invalidating ethereum deposit")
+	}
+
+	return EthereumDepositReorged
+}
@@ -0,0 +1,21 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/starbridge/store"
+)
+
+func TestClaimableBalanceSequence(t *testing.T) {
+	const bridgeSequence = int64(1000)
+	first := claimableBalanceSequence(bridgeSequence, store.EthereumDeposit{BlockNumber: 15000000, LogIndex: 3})
+	sameBlock := claimableBalanceSequence(bridgeSequence, store.EthereumDeposit{BlockNumber: 15000000, LogIndex: 4})
+	later := claimableBalanceSequence(bridgeSequence, store.EthereumDeposit{BlockNumber: 15000001, LogIndex: 0})
+
+	// reservations are above the bridge sequence and ordered like deposits
+	assert.Greater(t, first, bridgeSequence)
+	assert.Greater(t, sameBlock, first)
+	assert.Greater(t, later, sameBlock)
+}
@@ -0,0 +1,120 @@
+package backend
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stellar/go/support/log"
+
+	"github.com/stellar/starbridge/store"
+)
+
+// SignatureNotifier wakes up HTTP requests waiting for a signature request
+// to be processed. The worker notifies processed requests through Postgres
+// NOTIFY so it works when the worker and the HTTP server run in different
+// processes sharing a database.
+type SignatureNotifier struct {
+	listener *pq.Listener
+	log      *log.Entry
+
+	lock    sync.Mutex
+	waiters map[string][]chan struct{}
+}
+
+// NewSignatureNotifier creates a SignatureNotifier listening on a dedicated
+// connection to the given database
+func NewSignatureNotifier(postgresDSN string) *SignatureNotifier {
+	n := &SignatureNotifier{
+		log:     log.WithField("service", "signature_notifier"),
+		waiters: map[string][]chan struct{}{},
+	}
+	n.listener = pq.NewListener(postgresDSN, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			n.log.WithField("err", err).Warn("Signature notification listener error")
+		}
+	})
+	return n
+}
+
+// Run dispatches notifications to waiting requests until the context is
+// cancelled
+func (n *SignatureNotifier) Run(ctx context.Context) {
+	defer n.listener.Close()
+	if err := n.listener.Listen(store.SignatureRequestChannel); err != nil {
+		n.log.WithField("err", err).Error("Cannot listen for signature notifications")
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case notification := <-n.listener.Notify:
+			// A nil notification is sent after the connection was
+			// re-established, notifications may have been lost so all
+			// waiting requests are woken up to check the database
+			if notification == nil {
+				n.notifyAll()
+			} else {
+				n.notify(notification.Extra)
+			}
+		case <-time.After(90 * time.Second):
+			go func() {
+				if err := n.listener.Ping(); err != nil {
+					n.log.WithField("err", err).Warn("Signature notification listener ping failed")
+				}
+			}()
+		}
+	}
+}
+
+// Subscribe returns a channel which is closed when the given signature
+// request is processed and a function which must be called to release the
+// subscription
+func (n *SignatureNotifier) Subscribe(request store.SignatureRequest) (<-chan struct{}, func()) {
+	key := store.SignatureRequestPayload(request)
+	ch := make(chan struct{})
+
+	n.lock.Lock()
+	n.waiters[key] = append(n.waiters[key], ch)
+	n.lock.Unlock()
+
+	return ch, func() {
+		n.lock.Lock()
+		defer n.lock.Unlock()
+		waiters := n.waiters[key]
+		for i, waiter := range waiters {
+			if waiter == ch {
+				waiters = append(waiters[:i], waiters[i+1:]...)
+				break
+			}
+		}
+		if len(waiters) == 0 {
+			delete(n.waiters, key)
+		} else {
+			n.waiters[key] = waiters
+		}
+	}
+}
+
+func (n *SignatureNotifier) notify(key string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for _, ch := range n.waiters[key] {
+		close(ch)
+	}
+	delete(n.waiters, key)
+}
+
+func (n *SignatureNotifier) notifyAll() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for key, waiters := range n.waiters {
+		for _, ch := range waiters {
+			close(ch)
+		}
+		delete(n.waiters, key)
+	}
+}
@@ -0,0 +1,120 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/problem"
+
+	"github.com/stellar/starbridge/ethereum"
+	"github.com/stellar/starbridge/store"
+)
+
+const (
+	// MaxSigners is the maximum number of validators supported by the bridge
+	// contract
+	MaxSigners = 255
+	// MaxStellarSigners is the maximum number of signers of a Stellar account
+	MaxStellarSigners = 20
+)
+
+var (
+	InvalidSigners = problem.P{
+		Type:   "invalid_signers",
+		Title:  "Invalid Signers",
+		Status: http.StatusBadRequest,
+		Detail: "The signers must be a non-empty list of at most 255 unique addresses sorted in ascending order.",
+	}
+	InvalidStellarSigners = problem.P{
+		Type:   "invalid_stellar_signers",
+		Title:  "Invalid Stellar Signers",
+		Status: http.StatusBadRequest,
+		Detail: "The signers must be a non-empty list of at most 20 unique Stellar account ids sorted in ascending order.",
+	}
+	InvalidMinThreshold = problem.P{
+		Type:   "invalid_min_threshold",
+		Title:  "Invalid Minimum Threshold",
+		Status: http.StatusBadRequest,
+		Detail: "The minimum threshold must be greater than half of the signers and at most the number of signers.",
+	}
+	InvalidStellarSetSignersSequence = problem.P{
+		Type:   "invalid_stellar_set_signers_sequence",
+		Title:  "Invalid Stellar Set Signers Sequence",
+		Status: http.StatusBadRequest,
+		Detail: "The sequence number must be greater than the current sequence number of the bridge account.",
+	}
+)
+
+// ValidateEthereumSigners checks that the given validator set is accepted by
+// the updateSigners function of the bridge contract
+func ValidateEthereumSigners(signers []common.Address, minThreshold uint8) error {
+	if len(signers) == 0 || len(signers) > MaxSigners {
+		return InvalidSigners
+	}
+	for i := 1; i < len(signers); i++ {
+		if bytes.Compare(signers[i-1].Bytes(), signers[i].Bytes()) >= 0 {
+			return InvalidSigners
+		}
+	}
+	return validateMinThreshold(len(signers), minThreshold)
+}
+
+// ValidateStellarSetSignersRequest checks that the given Stellar signers can
+// be used as the validator set of the bridge account
+func ValidateStellarSetSignersRequest(signers []string, minThreshold uint8, expiration int64, now time.Time) error {
+	if len(signers) == 0 || len(signers) > MaxStellarSigners {
+		return InvalidStellarSigners
+	}
+	for i, signer := range signers {
+		if !strkey.IsValidEd25519PublicKey(signer) {
+			return InvalidStellarSigners
+		}
+		if i > 0 && signers[i-1] >= signer {
+			return InvalidStellarSigners
+		}
+	}
+	if err := validateMinThreshold(len(signers), minThreshold); err != nil {
+		return err
+	}
+	return validateGovernanceExpiration(big.NewInt(expiration), now)
+}
+
+func validateMinThreshold(numSigners int, minThreshold uint8) error {
+	if int(minThreshold) <= numSigners/2 || int(minThreshold) > numSigners {
+		return InvalidMinThreshold
+	}
+	return nil
+}
+
+// LatestEthereumSigner returns the given signer configured with the most
+// recent bridge contract version ingested from RegisterSigners events. The
+// configured version is used until the first RegisterSigners event is
+// ingested.
+func LatestEthereumSigner(ctx context.Context, db *store.DB, signer ethereum.Signer) (ethereum.Signer, error) {
+	latest, err := db.GetLatestEthereumSigners(ctx)
+	if err == sql.ErrNoRows {
+		return signer, nil
+	} else if err != nil {
+		return ethereum.Signer{}, errors.Wrap(err, "error getting latest ethereum signers")
+	}
+	return signer.WithVersion(latest.Version), nil
+}
+
+// IsStaleEthereumSignature returns true if the given signature was created
+// for a bridge contract version which has since been replaced by updateSigners
+func IsStaleEthereumSignature(ctx context.Context, db *store.DB, sig store.EthereumSignature) (bool, error) {
+	latest, err := db.GetLatestEthereumSigners(ctx)
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "error getting latest ethereum signers")
+	}
+	return sig.Version < latest.Version, nil
+}
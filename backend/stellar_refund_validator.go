@@ -25,10 +25,9 @@ var RefundAlreadyExecuted = problem.P{
 // StellarRefundValidator checks if it is possible to
 // refund a deposit to depositor's Stellar account.
 type StellarRefundValidator struct {
-	Session                db.SessionInterface
-	WithdrawalWindow       time.Duration
-	Observer               ethereum.Observer
-	EthereumFinalityBuffer uint64
+	Session          db.SessionInterface
+	WithdrawalWindow time.Duration
+	Observer         ethereum.Observer
 }
 
 // StellarRefundDetails includes metadata about the
@@ -67,6 +66,14 @@ func (s StellarRefundValidator) CanRefund(ctx context.Context, deposit store.Ste
 		return StellarRefundDetails{}, RefundAlreadyExecuted
 	}
 
+	// Deposits without a valid Ethereum recipient can never be withdrawn
+	// so they can be refunded immediately
+	if deposit.InvalidReason != "" {
+		return StellarRefundDetails{
+			LedgerSequence: lastLedgerSequence,
+		}, nil
+	}
+
 	withdrawalDeadline := time.Unix(deposit.LedgerTime, 0).Add(s.WithdrawalWindow)
 
 	// rollback to release used DB connection because further checks
@@ -75,22 +82,27 @@ func (s StellarRefundValidator) CanRefund(ctx context.Context, deposit store.Ste
 
 	// Checks on Ethereum side:
 	// - Ensure that there was no withdrawal to Ethereum account
-	// - The response from the client is after the withdrawal deadline
+	// - The latest final block is after the withdrawal deadline
+	//
+	// The final block is fetched before the request status so that the
+	// request status is queried at a block which is not older than the
+	// final block.
+	block, err := s.Observer.GetLatestFinalBlock(ctx)
+	if err == ethereum.ErrNoFinalBlock {
+		return StellarRefundDetails{}, EthereumNodeBehind
+	} else if err != nil {
+		return StellarRefundDetails{}, errors.Wrap(err, "error getting final block from ethereum observer")
+	}
+
 	depositID := common.HexToHash(deposit.ID)
 	requestStatus, err := s.Observer.GetRequestStatus(ctx, depositID)
 	if err != nil {
 		return StellarRefundDetails{}, errors.Wrap(err, "error getting request status from ethereum observer")
 	}
-
-	if requestStatus.BlockNumber <= s.EthereumFinalityBuffer {
+	if requestStatus.BlockNumber < block.Number {
 		return StellarRefundDetails{}, EthereumNodeBehind
 	}
 
-	block, err := s.Observer.GetBlockByNumber(ctx, requestStatus.BlockNumber-s.EthereumFinalityBuffer)
-	if err != nil {
-		return StellarRefundDetails{}, errors.Wrap(err, "error getting block from ethereum observer")
-	}
-
 	if !block.Time.After(withdrawalDeadline) {
 		return StellarRefundDetails{}, WithdrawalWindowStillActive
 	}
@@ -41,7 +41,8 @@ var (
 type StellarWithdrawalValidator struct {
 	Session          db.SessionInterface
 	WithdrawalWindow time.Duration
-	Converter        AssetConverter
+	AssetMappings    *AssetMappings
+	TransferLimits   TransferLimits
 }
 
 // StellarWithdrawalDetails includes metadata about the
@@ -59,12 +60,23 @@ type StellarWithdrawalDetails struct {
 	// Asset is the Stellar asset which will be transferred to the
 	// recipient.
 	Asset string
-	// Amount is the amount which will be transferred to the recipient.
+	// Amount is the amount which will be transferred to the recipient
+	// after deducting the bridge fee.
 	Amount int64
+	// Fee is the bridge fee deducted from the deposit.
+	Fee int64
 }
 
 func (s StellarWithdrawalValidator) CanWithdraw(ctx context.Context, deposit store.EthereumDeposit) (StellarWithdrawalDetails, error) {
-	stellarAsset, stellarAmount, err := s.Converter.ToStellar(deposit.Token, deposit.Amount)
+	converter, err := s.AssetMappings.ConverterAt(time.Unix(deposit.BlockTime, 0))
+	if err != nil {
+		return StellarWithdrawalDetails{}, errors.Wrap(err, "error loading asset mappings")
+	}
+	stellarAsset, grossAmount, err := converter.ToStellar(deposit.Token, deposit.Amount)
+	if err != nil {
+		return StellarWithdrawalDetails{}, err
+	}
+	stellarAmount, fee, err := converter.DeductFee(stellarAsset, grossAmount)
 	if err != nil {
 		return StellarWithdrawalDetails{}, err
 	}
@@ -117,11 +129,16 @@ func (s StellarWithdrawalValidator) CanWithdraw(ctx context.Context, deposit sto
 		return StellarWithdrawalDetails{}, WithdrawalAlreadyExecuted
 	}
 
+	if err = s.TransferLimits.CheckEthereumDeposit(ctx, &dbStore, deposit); err != nil {
+		return StellarWithdrawalDetails{}, err
+	}
+
 	return StellarWithdrawalDetails{
 		Deadline:       withdrawalDeadline,
 		Recipient:      destinationAccountID,
 		LedgerSequence: lastLedgerSequence,
 		Asset:          stellarAsset,
 		Amount:         stellarAmount,
+		Fee:            fee,
 	}, nil
 }
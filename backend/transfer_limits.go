@@ -0,0 +1,357 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/problem"
+
+	"github.com/stellar/starbridge/store"
+)
+
+var (
+	TransferAmountTooSmall = problem.P{
+		Type:   "transfer_amount_too_small",
+		Title:  "Transfer Amount Too Small",
+		Status: http.StatusBadRequest,
+		Detail: "The deposited amount is below the minimum transfer size of the bridge. " +
+			"Refund the deposit once the withdrawal period has expired.",
+	}
+	TransferAmountTooLarge = problem.P{
+		Type:   "transfer_amount_too_large",
+		Title:  "Transfer Amount Too Large",
+		Status: http.StatusBadRequest,
+		Detail: "The deposited amount is above the maximum transfer size of the bridge. " +
+			"Refund the deposit once the withdrawal period has expired.",
+	}
+	AssetVolumeExceeded = problem.P{
+		Type:   "asset_volume_exceeded",
+		Title:  "Asset Volume Exceeded",
+		Status: http.StatusBadRequest,
+		Detail: "The deposit exceeds the volume cap of the asset within the rolling window. " +
+			"Refund the deposit once the withdrawal period has expired.",
+	}
+	SenderVolumeExceeded = problem.P{
+		Type:   "sender_volume_exceeded",
+		Title:  "Sender Volume Exceeded",
+		Status: http.StatusBadRequest,
+		Detail: "The deposit exceeds the volume cap of the sender within the rolling window. " +
+			"Refund the deposit once the withdrawal period has expired.",
+	}
+	GlobalTransferLimitExceeded = problem.P{
+		Type:   "global_transfer_limit_exceeded",
+		Title:  "Global Transfer Limit Exceeded",
+		Status: http.StatusBadRequest,
+		Detail: "The deposit exceeds the maximum number of transfers within the rolling window. " +
+			"Refund the deposit once the withdrawal period has expired.",
+	}
+	GlobalVolumeExceeded = problem.P{
+		Type:   "global_volume_exceeded",
+		Title:  "Global Volume Exceeded",
+		Status: http.StatusBadRequest,
+		Detail: "The deposit exceeds the volume cap of the bridge within the rolling window. " +
+			"Refund the deposit once the withdrawal period has expired.",
+	}
+	AssetValueMissing = problem.P{
+		Type:   "asset_value_missing",
+		Title:  "Asset Value Missing",
+		Status: http.StatusBadRequest,
+		Detail: "The deposited asset has no value configured for the volume cap of the bridge. " +
+			"Refund the deposit once the withdrawal period has expired.",
+	}
+)
+
+// TransferLimitsConfig is the toml representation of the transfer limits
+// enforced by the validators
+type TransferLimitsConfig struct {
+	// Window is the duration of the rolling window used for volume caps,
+	// for example "24h"
+	Window string `toml:"window" valid:"-"`
+	// MaxTransfers is the maximum number of deposits of any asset made to
+	// the same chain within the window. 0 disables the limit.
+	MaxTransfers int64 `toml:"max_transfers" valid:"-"`
+	// MaxVolume is the maximum total value of the deposits of all assets
+	// made to the same chain within the window. The value of a deposit is
+	// its amount multiplied by the value of its asset. Empty disables the
+	// limit.
+	MaxVolume string `toml:"max_volume" valid:"-"`
+
+	Assets []TransferLimitConfigEntry `toml:"asset" valid:"-"`
+}
+
+// TransferLimitConfigEntry is the toml representation of the limits which
+// apply to deposits of a single asset. Amounts are expressed in the units
+// of the deposit chain: decimal Stellar amounts for Stellar assets and base
+// units for Ethereum tokens. Empty values disable the corresponding limit.
+type TransferLimitConfigEntry struct {
+	// Asset is a Stellar asset or an Ethereum token address
+	Asset string `toml:"asset" valid:"-"`
+	// MinAmount is the minimum amount of a single deposit
+	MinAmount string `toml:"min_amount" valid:"-"`
+	// MaxAmount is the maximum amount of a single deposit
+	MaxAmount string `toml:"max_amount" valid:"-"`
+	// MaxVolume is the maximum total amount deposited within the window
+	MaxVolume string `toml:"max_volume" valid:"-"`
+	// MaxSenderVolume is the maximum total amount deposited by a single
+	// sender within the window
+	MaxSenderVolume string `toml:"max_sender_volume" valid:"-"`
+	// Value is the value of one unit of the asset counted towards the
+	// global volume cap. Deposits of assets without a value are rejected
+	// when the global volume cap is enabled.
+	Value string `toml:"value" valid:"-"`
+}
+
+// maxLimitDecimals is the maximum number of decimals of a transfer limit
+const maxLimitDecimals = 18
+
+type assetLimits struct {
+	minAmount, maxAmount, maxVolume, maxSenderVolume, value *big.Rat
+}
+
+// TransferLimits enforces per asset transfer sizes and rolling window volume
+// caps. Volumes are computed from the deposits which precede a deposit on its
+// chain so that all validators reach the same decision. Deposits outside of
+// the transfer size limits of their asset are not counted so that deposits
+// which are always rejected cannot exhaust the caps.
+//
+// Deposits rejected by a volume cap are counted towards the caps. Whether a
+// deposit exceeds a cap depends on all the deposits preceding it so excluding
+// them cannot be expressed as an aggregate over the window. Exhausting a cap
+// with deposits which are rejected costs as much as exhausting it with valid
+// deposits because rejected deposits can only be refunded once the withdrawal
+// window has expired. The zero value does not enforce any limits.
+type TransferLimits struct {
+	window       time.Duration
+	maxTransfers int64
+	maxVolume    *big.Rat
+	assets       map[string]assetLimits
+	// ranges are the transfer size limits of each asset as decimal strings
+	ranges map[string]store.AmountRange
+	// values are the values of the assets counted towards the global
+	// volume cap as decimal strings
+	values map[string]string
+}
+
+// NewTransferLimits constructs a new instance of TransferLimits
+func NewTransferLimits(config TransferLimitsConfig) (TransferLimits, error) {
+	limits := TransferLimits{
+		maxTransfers: config.MaxTransfers,
+		assets:       map[string]assetLimits{},
+		ranges:       map[string]store.AmountRange{},
+		values:       map[string]string{},
+	}
+	if config.Window != "" {
+		window, err := time.ParseDuration(config.Window)
+		if err != nil || window <= 0 {
+			return limits, fmt.Errorf("%s is not a valid transfer limit window", config.Window)
+		}
+		limits.window = window
+	}
+	if config.MaxTransfers < 0 {
+		return limits, fmt.Errorf("max transfers cannot be negative")
+	}
+	if config.MaxVolume != "" {
+		maxVolume, ok := new(big.Rat).SetString(config.MaxVolume)
+		if !ok || maxVolume.Sign() < 0 || !isDecimal(maxVolume) {
+			return limits, fmt.Errorf("%s is not a valid global volume cap", config.MaxVolume)
+		}
+		limits.maxVolume = maxVolume
+	}
+
+	for _, entry := range config.Assets {
+		if !isAsset(entry.Asset) && !common.IsHexAddress(entry.Asset) {
+			return limits, fmt.Errorf("%s is not a valid stellar asset or ethereum token", entry.Asset)
+		}
+		key := limitsKey(entry.Asset)
+		if _, exists := limits.assets[key]; exists {
+			return limits, fmt.Errorf("asset %v is repeated in the transfer limits", entry.Asset)
+		}
+
+		var parsed assetLimits
+		for _, field := range []struct {
+			value  string
+			target **big.Rat
+		}{
+			{entry.MinAmount, &parsed.minAmount},
+			{entry.MaxAmount, &parsed.maxAmount},
+			{entry.MaxVolume, &parsed.maxVolume},
+			{entry.MaxSenderVolume, &parsed.maxSenderVolume},
+			{entry.Value, &parsed.value},
+		} {
+			if field.value == "" {
+				continue
+			}
+			value, ok := new(big.Rat).SetString(field.value)
+			if !ok || value.Sign() < 0 || !isDecimal(value) {
+				return limits, fmt.Errorf("%s is not a valid transfer limit for %s", field.value, entry.Asset)
+			}
+			*field.target = value
+		}
+		if parsed.minAmount != nil || parsed.maxAmount != nil {
+			limits.ranges[key] = store.AmountRange{
+				Min: decimalString(parsed.minAmount),
+				Max: decimalString(parsed.maxAmount),
+			}
+		}
+		if parsed.value != nil {
+			limits.values[key] = decimalString(parsed.value)
+		}
+		if (parsed.maxVolume != nil || parsed.maxSenderVolume != nil) && limits.window == 0 {
+			return limits, fmt.Errorf("volume caps for %s require a transfer limit window", entry.Asset)
+		}
+		limits.assets[key] = parsed
+	}
+	if limits.maxTransfers > 0 && limits.window == 0 {
+		return limits, fmt.Errorf("max transfers requires a transfer limit window")
+	}
+	if limits.maxVolume != nil && limits.window == 0 {
+		return limits, fmt.Errorf("max volume requires a transfer limit window")
+	}
+
+	return limits, nil
+}
+
+// isDecimal returns true if value can be represented exactly with at most
+// maxLimitDecimals decimals, which is required to compare deposit amounts
+// against the limit in the database
+func isDecimal(value *big.Rat) bool {
+	decimal, _ := new(big.Rat).SetString(value.FloatString(maxLimitDecimals))
+	return decimal.Cmp(value) == 0
+}
+
+func decimalString(value *big.Rat) string {
+	if value == nil {
+		return ""
+	}
+	return value.FloatString(maxLimitDecimals)
+}
+
+func limitsKey(asset string) string {
+	if common.IsHexAddress(asset) {
+		return strings.ToLower(common.HexToAddress(asset).String())
+	}
+	return asset
+}
+
+// enabled returns true if any volume based limit requires database access
+func (l TransferLimits) enabled() bool {
+	return len(l.assets) > 0 || l.maxTransfers > 0 || l.maxVolume != nil
+}
+
+// CheckStellarDeposit checks the given Stellar deposit against the limits
+// of the deposited asset
+func (l TransferLimits) CheckStellarDeposit(ctx context.Context, db *store.DB, deposit store.StellarDeposit) error {
+	if !l.enabled() {
+		return nil
+	}
+	return l.check(
+		limitsKey(deposit.Asset),
+		deposit.Sender,
+		deposit.Amount,
+		deposit.LedgerTime,
+		func(filter store.DepositVolumeFilter) (store.DepositVolume, error) {
+			return db.GetStellarDepositVolume(ctx, deposit, filter)
+		},
+	)
+}
+
+// CheckEthereumDeposit checks the given Ethereum deposit against the limits
+// of the deposited token. Deposits can also be inserted through the http api
+// so EthereumNodeBehind is returned until the ingester has caught up with the
+// block containing the deposit.
+func (l TransferLimits) CheckEthereumDeposit(ctx context.Context, db *store.DB, deposit store.EthereumDeposit) error {
+	if !l.enabled() {
+		return nil
+	}
+	lastBlock, err := db.GetLastEthereumBlock(ctx)
+	if err == sql.ErrNoRows || (err == nil && lastBlock.Number < deposit.BlockNumber) {
+		return EthereumNodeBehind
+	} else if err != nil {
+		return errors.Wrap(err, "error getting last ethereum block")
+	}
+	return l.check(
+		limitsKey(deposit.Token),
+		deposit.Sender,
+		deposit.Amount,
+		deposit.BlockTime,
+		func(filter store.DepositVolumeFilter) (store.DepositVolume, error) {
+			return db.GetEthereumDepositVolume(ctx, deposit, filter)
+		},
+	)
+}
+
+func (l TransferLimits) check(
+	asset, sender, depositAmount string,
+	depositTime int64,
+	volume func(filter store.DepositVolumeFilter) (store.DepositVolume, error),
+) error {
+	since := depositTime - int64(l.window/time.Second)
+
+	amount, ok := new(big.Rat).SetString(depositAmount)
+	if !ok {
+		return WithdrawalAmountInvalid
+	}
+	limits := l.assets[asset]
+	if limits.minAmount != nil && amount.Cmp(limits.minAmount) < 0 {
+		return TransferAmountTooSmall
+	}
+	if limits.maxAmount != nil && amount.Cmp(limits.maxAmount) > 0 {
+		return TransferAmountTooLarge
+	}
+
+	if l.maxTransfers > 0 || l.maxVolume != nil {
+		total, err := volume(store.DepositVolumeFilter{Since: since, Ranges: l.ranges, Values: l.values})
+		if err != nil {
+			return errors.Wrap(err, "error getting deposit volume")
+		}
+		if l.maxTransfers > 0 && total.Count+1 > l.maxTransfers {
+			return GlobalTransferLimitExceeded
+		}
+		if l.maxVolume != nil {
+			if limits.value == nil {
+				return AssetValueMissing
+			}
+			totalValue, ok := new(big.Rat).SetString(total.Amount)
+			if !ok {
+				return errors.Errorf("invalid deposit volume: %s", total.Amount)
+			}
+			value := new(big.Rat).Mul(amount, limits.value)
+			if totalValue.Add(totalValue, value).Cmp(l.maxVolume) > 0 {
+				return GlobalVolumeExceeded
+			}
+		}
+	}
+
+	for _, volumeCap := range []struct {
+		max    *big.Rat
+		filter store.DepositVolumeFilter
+		err    error
+	}{
+		{limits.maxVolume, store.DepositVolumeFilter{Asset: asset, Since: since, Ranges: l.ranges}, AssetVolumeExceeded},
+		{limits.maxSenderVolume, store.DepositVolumeFilter{Asset: asset, Sender: sender, Since: since, Ranges: l.ranges}, SenderVolumeExceeded},
+	} {
+		if volumeCap.max == nil {
+			continue
+		}
+		result, err := volume(volumeCap.filter)
+		if err != nil {
+			return errors.Wrap(err, "error getting deposit volume")
+		}
+		total, ok := new(big.Rat).SetString(result.Amount)
+		if !ok {
+			return errors.Errorf("invalid deposit volume: %s", result.Amount)
+		}
+		if total.Add(total, amount).Cmp(volumeCap.max) > 0 {
+			return volumeCap.err
+		}
+	}
+
+	return nil
+}
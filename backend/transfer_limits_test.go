@@ -0,0 +1,264 @@
+package backend
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/starbridge/store"
+)
+
+const (
+	testUSDC  = "USDC:GABPNFVU2NHSHRCJ4HLW6BAMVOT7YIHMJBBQ3DMSSPRKEYDSYGRE5JWH"
+	testToken = "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+)
+
+func TestNewTransferLimits(t *testing.T) {
+	for _, testCase := range []struct {
+		name        string
+		config      TransferLimitsConfig
+		expectedErr string
+	}{
+		{name: "empty"},
+		{
+			name: "valid",
+			config: TransferLimitsConfig{
+				Window:       "24h",
+				MaxTransfers: 100,
+				MaxVolume:    "50000",
+				Assets: []TransferLimitConfigEntry{
+					{Asset: testUSDC, MinAmount: "1", MaxAmount: "1000.5", MaxVolume: "10000", MaxSenderVolume: "2000", Value: "1"},
+					{Asset: testToken, MinAmount: "1000000", Value: "0.000001"},
+				},
+			},
+		},
+		{
+			name:        "invalid window",
+			config:      TransferLimitsConfig{Window: "1 day"},
+			expectedErr: "1 day is not a valid transfer limit window",
+		},
+		{
+			name:        "negative window",
+			config:      TransferLimitsConfig{Window: "-1h"},
+			expectedErr: "-1h is not a valid transfer limit window",
+		},
+		{
+			name:        "negative max transfers",
+			config:      TransferLimitsConfig{Window: "1h", MaxTransfers: -1},
+			expectedErr: "max transfers cannot be negative",
+		},
+		{
+			name:        "max transfers without window",
+			config:      TransferLimitsConfig{MaxTransfers: 1},
+			expectedErr: "max transfers requires a transfer limit window",
+		},
+		{
+			name:        "invalid max volume",
+			config:      TransferLimitsConfig{Window: "1h", MaxVolume: "-5"},
+			expectedErr: "-5 is not a valid global volume cap",
+		},
+		{
+			name:        "max volume without window",
+			config:      TransferLimitsConfig{MaxVolume: "5"},
+			expectedErr: "max volume requires a transfer limit window",
+		},
+		{
+			name:        "invalid asset",
+			config:      TransferLimitsConfig{Assets: []TransferLimitConfigEntry{{Asset: "USDC"}}},
+			expectedErr: "USDC is not a valid stellar asset or ethereum token",
+		},
+		{
+			name: "repeated token",
+			config: TransferLimitsConfig{Assets: []TransferLimitConfigEntry{
+				{Asset: testToken},
+				{Asset: "0x5fbdb2315678afecb367f032d93f642f64180aa3"},
+			}},
+			expectedErr: "asset 0x5fbdb2315678afecb367f032d93f642f64180aa3 is repeated in the transfer limits",
+		},
+		{
+			name:        "negative limit",
+			config:      TransferLimitsConfig{Assets: []TransferLimitConfigEntry{{Asset: testUSDC, MinAmount: "-1"}}},
+			expectedErr: "-1 is not a valid transfer limit for " + testUSDC,
+		},
+		{
+			name:        "non decimal limit",
+			config:      TransferLimitsConfig{Assets: []TransferLimitConfigEntry{{Asset: testUSDC, MaxAmount: "1/3"}}},
+			expectedErr: "1/3 is not a valid transfer limit for " + testUSDC,
+		},
+		{
+			name:        "invalid value",
+			config:      TransferLimitsConfig{Assets: []TransferLimitConfigEntry{{Asset: testUSDC, Value: "x"}}},
+			expectedErr: "x is not a valid transfer limit for " + testUSDC,
+		},
+		{
+			name:        "volume without window",
+			config:      TransferLimitsConfig{Assets: []TransferLimitConfigEntry{{Asset: testUSDC, MaxVolume: "10"}}},
+			expectedErr: "volume caps for " + testUSDC + " require a transfer limit window",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := NewTransferLimits(testCase.config)
+			if testCase.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, testCase.expectedErr)
+			}
+		})
+	}
+}
+
+func TestTransferLimitsCheck(t *testing.T) {
+	limits, err := NewTransferLimits(TransferLimitsConfig{
+		Window:       "1h",
+		MaxTransfers: 10,
+		Assets: []TransferLimitConfigEntry{
+			{Asset: testUSDC, MinAmount: "1", MaxAmount: "100", MaxVolume: "500", MaxSenderVolume: "150"},
+			{Asset: testToken, MaxAmount: "1000000"},
+		},
+	})
+	require.NoError(t, err)
+	expectedRanges := map[string]store.AmountRange{
+		testUSDC: {Min: "1.000000000000000000", Max: "100.000000000000000000"},
+		"0x5fbdb2315678afecb367f032d93f642f64180aa3": {Max: "1000000.000000000000000000"},
+	}
+
+	for _, testCase := range []struct {
+		name         string
+		asset        string
+		amount       string
+		count        int64
+		assetVolume  string
+		senderVolume string
+		expectedErr  error
+	}{
+		{"within limits", testUSDC, "50", 9, "400", "100", nil},
+		{"unlimited asset", "native", "1000000", 0, "0", "0", nil},
+		{"transfer count", testUSDC, "50", 10, "0", "0", GlobalTransferLimitExceeded},
+		{"invalid amount", testUSDC, "abc", 0, "0", "0", WithdrawalAmountInvalid},
+		{"too small", testUSDC, "0.9999999", 0, "0", "0", TransferAmountTooSmall},
+		{"too large", testUSDC, "100.0000001", 0, "0", "0", TransferAmountTooLarge},
+		{"minimum amount", testUSDC, "1", 0, "0", "0", nil},
+		{"maximum amount", testUSDC, "100", 0, "400", "50", nil},
+		{"asset volume", testUSDC, "100.0000000", 0, "400.0000001", "0", AssetVolumeExceeded},
+		{"sender volume", testUSDC, "50", 0, "0", "100.0000001", SenderVolumeExceeded},
+		{"token", testToken, "1000000", 0, "0", "0", nil},
+		{"token too large", testToken, "1000001", 0, "0", "0", TransferAmountTooLarge},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			const sender = "GD4N5QYZOOFXLRX2POOPOJYB2JBVJFAPPJ3UMBXTQO7TULEYHPKYF4BY"
+			const depositTime = 1656000000
+			err := limits.check(
+				limitsKey(testCase.asset),
+				sender,
+				testCase.amount,
+				depositTime,
+				func(filter store.DepositVolumeFilter) (store.DepositVolume, error) {
+					assert.Equal(t, int64(depositTime-3600), filter.Since)
+					// Deposits outside of the transfer size limits are
+					// never counted
+					assert.Equal(t, expectedRanges, filter.Ranges)
+					switch {
+					case filter.Asset == "":
+						assert.Empty(t, filter.Sender)
+						return store.DepositVolume{Count: testCase.count}, nil
+					case filter.Sender == "":
+						return store.DepositVolume{Amount: testCase.assetVolume}, nil
+					default:
+						assert.Equal(t, sender, filter.Sender)
+						return store.DepositVolume{Amount: testCase.senderVolume}, nil
+					}
+				},
+			)
+			assert.Equal(t, testCase.expectedErr, err)
+		})
+	}
+}
+
+func TestTransferLimitsCheckGlobalVolume(t *testing.T) {
+	limits, err := NewTransferLimits(TransferLimitsConfig{
+		Window:    "1h",
+		MaxVolume: "1000",
+		Assets: []TransferLimitConfigEntry{
+			{Asset: testUSDC, MaxAmount: "100", Value: "2"},
+			{Asset: testToken, Value: "0.000001"},
+		},
+	})
+	require.NoError(t, err)
+	expectedValues := map[string]string{
+		testUSDC: "2.000000000000000000",
+		"0x5fbdb2315678afecb367f032d93f642f64180aa3": "0.000001000000000000",
+	}
+
+	for _, testCase := range []struct {
+		name        string
+		asset       string
+		amount      string
+		volume      string
+		expectedErr error
+	}{
+		{"within limit", testUSDC, "50", "900", nil},
+		{"exceeded", testUSDC, "50.0000001", "900", GlobalVolumeExceeded},
+		{"token value", testToken, "100000000", "900", nil},
+		{"token exceeded", testToken, "100000001", "900", GlobalVolumeExceeded},
+		{"too large", testUSDC, "100.0000001", "0", TransferAmountTooLarge},
+		{"asset without value", "native", "1", "0", AssetValueMissing},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := limits.check(
+				limitsKey(testCase.asset),
+				"GD4N5QYZOOFXLRX2POOPOJYB2JBVJFAPPJ3UMBXTQO7TULEYHPKYF4BY",
+				testCase.amount,
+				1656000000,
+				func(filter store.DepositVolumeFilter) (store.DepositVolume, error) {
+					assert.Empty(t, filter.Asset)
+					assert.Empty(t, filter.Sender)
+					assert.Equal(t, expectedValues, filter.Values)
+					return store.DepositVolume{Amount: testCase.volume}, nil
+				},
+			)
+			assert.Equal(t, testCase.expectedErr, err)
+		})
+	}
+}
+
+// TestTransferLimitsCountRejectedDeposits checks that deposits rejected by a
+// volume cap keep counting towards the cap like the store aggregates do
+func TestTransferLimitsCountRejectedDeposits(t *testing.T) {
+	limits, err := NewTransferLimits(TransferLimitsConfig{
+		Window: "1h",
+		Assets: []TransferLimitConfigEntry{
+			{Asset: testUSDC, MaxAmount: "100", MaxVolume: "150"},
+		},
+	})
+	require.NoError(t, err)
+
+	var deposits []*big.Rat
+	deposit := func(amount string) error {
+		err := limits.check(
+			testUSDC,
+			"GD4N5QYZOOFXLRX2POOPOJYB2JBVJFAPPJ3UMBXTQO7TULEYHPKYF4BY",
+			amount,
+			1656000000,
+			func(filter store.DepositVolumeFilter) (store.DepositVolume, error) {
+				total := new(big.Rat)
+				for _, previous := range deposits {
+					total.Add(total, previous)
+				}
+				return store.DepositVolume{
+					Count:  int64(len(deposits)),
+					Amount: total.FloatString(7),
+				}, nil
+			},
+		)
+		value, _ := new(big.Rat).SetString(amount)
+		deposits = append(deposits, value)
+		return err
+	}
+
+	assert.NoError(t, deposit("100"))
+	assert.Equal(t, AssetVolumeExceeded, deposit("100"))
+	// the rejected deposit is counted so the remaining volume is exhausted
+	assert.Equal(t, AssetVolumeExceeded, deposit("10"))
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stellar/starbridge/backend"
+	"github.com/stellar/starbridge/controllers"
+)
+
+// AddAssetMapping adds the given asset mapping to all validators. The
+// mapping applies to deposits made at or after the activation time.
+func (b BridgeClient) AddAssetMapping(
+	entry backend.AssetMappingConfigEntry,
+	activationTime time.Time,
+) ([]controllers.AssetMappingResponse, error) {
+	postData := url.Values{
+		"stellar_asset":       {entry.StellarAsset},
+		"ethereum_token":      {entry.EthereumToken},
+		"stellar_to_ethereum": {entry.StellarToEthereum},
+		"flat_fee":            {entry.FlatFee},
+		"fee_basis_points":    {strconv.FormatUint(uint64(entry.FeeBasisPoints), 10)},
+		"activation_time":     {strconv.FormatInt(activationTime.Unix(), 10)},
+	}
+	responses := make([]controllers.AssetMappingResponse, len(b.ValidatorAdminURLs))
+	for i, adminURL := range b.ValidatorAdminURLs {
+		requestURL := strings.TrimSuffix(adminURL, "/") + "/asset_mappings"
+		if err := b.postForm(requestURL, postData, &responses[i]); err != nil {
+			return responses[:i], err
+		}
+	}
+	return responses, nil
+}
+
+// DisableAssetMapping disables the asset mapping with the given id on all
+// validators. Validators assign ids independently so the caller must provide
+// the id used by each validator, in the order of ValidatorAdminURLs.
+func (b BridgeClient) DisableAssetMapping(
+	ids []int64,
+	deactivationTime time.Time,
+) ([]controllers.AssetMappingResponse, error) {
+	if len(ids) != len(b.ValidatorAdminURLs) {
+		return nil, fmt.Errorf("expected %d asset mapping ids", len(b.ValidatorAdminURLs))
+	}
+	responses := make([]controllers.AssetMappingResponse, len(b.ValidatorAdminURLs))
+	postData := url.Values{
+		"deactivation_time": {strconv.FormatInt(deactivationTime.Unix(), 10)},
+	}
+	for i, adminURL := range b.ValidatorAdminURLs {
+		requestURL := strings.TrimSuffix(adminURL, "/") +
+			"/asset_mappings/" + strconv.FormatInt(ids[i], 10) + "/disable"
+		if err := b.postForm(requestURL, postData, &responses[i]); err != nil {
+			return responses[:i], err
+		}
+	}
+	return responses, nil
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"math/big"
 	"net/http"
 	"net/url"
@@ -16,6 +15,7 @@ import (
 	"github.com/ethereum/go-ethereum"
 
 	"github.com/stellar/starbridge/controllers"
+	starbridgeethereum "github.com/stellar/starbridge/ethereum"
 
 	"github.com/stellar/go/support/render/problem"
 
@@ -30,6 +30,7 @@ import (
 	"github.com/stellar/go/protocols/horizon"
 	"github.com/stellar/go/strkey"
 	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
 	"github.com/stellar/starbridge/solidity-go"
 )
 
@@ -44,6 +45,17 @@ type BridgeClient struct {
 	EthereumBridgeConfigVersion uint32
 	StellarPrivateKey           string
 	EthereumPrivateKey          string
+
+	// ValidatorAdminURLs are the urls of the validator admin servers
+	// which are used to sign governance requests
+	ValidatorAdminURLs []string
+
+	// ValidatorTimeout is the time after which a validator which did not
+	// return a signature is given up on, DefaultValidatorTimeout if not set
+	ValidatorTimeout time.Duration
+	// OnSignatureReport is called with the outcome of every signature
+	// collection, including failed ones
+	OnSignatureReport func(SignatureReport)
 }
 
 func (b BridgeClient) SubmitStellarDeposit(amount, asset, ethereumRecipient string) (*horizon.Transaction, error) {
@@ -155,10 +167,12 @@ func (b BridgeClient) createEthClient(gasPrice *big.Int) (*ethclient.Client, *so
 func (b BridgeClient) SubmitEthereumWithdrawal(
 	ctx context.Context,
 	stellarTxHash string,
+	operationIndex uint,
 	gasPrice *big.Int,
 ) (*types.Receipt, error) {
 	postData := url.Values{
 		"transaction_hash": {stellarTxHash},
+		"operation_index":  {strconv.FormatUint(uint64(operationIndex), 10)},
 	}
 	return b.withdrawEthereum(ctx, "stellar/withdraw/ethereum", postData, gasPrice)
 }
@@ -182,77 +196,86 @@ func (b BridgeClient) withdrawEthereum(
 	postData url.Values,
 	gasPrice *big.Int,
 ) (*types.Receipt, error) {
-	responses, err := b.ethereumSignatures(uri, postData)
+	ethRPCClient, bridge, opts, err := b.createEthClient(gasPrice)
 	if err != nil {
 		return nil, err
 	}
 
-	ethRPCClient, bridge, opts, err := b.createEthClient(gasPrice)
+	bridgeAddress := common.HexToAddress(b.EthereumBridgeAddress)
+	caller, err := solidity.NewBridgeCaller(bridgeAddress, ethRPCClient)
 	if err != nil {
 		return nil, err
 	}
 
-	caller, err := solidity.NewBridgeCaller(common.HexToAddress(b.EthereumBridgeAddress), ethRPCClient)
+	validatorToIndex, err := signerIndexes(caller)
 	if err != nil {
 		return nil, err
 	}
 
-	validatorToIndex := map[common.Address]uint8{}
-	for i := 0; i < len(b.ValidatorURLs); i++ {
-		address, err := caller.Signers(nil, big.NewInt(int64(i)))
-		if err != nil {
-			return nil, err
-		}
-		validatorToIndex[address] = uint8(i)
+	responses, err := b.ethereumSignatures(ctx, caller, validatorToIndex, opts.From, uri, postData)
+	if err != nil {
+		return nil, err
 	}
 
-	sort.Slice(responses, func(i, j int) bool {
-		address := common.HexToAddress(responses[i].Address)
-		index := validatorToIndex[address]
-
-		otherAddress := common.HexToAddress(responses[j].Address)
-		otherIndex := validatorToIndex[otherAddress]
-
-		return index < otherIndex
-	})
-	signatures := make([][]byte, len(responses))
-	indexes := make([]uint8, len(responses))
+	addresses := make([]string, len(responses))
+	hexSignatures := make([]string, len(responses))
 	for i, response := range responses {
-		signatures[i] = common.Hex2Bytes(response.Signature)
-		indexes[i] = validatorToIndex[common.HexToAddress(response.Address)]
+		addresses[i] = response.Address
+		hexSignatures[i] = response.Signature
+	}
+	signatures, indexes, err := sortedSignatures(validatorToIndex, addresses, hexSignatures)
+	if err != nil {
+		return nil, err
 	}
 
+	// amount was validated when the signatures were verified
 	token := common.HexToAddress(responses[0].Token)
-	amount, ok := new(big.Int).SetString(responses[0].Amount, 10)
-	if !ok {
-		return nil, fmt.Errorf("invalid amount in response %v", responses[0].Amount)
-	}
-	var tx *types.Transaction
+	amount, _ := new(big.Int).SetString(responses[0].Amount, 10)
+	var method string
+	var request interface{}
 	if token == (common.Address{}) {
-		tx, err = bridge.WithdrawETH(
-			opts,
-			solidity.WithdrawETHRequest{
-				Id:         common.HexToHash(responses[0].DepositID),
-				Expiration: big.NewInt(responses[0].Expiration),
-				Recipient:  opts.From,
-				Amount:     amount,
-			},
-			signatures,
-			indexes,
-		)
+		method = "withdrawETH"
+		request = solidity.WithdrawETHRequest{
+			Id:         common.HexToHash(responses[0].DepositID),
+			Expiration: big.NewInt(responses[0].Expiration),
+			Recipient:  opts.From,
+			Amount:     amount,
+		}
 	} else {
-		tx, err = bridge.WithdrawERC20(
-			opts,
-			solidity.WithdrawERC20Request{
-				Id:         common.HexToHash(responses[0].DepositID),
-				Expiration: big.NewInt(responses[0].Expiration),
-				Recipient:  opts.From,
-				Amount:     amount,
-				Token:      token,
-			},
-			signatures,
-			indexes,
-		)
+		method = "withdrawERC20"
+		request = solidity.WithdrawERC20Request{
+			Id:         common.HexToHash(responses[0].DepositID),
+			Expiration: big.NewInt(responses[0].Expiration),
+			Recipient:  opts.From,
+			Amount:     amount,
+			Token:      token,
+		}
+	}
+
+	// Dry run the withdrawal so that it is not submitted, and gas is not
+	// spent, if the bridge contract would revert it
+	bridgeABI, err := solidity.BridgeMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	data, err := bridgeABI.Pack(method, request, signatures, indexes)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = ethRPCClient.CallContract(ctx, ethereum.CallMsg{
+		From: opts.From,
+		To:   &bridgeAddress,
+		Data: data,
+	}, nil); err != nil {
+		return nil, fmt.Errorf("%s would fail: %w", method, err)
+	}
+
+	var tx *types.Transaction
+	switch request := request.(type) {
+	case solidity.WithdrawETHRequest:
+		tx, err = bridge.WithdrawETH(opts, request, signatures, indexes)
+	case solidity.WithdrawERC20Request:
+		tx, err = bridge.WithdrawERC20(opts, request, signatures, indexes)
 	}
 	if err != nil {
 		return nil, err
@@ -261,32 +284,154 @@ func (b BridgeClient) withdrawEthereum(
 	return submitEthereumTx(ctx, ethRPCClient, tx)
 }
 
-func (b BridgeClient) ethereumSignatures(uri string, postData url.Values) ([]controllers.EthereumSignatureResponse, error) {
-	responses := make([]controllers.EthereumSignatureResponse, len(b.ValidatorURLs))
-	for i := 0; i < len(b.ValidatorURLs); i++ {
-		for {
-			requestURL := strings.TrimSuffix(b.ValidatorURLs[i], "/") + "/" + strings.TrimPrefix(uri, "/")
-			resp, err := http.PostForm(requestURL, postData)
-			if err != nil {
-				return nil, err
-			}
-			switch resp.StatusCode {
-			case http.StatusAccepted:
-				time.Sleep(time.Second)
-				continue
-			case http.StatusOK:
-				if err = json.NewDecoder(resp.Body).Decode(&responses[i]); err != nil {
-					return nil, err
-				}
-			default:
-				return nil, b.parseProblem(resp)
+// maxSigners is the maximum number of signers of the bridge smart contract
+const maxSigners = 255
+
+// signerIndexes maps the signers configured in the bridge smart contract
+// to their index. The contract does not expose the number of signers so
+// signers are read until the contract reverts.
+func signerIndexes(caller *solidity.BridgeCaller) (map[common.Address]uint8, error) {
+	validatorToIndex := map[common.Address]uint8{}
+	for i := 0; i < maxSigners; i++ {
+		address, err := caller.Signers(nil, big.NewInt(int64(i)))
+		if err != nil {
+			if i > 0 {
+				break
 			}
-			break
+			return nil, err
+		}
+		validatorToIndex[address] = uint8(i)
+	}
+	return validatorToIndex, nil
+}
+
+// sortedSignatures returns the given hex encoded signatures and the indexes
+// of their signers sorted by index, as required by the bridge contract. An
+// error is returned if an address is not a bridge signer.
+func sortedSignatures(
+	validatorToIndex map[common.Address]uint8,
+	addresses []string,
+	hexSignatures []string,
+) ([][]byte, []uint8, error) {
+	type indexedSignature struct {
+		index     uint8
+		signature []byte
+	}
+	sorted := make([]indexedSignature, len(addresses))
+	for i, address := range addresses {
+		index, ok := validatorToIndex[common.HexToAddress(address)]
+		if !ok {
+			return nil, nil, fmt.Errorf("%s is not a bridge signer", address)
 		}
+		sorted[i] = indexedSignature{index: index, signature: common.FromHex(hexSignatures[i])}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].index < sorted[j].index
+	})
+
+	signatures := make([][]byte, len(sorted))
+	indexes := make([]uint8, len(sorted))
+	for i, sig := range sorted {
+		signatures[i] = sig.signature
+		indexes[i] = sig.index
+	}
+	return signatures, indexes, nil
+}
+
+// ethereumSignatures collects signatures from validators which are bridge
+// signers until the minimum threshold of the bridge contract is met. Each
+// signature is verified against the withdrawal request recomputed from the
+// response and all validators must sign the same request.
+func (b BridgeClient) ethereumSignatures(
+	ctx context.Context,
+	caller *solidity.BridgeCaller,
+	validatorToIndex map[common.Address]uint8,
+	recipient common.Address,
+	uri string,
+	postData url.Values,
+) ([]controllers.EthereumSignatureResponse, error) {
+	callOpts := &bind.CallOpts{Context: ctx}
+	threshold, err := caller.MinThreshold(callOpts)
+	if err != nil {
+		return nil, err
+	}
+	version, err := caller.Version(callOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []controllers.EthereumSignatureResponse
+	signed := map[common.Address]bool{}
+	_, _, err = b.collectSignatures(ctx, uri, postData, int(threshold), func(_ int, body []byte) (int, error) {
+		var response controllers.EthereumSignatureResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return 0, err
+		}
+		if len(responses) > 0 && !sameWithdrawalRequest(responses[0], response) {
+			return 0, fmt.Errorf("validator signed a different withdrawal request")
+		}
+		address := common.HexToAddress(response.Address)
+		if _, ok := validatorToIndex[address]; !ok {
+			return 0, fmt.Errorf("%s is not a bridge signer", response.Address)
+		}
+		if signed[address] {
+			return 0, fmt.Errorf("duplicate signature of %s", response.Address)
+		}
+		if err := verifyEthereumSignature(version, recipient, response); err != nil {
+			return 0, err
+		}
+		signed[address] = true
+		responses = append(responses, response)
+		return 1, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return responses, nil
 }
 
+// sameWithdrawalRequest returns true if both responses are signatures of
+// the same withdrawal request
+func sameWithdrawalRequest(a, b controllers.EthereumSignatureResponse) bool {
+	return common.HexToHash(a.DepositID) == common.HexToHash(b.DepositID) &&
+		common.HexToAddress(a.Token) == common.HexToAddress(b.Token) &&
+		a.Amount == b.Amount &&
+		a.Expiration == b.Expiration
+}
+
+// verifyEthereumSignature checks that the signature of the response was
+// created by the validator at the response address for the withdrawal
+// request described by the response, the way the bridge contract verifies it
+func verifyEthereumSignature(
+	version *big.Int,
+	recipient common.Address,
+	response controllers.EthereumSignatureResponse,
+) error {
+	amount, ok := new(big.Int).SetString(response.Amount, 10)
+	if !ok {
+		return fmt.Errorf("invalid amount in response %v", response.Amount)
+	}
+	hash, err := starbridgeethereum.WithdrawalHash(
+		version,
+		common.HexToHash(response.DepositID),
+		response.Expiration,
+		recipient,
+		common.HexToAddress(response.Token),
+		amount,
+	)
+	if err != nil {
+		return err
+	}
+	signer, err := starbridgeethereum.RecoverSigner(hash, common.FromHex(response.Signature))
+	if err != nil {
+		return err
+	}
+	if signer != common.HexToAddress(response.Address) {
+		return fmt.Errorf("signature of %s was created by %s", response.Address, signer.Hex())
+	}
+	return nil
+}
+
 func (b BridgeClient) parseProblem(resp *http.Response) error {
 	var p problem.P
 	if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
@@ -297,9 +442,11 @@ func (b BridgeClient) parseProblem(resp *http.Response) error {
 
 func (b BridgeClient) SubmitStellarRefund(
 	stellarTxHash string,
+	operationIndex uint,
 ) (*horizon.Transaction, error) {
 	postData := url.Values{
 		"transaction_hash": {stellarTxHash},
+		"operation_index":  {strconv.FormatUint(uint64(operationIndex), 10)},
 	}
 	tx, err := b.stellarTx("stellar/refund", postData)
 	if err != nil {
@@ -342,64 +489,155 @@ func (b BridgeClient) submitStellarTx(horizonClient *horizonclient.Client, tx *t
 	return &result, nil
 }
 
+// stellarTx collects signatures of the bridge account signers until their
+// weight meets the medium threshold of the bridge account, which is
+// required by the payment and claimable balance operations signed by
+// validators. Validators must sign the same transaction, responses with a
+// different transaction than the first valid response or with signatures
+// which do not verify are rejected.
 func (b BridgeClient) stellarTx(uri string, postData url.Values) (*txnbuild.Transaction, error) {
-	responses := make([]string, len(b.ValidatorURLs))
-	for i := 0; i < len(b.ValidatorURLs); i++ {
-		for {
-			requestURL := strings.TrimSuffix(b.ValidatorURLs[i], "/") + "/" + strings.TrimPrefix(uri, "/")
-			resp, err := http.PostForm(requestURL, postData)
-			if err != nil {
-				return nil, err
+	signers, threshold, err := b.stellarBridgeSigners()
+	if err != nil {
+		return nil, err
+	}
+
+	var mainTx *txnbuild.Transaction
+	var mainBody string
+	var signatures []stellarSignature
+	signed := map[string]bool{}
+	_, _, err = b.collectSignatures(context.Background(), uri, postData, threshold, func(_ int, body []byte) (int, error) {
+		gtx, err := txnbuild.TransactionFromXDR(string(body))
+		if err != nil {
+			return 0, err
+		}
+		tx, ok := gtx.Transaction()
+		if !ok {
+			return 0, fmt.Errorf("invalid transaction type")
+		}
+		unsigned, err := tx.ClearSignatures()
+		if err != nil {
+			return 0, err
+		}
+		unsignedBody, err := unsigned.Base64()
+		if err != nil {
+			return 0, err
+		}
+		if mainTx != nil && unsignedBody != mainBody {
+			return 0, fmt.Errorf("transaction differs from the transaction signed by other validators")
+		}
+		hash, err := tx.Hash(b.NetworkPassphrase)
+		if err != nil {
+			return 0, err
+		}
+
+		weight := 0
+		for _, sig := range tx.Signatures() {
+			signer, ok := verifyStellarSignature(signers[sig.Hint], hash, sig)
+			if !ok {
+				return 0, fmt.Errorf("invalid signature with hint %x", sig.Hint)
 			}
-			switch resp.StatusCode {
-			case http.StatusAccepted:
-				time.Sleep(time.Second)
+			if signed[signer.Key] {
 				continue
-			case http.StatusOK:
-				if body, err := ioutil.ReadAll(resp.Body); err != nil {
-					return nil, err
-				} else {
-					responses[i] = string(body)
-				}
-			default:
-				return nil, b.parseProblem(resp)
 			}
-			break
+			signed[signer.Key] = true
+			weight += int(signer.Weight)
+			signatures = append(signatures, stellarSignature{signature: sig, weight: int(signer.Weight)})
 		}
+		if weight == 0 {
+			return 0, fmt.Errorf("transaction is not signed by a bridge signer")
+		}
+
+		if mainTx == nil {
+			mainTx, mainBody = unsigned, unsignedBody
+		}
+		return weight, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	gtx, err := txnbuild.TransactionFromXDR(responses[0])
+	mainTx, err = mainTx.AddSignatureDecorated(minimalStellarSignatures(signatures, threshold)...)
 	if err != nil {
 		return nil, err
 	}
 
-	mainTx, ok := gtx.Transaction()
-	if !ok {
-		return nil, fmt.Errorf("invalid transaction type")
+	// ...and add client signature if it's tx source. Claimable balance
+	// withdrawals are sourced from the bridge account so the validator
+	// signatures are sufficient.
+	clientKey := keypair.MustParseFull(b.StellarPrivateKey)
+	if mainTx.SourceAccount().AccountID != clientKey.Address() {
+		return mainTx, nil
 	}
+	return mainTx.Sign(b.NetworkPassphrase, clientKey)
+}
 
-	// Add as many sigs as needed and not a single more
-	for i := 1; i < len(b.ValidatorURLs)/2+1; i++ {
-		gtx, err := txnbuild.TransactionFromXDR(responses[i])
-		if err != nil {
-			return nil, err
-		}
-		tx, ok := gtx.Transaction()
-		if !ok {
-			return nil, fmt.Errorf("invalid transaction type")
-		}
+type stellarSignature struct {
+	signature xdr.DecoratedSignature
+	weight    int
+}
 
-		sig := tx.Signatures()
+// stellarBridgeSigners returns the ed25519 signers of the bridge account by
+// signature hint and the medium threshold of the bridge account
+func (b BridgeClient) stellarBridgeSigners() (map[xdr.SignatureHint][]horizon.Signer, int, error) {
+	horizonClient := &horizonclient.Client{
+		HorizonURL: b.HorizonURL,
+	}
+	bridgeAccount, err := horizonClient.AccountDetail(horizonclient.AccountRequest{
+		AccountID: b.StellarBridgeAccount,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
 
-		mainTx, err = mainTx.AddSignatureDecorated(sig...)
+	signers := map[xdr.SignatureHint][]horizon.Signer{}
+	for _, signer := range bridgeAccount.Signers {
+		kp, err := keypair.ParseAddress(signer.Key)
+		if err != nil || signer.Weight == 0 {
+			// only ed25519 signers can be validators
+			continue
+		}
+		hint := xdr.SignatureHint(kp.Hint())
+		signers[hint] = append(signers[hint], signer)
+	}
+	threshold := int(bridgeAccount.Thresholds.MedThreshold)
+	if threshold == 0 {
+		threshold = 1
+	}
+	return signers, threshold, nil
+}
+
+// verifyStellarSignature returns the signer, among signers sharing the hint
+// of the signature, which created the signature of the transaction hash
+func verifyStellarSignature(signers []horizon.Signer, hash [32]byte, sig xdr.DecoratedSignature) (horizon.Signer, bool) {
+	for _, signer := range signers {
+		kp, err := keypair.ParseAddress(signer.Key)
 		if err != nil {
-			return nil, err
+			continue
+		}
+		if kp.Verify(hash[:], sig.Signature) == nil {
+			return signer, true
 		}
 	}
+	return horizon.Signer{}, false
+}
 
-	// ...and add client signature (because it's tx source)
-	clientKey := keypair.MustParseFull(b.StellarPrivateKey)
-	return mainTx.Sign(b.NetworkPassphrase, clientKey)
+// minimalStellarSignatures returns the smallest set of signatures meeting
+// the threshold. Signatures are added by decreasing weight so no signature
+// can be left out once the threshold is met.
+func minimalStellarSignatures(signatures []stellarSignature, threshold int) []xdr.DecoratedSignature {
+	sort.SliceStable(signatures, func(i, j int) bool {
+		return signatures[i].weight > signatures[j].weight
+	})
+	var result []xdr.DecoratedSignature
+	weight := 0
+	for _, sig := range signatures {
+		if weight >= threshold {
+			break
+		}
+		result = append(result, sig.signature)
+		weight += sig.weight
+	}
+	return result
 }
 
 func submitEthereumTx(ctx context.Context, ethRPCClient *ethclient.Client, tx *types.Transaction) (*types.Receipt, error) {
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyStellarSignature(t *testing.T) {
+	validator := keypair.MustRandom()
+	other := keypair.MustRandom()
+	// hash(x) signers are skipped
+	hashX, err := strkey.Encode(strkey.VersionByteHashX, make([]byte, 32))
+	require.NoError(t, err)
+	signers := []horizon.Signer{
+		{Key: hashX, Weight: 1},
+		{Key: other.Address(), Weight: 1},
+		{Key: validator.Address(), Weight: 2},
+	}
+	hash := sha256.Sum256([]byte("transaction"))
+
+	sig, err := validator.SignDecorated(hash[:])
+	require.NoError(t, err)
+	signer, ok := verifyStellarSignature(signers, hash, sig)
+	assert.True(t, ok)
+	assert.Equal(t, signers[2], signer)
+
+	// the signature does not match the transaction
+	otherHash := hash
+	otherHash[0]++
+	_, ok = verifyStellarSignature(signers, otherHash, sig)
+	assert.False(t, ok)
+
+	// the signature was created by a key which is not a signer
+	_, ok = verifyStellarSignature(signers[:2], hash, sig)
+	assert.False(t, ok)
+}
+
+func TestMinimalStellarSignatures(t *testing.T) {
+	signature := func(weight int) stellarSignature {
+		return stellarSignature{
+			signature: xdr.DecoratedSignature{Signature: xdr.Signature{byte(weight)}},
+			weight:    weight,
+		}
+	}
+	for _, testCase := range []struct {
+		name      string
+		weights   []int
+		threshold int
+		expected  []int
+	}{
+		{
+			name:      "heaviest signatures first",
+			weights:   []int{1, 3, 2},
+			threshold: 4,
+			expected:  []int{3, 2},
+		},
+		{
+			name:      "single signature",
+			weights:   []int{1, 3, 2},
+			threshold: 3,
+			expected:  []int{3},
+		},
+		{
+			name:      "all signatures",
+			weights:   []int{1, 1, 1},
+			threshold: 3,
+			expected:  []int{1, 1, 1},
+		},
+		{
+			name:      "threshold not met",
+			weights:   []int{1, 1},
+			threshold: 3,
+			expected:  []int{1, 1},
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			var signatures []stellarSignature
+			for _, weight := range testCase.weights {
+				signatures = append(signatures, signature(weight))
+			}
+			var expected []xdr.DecoratedSignature
+			for _, weight := range testCase.expected {
+				expected = append(expected, signature(weight).signature)
+			}
+			assert.Equal(t, expected, minimalStellarSignatures(signatures, testCase.threshold))
+		})
+	}
+}
@@ -0,0 +1,363 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/txnbuild"
+
+	"github.com/stellar/starbridge/controllers"
+	"github.com/stellar/starbridge/solidity-go"
+)
+
+// SubmitSetPaused collects signatures for the given setPaused request from
+// the admin servers of all validators and submits the setPaused transaction
+// to the bridge smart contract.
+func (b BridgeClient) SubmitSetPaused(
+	ctx context.Context,
+	value uint8,
+	nonce *big.Int,
+	expiration int64,
+	gasPrice *big.Int,
+) (*types.Receipt, error) {
+	postData := url.Values{
+		"value":      {strconv.FormatUint(uint64(value), 10)},
+		"nonce":      {nonce.String()},
+		"expiration": {strconv.FormatInt(expiration, 10)},
+	}
+	responses := make([]controllers.SetPausedSignatureResponse, len(b.ValidatorAdminURLs))
+	for i, adminURL := range b.ValidatorAdminURLs {
+		requestURL := strings.TrimSuffix(adminURL, "/") + "/ethereum/set_paused"
+		if err := b.postForm(requestURL, postData, &responses[i]); err != nil {
+			return nil, err
+		}
+		if responses[i].Value != value ||
+			responses[i].Nonce != nonce.String() ||
+			responses[i].Expiration != expiration {
+			return nil, fmt.Errorf("validator %s signed a different setPaused request", adminURL)
+		}
+	}
+
+	ethRPCClient, bridge, opts, err := b.createEthClient(gasPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	caller, err := solidity.NewBridgeCaller(common.HexToAddress(b.EthereumBridgeAddress), ethRPCClient)
+	if err != nil {
+		return nil, err
+	}
+
+	validatorToIndex, err := signerIndexes(caller)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]string, len(responses))
+	hexSignatures := make([]string, len(responses))
+	for i, response := range responses {
+		addresses[i] = response.Address
+		hexSignatures[i] = response.Signature
+	}
+	signatures, indexes, err := sortedSignatures(validatorToIndex, addresses, hexSignatures)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := bridge.SetPaused(
+		opts,
+		solidity.SetPausedRequest{
+			Value:      value,
+			Nonce:      nonce,
+			Expiration: big.NewInt(expiration),
+		},
+		signatures,
+		indexes,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return submitEthereumTx(ctx, ethRPCClient, tx)
+}
+
+func (b BridgeClient) postForm(requestURL string, postData url.Values, response interface{}) error {
+	resp, err := http.PostForm(requestURL, postData)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return b.parseProblem(resp)
+	}
+	return json.NewDecoder(resp.Body).Decode(response)
+}
+
+// SubmitUpdateSigners collects signatures for the given validator set from
+// the admin servers of all current validators and submits the updateSigners
+// transaction to the bridge smart contract.
+func (b BridgeClient) SubmitUpdateSigners(
+	ctx context.Context,
+	signers []common.Address,
+	minThreshold uint8,
+	gasPrice *big.Int,
+) (*types.Receipt, error) {
+	signerList := make([]string, len(signers))
+	for i, signer := range signers {
+		signerList[i] = signer.String()
+	}
+	postData := url.Values{
+		"signers":       {strings.Join(signerList, ",")},
+		"min_threshold": {strconv.FormatUint(uint64(minThreshold), 10)},
+	}
+
+	ethRPCClient, bridge, opts, err := b.createEthClient(gasPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	caller, err := solidity.NewBridgeCaller(common.HexToAddress(b.EthereumBridgeAddress), ethRPCClient)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := caller.Version(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]controllers.UpdateSignersSignatureResponse, len(b.ValidatorAdminURLs))
+	for i, adminURL := range b.ValidatorAdminURLs {
+		requestURL := strings.TrimSuffix(adminURL, "/") + "/ethereum/update_signers"
+		if err = b.postForm(requestURL, postData, &responses[i]); err != nil {
+			return nil, err
+		}
+		if responses[i].Version != version.Uint64() {
+			return nil, fmt.Errorf(
+				"validator %s signed version %d but the bridge is at version %d",
+				adminURL, responses[i].Version, version.Uint64(),
+			)
+		}
+		if responses[i].MinThreshold != minThreshold ||
+			strings.Join(responses[i].Signers, ",") != strings.Join(signerList, ",") {
+			return nil, fmt.Errorf("validator %s signed a different updateSigners request", adminURL)
+		}
+	}
+
+	validatorToIndex, err := signerIndexes(caller)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]string, len(responses))
+	hexSignatures := make([]string, len(responses))
+	for i, response := range responses {
+		addresses[i] = response.Address
+		hexSignatures[i] = response.Signature
+	}
+	signatures, indexes, err := sortedSignatures(validatorToIndex, addresses, hexSignatures)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := bridge.UpdateSigners(opts, signers, minThreshold, signatures, indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	return submitEthereumTx(ctx, ethRPCClient, tx)
+}
+
+// SubmitStellarSetSigners collects signatures from the admin servers of the
+// current validators for a transaction replacing the signers of the bridge
+// account and submits it to the Stellar network. The sequence number of the
+// transaction is pinned in the request so every validator signs the same
+// transaction. Signatures are collected until the high threshold of the
+// bridge account is met.
+func (b BridgeClient) SubmitStellarSetSigners(
+	signers []string,
+	minThreshold uint8,
+	expiration int64,
+) (*horizon.Transaction, error) {
+	if len(b.ValidatorAdminURLs) == 0 {
+		return nil, fmt.Errorf("no validator admin urls configured")
+	}
+	horizonClient := &horizonclient.Client{
+		HorizonURL: b.HorizonURL,
+	}
+	bridgeAccount, err := horizonClient.AccountDetail(horizonclient.AccountRequest{
+		AccountID: b.StellarBridgeAccount,
+	})
+	if err != nil {
+		return nil, err
+	}
+	postData := url.Values{
+		"signers":       {strings.Join(signers, ",")},
+		"min_threshold": {strconv.FormatUint(uint64(minThreshold), 10)},
+		"sequence":      {strconv.FormatInt(bridgeAccount.Sequence+1, 10)},
+		"expiration":    {strconv.FormatInt(expiration, 10)},
+	}
+
+	var mainTx *txnbuild.Transaction
+	var mainHash [32]byte
+	var failures []string
+	weight := 0
+	threshold := int(bridgeAccount.Thresholds.HighThreshold)
+	for _, adminURL := range b.ValidatorAdminURLs {
+		if mainTx != nil && weight >= threshold {
+			break
+		}
+		var response controllers.StellarSetSignersResponse
+		requestURL := strings.TrimSuffix(adminURL, "/") + "/stellar/update_signers"
+		if err := b.postForm(requestURL, postData, &response); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", adminURL, err))
+			continue
+		}
+
+		gtx, err := txnbuild.TransactionFromXDR(response.Envelope)
+		if err != nil {
+			return nil, err
+		}
+		tx, ok := gtx.Transaction()
+		if !ok {
+			return nil, fmt.Errorf("invalid transaction type")
+		}
+		hash, err := tx.Hash(b.NetworkPassphrase)
+		if err != nil {
+			return nil, err
+		}
+
+		if mainTx == nil {
+			mainTx, mainHash = tx, hash
+		} else if hash != mainHash {
+			return nil, fmt.Errorf("validator %s signed a different transaction", adminURL)
+		} else {
+			mainTx, err = mainTx.AddSignatureDecorated(tx.Signatures()...)
+			if err != nil {
+				return nil, err
+			}
+		}
+		weight += signerWeight(bridgeAccount.Signers, response.Address)
+	}
+	if mainTx == nil || weight < threshold {
+		return nil, fmt.Errorf(
+			"collected signatures of weight %d, %d required: %s",
+			weight, threshold, strings.Join(failures, "; "),
+		)
+	}
+
+	return b.submitStellarTx(horizonClient, mainTx)
+}
+
+// signerWeight returns the weight of the given key among the signers of an
+// account
+func signerWeight(signers []horizon.Signer, key string) int {
+	for _, signer := range signers {
+		if signer.Key == key {
+			return int(signer.Weight)
+		}
+	}
+	return 0
+}
+
+// SubmitRegisterStellarAsset collects signatures for the given
+// registerStellarAsset request from the admin servers of all validators and
+// submits the transaction to the bridge smart contract. It returns the
+// address of the ERC20 token which was deployed by the bridge.
+func (b BridgeClient) SubmitRegisterStellarAsset(
+	ctx context.Context,
+	asset string,
+	request solidity.RegisterStellarAssetRequest,
+	gasPrice *big.Int,
+) (common.Address, *types.Receipt, error) {
+	postData := url.Values{
+		"asset":    {asset},
+		"decimals": {strconv.FormatUint(uint64(request.Decimals), 10)},
+		"name":     {request.Name},
+		"symbol":   {request.Symbol},
+	}
+	responses := make([]controllers.RegisterStellarAssetSignatureResponse, len(b.ValidatorAdminURLs))
+	for i, adminURL := range b.ValidatorAdminURLs {
+		requestURL := strings.TrimSuffix(adminURL, "/") + "/ethereum/register_stellar_asset"
+		if err := b.postForm(requestURL, postData, &responses[i]); err != nil {
+			return common.Address{}, nil, err
+		}
+		if responses[i].Decimals != request.Decimals ||
+			responses[i].Name != request.Name ||
+			responses[i].Symbol != request.Symbol {
+			return common.Address{}, nil, fmt.Errorf("validator %s signed a different registerStellarAsset request", adminURL)
+		}
+	}
+
+	ethRPCClient, bridge, opts, err := b.createEthClient(gasPrice)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+
+	caller, err := solidity.NewBridgeCaller(common.HexToAddress(b.EthereumBridgeAddress), ethRPCClient)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+
+	validatorToIndex, err := signerIndexes(caller)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+
+	addresses := make([]string, len(responses))
+	hexSignatures := make([]string, len(responses))
+	for i, response := range responses {
+		addresses[i] = response.Address
+		hexSignatures[i] = response.Signature
+	}
+	signatures, indexes, err := sortedSignatures(validatorToIndex, addresses, hexSignatures)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+
+	tx, err := bridge.RegisterStellarAsset(opts, request, signatures, indexes)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+
+	receipt, err := submitEthereumTx(ctx, ethRPCClient, tx)
+	if err != nil {
+		return common.Address{}, receipt, err
+	}
+
+	filterer, err := solidity.NewBridgeFilterer(common.HexToAddress(b.EthereumBridgeAddress), ethRPCClient)
+	if err != nil {
+		return common.Address{}, receipt, err
+	}
+	for _, receiptLog := range receipt.Logs {
+		if receiptLog.Address != common.HexToAddress(b.EthereumBridgeAddress) {
+			continue
+		}
+		event, err := filterer.ParseRegisterStellarAsset(*receiptLog)
+		if err != nil {
+			continue
+		}
+		// validators which know the StellarAsset bytecode report the
+		// expected token address which must match the deployed token
+		for _, response := range responses {
+			if response.Token != "" && common.HexToAddress(response.Token) != event.Asset {
+				return event.Asset, receipt, fmt.Errorf(
+					"deployed token %s does not match expected token %s", event.Asset, response.Token,
+				)
+			}
+		}
+		return event.Asset, receipt, nil
+	}
+	return common.Address{}, receipt, fmt.Errorf("RegisterStellarAsset event not found in transaction %s", tx.Hash())
+}
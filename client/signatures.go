@@ -0,0 +1,228 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stellar/go/support/render/problem"
+)
+
+const (
+	// signatureWait is the time validators are asked to hold signature
+	// requests until the signature is created
+	signatureWait = 5 * time.Second
+	// DefaultValidatorTimeout is the time after which a validator which
+	// did not return a signature is given up on
+	DefaultValidatorTimeout = 30 * time.Second
+)
+
+// ValidatorStatus describes how a validator responded to a signature request
+type ValidatorStatus string
+
+const (
+	// ValidatorSigned validators returned a signature counting towards the
+	// threshold
+	ValidatorSigned ValidatorStatus = "signed"
+	// ValidatorRejected validators returned a signature which does not
+	// count towards the threshold, for example because their key is not a
+	// bridge signer
+	ValidatorRejected ValidatorStatus = "rejected"
+	// ValidatorFailed validators returned an error or could not be reached
+	ValidatorFailed ValidatorStatus = "failed"
+	// ValidatorTimedOut validators did not respond in time
+	ValidatorTimedOut ValidatorStatus = "timed_out"
+	// ValidatorSkipped validators were not waited for because the
+	// threshold was met before they responded
+	ValidatorSkipped ValidatorStatus = "skipped"
+)
+
+// ValidatorResult is the outcome of a signature request to a single validator
+type ValidatorResult struct {
+	URL    string
+	Status ValidatorStatus
+	// Err is set for rejected, failed and timed out validators
+	Err      error
+	Duration time.Duration
+}
+
+// SignatureReport describes the signatures collected for a transfer
+type SignatureReport struct {
+	// Threshold is the weight of signatures required on chain and Weight
+	// the weight of the collected signatures
+	Threshold  int
+	Weight     int
+	Validators []ValidatorResult
+}
+
+// SignatureError is returned when validators did not provide enough
+// signatures to meet the threshold
+type SignatureError struct {
+	Report SignatureReport
+}
+
+func (e *SignatureError) Error() string {
+	var failures []string
+	for _, result := range e.Report.Validators {
+		if result.Err != nil {
+			failures = append(failures, fmt.Sprintf("%s %s: %v", result.URL, result.Status, result.Err))
+		}
+	}
+	return fmt.Sprintf(
+		"collected signatures of weight %d, %d required: %s",
+		e.Report.Weight, e.Report.Threshold, strings.Join(failures, "; "),
+	)
+}
+
+// signatureAcceptor validates the response of the validator with the given
+// index and returns the weight it adds to the collected signatures. It is
+// never called concurrently.
+type signatureAcceptor func(index int, body []byte) (int, error)
+
+type validatorResponse struct {
+	index    int
+	body     []byte
+	err      error
+	duration time.Duration
+}
+
+// collectSignatures requests signatures from all validators concurrently
+// and returns once responses accepted by accept reach the threshold. Slow
+// and failing validators are tolerated as long as the threshold is met. The
+// returned bodies are indexed like ValidatorURLs and are nil for validators
+// which did not sign.
+func (b BridgeClient) collectSignatures(
+	ctx context.Context,
+	uri string,
+	postData url.Values,
+	threshold int,
+	accept signatureAcceptor,
+) ([][]byte, SignatureReport, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	timeout := b.ValidatorTimeout
+	if timeout == 0 {
+		timeout = DefaultValidatorTimeout
+	}
+	responses := make(chan validatorResponse, len(b.ValidatorURLs))
+	for i := range b.ValidatorURLs {
+		go func(i int) {
+			validatorCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			start := time.Now()
+			body, err := b.awaitSignature(validatorCtx, b.ValidatorURLs[i], uri, postData)
+			if err != nil && validatorCtx.Err() == context.DeadlineExceeded {
+				err = context.DeadlineExceeded
+			}
+			responses <- validatorResponse{index: i, body: body, err: err, duration: time.Since(start)}
+		}(i)
+	}
+
+	report := SignatureReport{Threshold: threshold}
+	for _, validatorURL := range b.ValidatorURLs {
+		report.Validators = append(report.Validators, ValidatorResult{
+			URL:    validatorURL,
+			Status: ValidatorSkipped,
+		})
+	}
+	bodies := make([][]byte, len(b.ValidatorURLs))
+	for received := 0; received < len(b.ValidatorURLs) && report.Weight < threshold; received++ {
+		response := <-responses
+		result := &report.Validators[response.index]
+		result.Duration = response.duration
+
+		var weight int
+		err := response.err
+		if err == nil {
+			weight, err = accept(response.index, response.body)
+			if err != nil {
+				result.Status = ValidatorRejected
+			}
+		} else if err == context.DeadlineExceeded {
+			result.Status = ValidatorTimedOut
+		} else {
+			result.Status = ValidatorFailed
+		}
+		if err != nil {
+			result.Err = err
+			continue
+		}
+
+		result.Status = ValidatorSigned
+		bodies[response.index] = response.body
+		report.Weight += weight
+	}
+
+	if b.OnSignatureReport != nil {
+		b.OnSignatureReport(report)
+	}
+	if report.Weight < threshold {
+		return nil, report, signatureError(report)
+	}
+	return bodies, report, nil
+}
+
+// signatureError returns the problem returned by all validators if none of
+// them signed, for example because the deposit was already withdrawn, and a
+// SignatureError otherwise
+func signatureError(report SignatureReport) error {
+	var common error
+	for _, result := range report.Validators {
+		p, ok := result.Err.(problem.P)
+		if !ok || (common != nil && common.(problem.P).Type != p.Type) {
+			return &SignatureError{Report: report}
+		}
+		common = p
+	}
+	if common == nil {
+		return &SignatureError{Report: report}
+	}
+	return common
+}
+
+// awaitSignature requests a signature from the validator until it is
+// available. Validators hold the request until the signature is created,
+// up to signatureWait, so the loop only repeats for slow signatures.
+func (b BridgeClient) awaitSignature(ctx context.Context, validatorURL, uri string, postData url.Values) ([]byte, error) {
+	requestURL := strings.TrimSuffix(validatorURL, "/") + "/" + strings.TrimPrefix(uri, "/")
+	waitData := url.Values{"wait": {strconv.Itoa(int(signatureWait / time.Second))}}
+	for key, values := range postData {
+		waitData[key] = values
+	}
+	for {
+		start := time.Now()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL, strings.NewReader(waitData.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+			defer resp.Body.Close()
+			return ioutil.ReadAll(resp.Body)
+		case http.StatusAccepted:
+			resp.Body.Close()
+			// Validators which do not support waiting respond immediately
+			if elapsed := time.Since(start); elapsed < time.Second {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(time.Second - elapsed):
+				}
+			}
+		default:
+			defer resp.Body.Close()
+			return nil, b.parseProblem(resp)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSignature = "signature"
+
+var testWithdrawnProblem = problem.P{
+	Type:   "withdrawal_already_executed",
+	Title:  "Withdrawal Already Executed",
+	Status: http.StatusBadRequest,
+}
+
+func validatorServer(t *testing.T, handler http.HandlerFunc) string {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func signingValidator(t *testing.T, delay time.Duration, body string) string {
+	return validatorServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/stellar/refund", r.URL.Path)
+		assert.Equal(t, "abc", r.PostFormValue("transaction_hash"))
+		assert.Equal(t, "5", r.PostFormValue("wait"))
+		time.Sleep(delay)
+		_, _ = w.Write([]byte(body))
+	})
+}
+
+func failingValidator(t *testing.T, p problem.P) string {
+	return validatorServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(p.Status)
+		assert.NoError(t, json.NewEncoder(w).Encode(p))
+	})
+}
+
+func slowValidator(t *testing.T) string {
+	return validatorServer(t, func(w http.ResponseWriter, r *http.Request) {
+		// the request body must be consumed for the server to notice
+		// the client giving up on the request
+		assert.NoError(t, r.ParseForm())
+		<-r.Context().Done()
+	})
+}
+
+func acceptTestSignature(_ int, body []byte) (int, error) {
+	if string(body) != testSignature {
+		return 0, fmt.Errorf("unexpected signature %s", body)
+	}
+	return 1, nil
+}
+
+func validatorStatuses(report SignatureReport) []ValidatorStatus {
+	var statuses []ValidatorStatus
+	for _, result := range report.Validators {
+		statuses = append(statuses, result.Status)
+	}
+	return statuses
+}
+
+func TestCollectSignaturesThresholdMet(t *testing.T) {
+	// signing validators respond last so the failing and mismatching
+	// responses are received before the threshold is met
+	var reported []SignatureReport
+	client := BridgeClient{
+		ValidatorURLs: []string{
+			signingValidator(t, 200*time.Millisecond, testSignature),
+			failingValidator(t, problem.ServerError),
+			signingValidator(t, 0, "other"),
+			signingValidator(t, 200*time.Millisecond, testSignature),
+			slowValidator(t),
+		},
+		OnSignatureReport: func(report SignatureReport) {
+			reported = append(reported, report)
+		},
+	}
+
+	bodies, report, err := client.collectSignatures(
+		context.Background(),
+		"stellar/refund",
+		url.Values{"transaction_hash": {"abc"}},
+		2,
+		acceptTestSignature,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte(testSignature), nil, nil, []byte(testSignature), nil}, bodies)
+	assert.Equal(t, 2, report.Threshold)
+	assert.Equal(t, 2, report.Weight)
+	assert.Equal(t, []ValidatorStatus{
+		ValidatorSigned,
+		ValidatorFailed,
+		ValidatorRejected,
+		ValidatorSigned,
+		ValidatorSkipped,
+	}, validatorStatuses(report))
+	assert.Equal(t, problem.ServerError, report.Validators[1].Err)
+	assert.EqualError(t, report.Validators[2].Err, "unexpected signature other")
+	assert.NoError(t, report.Validators[4].Err)
+	assert.Equal(t, []SignatureReport{report}, reported)
+}
+
+func TestCollectSignaturesTimeout(t *testing.T) {
+	client := BridgeClient{
+		ValidatorURLs: []string{
+			signingValidator(t, 0, testSignature),
+			slowValidator(t),
+			signingValidator(t, 0, "other"),
+		},
+		ValidatorTimeout: 100 * time.Millisecond,
+	}
+
+	bodies, report, err := client.collectSignatures(
+		context.Background(),
+		"stellar/refund",
+		url.Values{"transaction_hash": {"abc"}},
+		2,
+		acceptTestSignature,
+	)
+	assert.Nil(t, bodies)
+	require.IsType(t, &SignatureError{}, err)
+	assert.Equal(t, report, err.(*SignatureError).Report)
+	assert.Equal(t, 1, report.Weight)
+	assert.Equal(t, []ValidatorStatus{
+		ValidatorSigned,
+		ValidatorTimedOut,
+		ValidatorRejected,
+	}, validatorStatuses(report))
+	assert.Equal(t, context.DeadlineExceeded, report.Validators[1].Err)
+	assert.Contains(t, err.Error(), "collected signatures of weight 1, 2 required")
+}
+
+func TestCollectSignaturesCommonProblem(t *testing.T) {
+	client := BridgeClient{
+		ValidatorURLs: []string{
+			failingValidator(t, testWithdrawnProblem),
+			failingValidator(t, testWithdrawnProblem),
+		},
+	}
+
+	_, _, err := client.collectSignatures(
+		context.Background(),
+		"stellar/refund",
+		url.Values{"transaction_hash": {"abc"}},
+		1,
+		acceptTestSignature,
+	)
+	assert.Equal(t, testWithdrawnProblem, err)
+}
+
+func TestSignatureError(t *testing.T) {
+	for _, testCase := range []struct {
+		name     string
+		errors   []error
+		expected error
+	}{
+		{
+			name:     "same problem",
+			errors:   []error{testWithdrawnProblem, testWithdrawnProblem},
+			expected: testWithdrawnProblem,
+		},
+		{
+			name:   "different problems",
+			errors: []error{testWithdrawnProblem, problem.ServerError},
+		},
+		{
+			name:   "problem and error",
+			errors: []error{testWithdrawnProblem, fmt.Errorf("connection refused")},
+		},
+		{
+			name:   "skipped validator",
+			errors: []error{testWithdrawnProblem, nil},
+		},
+		{
+			name: "no validators",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			report := SignatureReport{Threshold: 1}
+			for _, err := range testCase.errors {
+				report.Validators = append(report.Validators, ValidatorResult{Err: err})
+			}
+			err := signatureError(report)
+			if testCase.expected != nil {
+				assert.Equal(t, testCase.expected, err)
+			} else {
+				assert.Equal(t, &SignatureError{Report: report}, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/support/errors"
+
+	"github.com/stellar/starbridge/stellar/signer"
+)
+
+var encryptStellarKeyCmd = &cobra.Command{
+	Use:   "encrypt-stellar-key",
+	Short: "encrypt a Stellar secret key into a keystore file",
+	Long: "Reads a Stellar secret key from stdin, encrypts it with the " +
+		"password in the password file and writes the keystore file used " +
+		"by the keystore key backend. A new random key is encrypted if " +
+		"--random is set.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		outPath := cmd.Flags().Lookup("out").Value.String()
+		passwordPath := cmd.Flags().Lookup("password-file").Value.String()
+		if outPath == "" || passwordPath == "" {
+			return errors.New("--out and --password-file are required")
+		}
+		random, err := cmd.Flags().GetBool("random")
+		if err != nil {
+			return err
+		}
+
+		password, err := ioutil.ReadFile(passwordPath)
+		if err != nil {
+			return errors.Wrap(err, "error reading password file")
+		}
+
+		var kp *keypair.Full
+		if random {
+			kp, err = keypair.Random()
+		} else {
+			kp, err = readStellarSecret()
+		}
+		if err != nil {
+			return err
+		}
+
+		// the password is trimmed the same way the keystore backend reads it
+		keyJSON, err := signer.EncryptKey(kp, strings.TrimRight(string(password), "\r\n"))
+		if err != nil {
+			return err
+		}
+		out, err := os.OpenFile(outPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return errors.Wrap(err, "error creating keystore file")
+		}
+		if _, err = out.Write(keyJSON); err != nil {
+			out.Close()
+			return errors.Wrap(err, "error writing keystore file")
+		}
+		if err = out.Close(); err != nil {
+			return errors.Wrap(err, "error writing keystore file")
+		}
+
+		// make sure the validator is able to load the key
+		if _, err = signer.NewKeystoreBackend(outPath, passwordPath); err != nil {
+			return err
+		}
+		fmt.Println(kp.Address())
+		return nil
+	},
+}
+
+func readStellarSecret() (*keypair.Full, error) {
+	secret, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && secret == "" {
+		return nil, errors.Wrap(err, "error reading secret key from stdin")
+	}
+	kp, err := keypair.ParseFull(strings.TrimSpace(secret))
+	if err != nil {
+		return nil, errors.New("invalid Stellar secret key")
+	}
+	return kp, nil
+}
+
+func init() {
+	encryptStellarKeyCmd.Flags().String("out", "", "path of the keystore file to create")
+	encryptStellarKeyCmd.Flags().String("password-file", "", "path of the file containing the keystore password")
+	encryptStellarKeyCmd.Flags().Bool("random", false, "encrypt a new random key instead of reading one from stdin")
+}
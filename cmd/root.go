@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"context"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stellar/go/support/config"
+	"github.com/stellar/go/support/db"
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/starbridge/app"
+	"github.com/stellar/starbridge/backend"
+	"github.com/stellar/starbridge/ethereum"
+	"github.com/stellar/starbridge/store"
 )
 
 var RootCmd = &cobra.Command{
@@ -15,22 +20,17 @@ var RootCmd = &cobra.Command{
 	SilenceErrors: true,
 	SilenceUsage:  true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var (
-			cfg     app.Config
-			cfgPath = cmd.PersistentFlags().Lookup("conf").Value.String()
-		)
-
-		err := config.Read(cfgPath, &cfg)
+		cfg, err := readConfig(cmd)
 		if err != nil {
-			switch cause := errors.Cause(err).(type) {
-			case *config.InvalidConfigError:
-				return errors.Wrap(cause, "config file")
-			default:
-				return err
-			}
+			return err
 		}
 
-		cfg.EthereumFinalityBuffer = 6
+		if cfg.EthereumFinalityMode == "" {
+			cfg.EthereumFinalityMode = string(ethereum.FixedDepthFinality)
+		}
+		if cfg.EthereumFinalityMode == string(ethereum.FixedDepthFinality) && cfg.EthereumFinalityBuffer == 0 {
+			cfg.EthereumFinalityBuffer = 6
+		}
 		cfg.WithdrawalWindow = time.Hour * 24
 		app := app.NewApp(cfg)
 		app.Run()
@@ -38,6 +38,39 @@ var RootCmd = &cobra.Command{
 	},
 }
 
+func readConfig(cmd *cobra.Command) (app.Config, error) {
+	var (
+		cfg     app.Config
+		cfgPath = cmd.Flags().Lookup("conf").Value.String()
+	)
+
+	err := config.Read(cfgPath, &cfg)
+	if err != nil {
+		switch cause := errors.Cause(err).(type) {
+		case *config.InvalidConfigError:
+			return cfg, errors.Wrap(cause, "config file")
+		default:
+			return cfg, err
+		}
+	}
+	return cfg, nil
+}
+
+// latestEthereumSigner returns the given signer configured with the bridge
+// contract version ingested by the validator, so signatures created by the
+// command remain valid after the validator set was updated
+func latestEthereumSigner(ctx context.Context, cfg app.Config, signer ethereum.Signer) (ethereum.Signer, error) {
+	session, err := db.Open("postgres", cfg.PostgresDSN)
+	if err != nil {
+		return ethereum.Signer{}, errors.Wrap(err, "cannot open DB")
+	}
+	defer session.Close()
+	return backend.LatestEthereumSigner(ctx, &store.DB{Session: session}, signer)
+}
+
 func init() {
 	RootCmd.PersistentFlags().String("conf", "./starbridge.cfg", "config file path")
+	RootCmd.AddCommand(signSetPausedCmd)
+	RootCmd.AddCommand(signRegisterStellarAssetCmd)
+	RootCmd.AddCommand(encryptStellarKeyCmd)
 }
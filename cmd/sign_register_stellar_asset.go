@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+
+	"github.com/stellar/starbridge/app"
+	"github.com/stellar/starbridge/controllers"
+	"github.com/stellar/starbridge/ethereum"
+)
+
+var signRegisterStellarAssetCmd = &cobra.Command{
+	Use:   "sign-register-stellar-asset",
+	Short: "sign a registerStellarAsset request for the bridge smart contract",
+	Long: "Signs a registerStellarAsset request with the ethereum key of the " +
+		"validator and prints the signature. If the StellarAsset artifact is " +
+		"available the address of the ERC20 token and the matching " +
+		"asset_mapping entry are printed as well.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := readConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		request, err := controllers.ParseRegisterStellarAssetRequest(
+			cmd.Flags().Lookup("decimals").Value.String(),
+			cmd.Flags().Lookup("name").Value.String(),
+			cmd.Flags().Lookup("symbol").Value.String(),
+		)
+		if err != nil {
+			return err
+		}
+
+		artifactPath := cmd.Flags().Lookup("artifact").Value.String()
+		if artifactPath == "" {
+			artifactPath = cfg.StellarAssetArtifactPath
+		}
+		var bytecode []byte
+		if artifactPath != "" {
+			bytecode, err = ethereum.LoadContractBytecode(artifactPath)
+			if err != nil {
+				return err
+			}
+		}
+
+		signer, err := app.NewEthereumSigner(cfg)
+		if err != nil {
+			return err
+		}
+		signer, err = latestEthereumSigner(context.Background(), cfg, signer)
+		if err != nil {
+			return err
+		}
+		response, err := controllers.SignRegisterStellarAsset(
+			signer,
+			common.HexToAddress(cfg.EthereumBridgeAddress),
+			bytecode,
+			cmd.Flags().Lookup("asset").Value.String(),
+			request,
+		)
+		if err != nil {
+			return err
+		}
+
+		responseBytes, err := json.MarshalIndent(response, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(responseBytes))
+
+		if response.AssetMapping != nil {
+			fmt.Println()
+			fmt.Println("[[asset_mapping]]")
+			fmt.Printf("stellar_asset=%q\n", response.AssetMapping.StellarAsset)
+			fmt.Printf("ethereum_token=%q\n", response.AssetMapping.EthereumToken)
+			fmt.Printf("stellar_to_ethereum=%q\n", response.AssetMapping.StellarToEthereum)
+		}
+		return nil
+	},
+}
+
+func init() {
+	signRegisterStellarAssetCmd.Flags().String("asset", "", "Stellar asset represented by the token (code:issuer or native)")
+	signRegisterStellarAssetCmd.Flags().Uint8("decimals", 18, "decimals of the ERC20 token")
+	signRegisterStellarAssetCmd.Flags().String("name", "", "name of the ERC20 token")
+	signRegisterStellarAssetCmd.Flags().String("symbol", "", "symbol of the ERC20 token")
+	signRegisterStellarAssetCmd.Flags().String(
+		"artifact",
+		"",
+		"path to the StellarAsset hardhat artifact (defaults to stellar_asset_artifact_path)",
+	)
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stellar/starbridge/app"
+	"github.com/stellar/starbridge/controllers"
+)
+
+var signSetPausedCmd = &cobra.Command{
+	Use:   "sign-set-paused",
+	Short: "sign a setPaused request for the bridge smart contract",
+	Long: "Signs a setPaused request with the ethereum key of the validator " +
+		"and prints the signature. The value is a bitmask where 1 pauses " +
+		"deposits and 2 pauses withdrawals.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := readConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		request, err := controllers.ParseSetPausedRequest(
+			cmd.Flags().Lookup("value").Value.String(),
+			cmd.Flags().Lookup("nonce").Value.String(),
+			cmd.Flags().Lookup("expiration").Value.String(),
+		)
+		if err != nil {
+			return err
+		}
+
+		signer, err := app.NewEthereumSigner(cfg)
+		if err != nil {
+			return err
+		}
+		signer, err = latestEthereumSigner(context.Background(), cfg, signer)
+		if err != nil {
+			return err
+		}
+		response, err := controllers.SignSetPaused(signer, request, time.Now())
+		if err != nil {
+			return err
+		}
+
+		responseBytes, err := json.MarshalIndent(response, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(responseBytes))
+		return nil
+	},
+}
+
+func init() {
+	signSetPausedCmd.Flags().Uint8("value", 0, "paused bitmask (1 = deposits, 2 = withdrawals, 3 = both)")
+	signSetPausedCmd.Flags().String("nonce", "", "nonce making the request unique")
+	signSetPausedCmd.Flags().Int64("expiration", 0, "unix timestamp after which the request expires")
+}
@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stellar/go/support/render/problem"
+
+	"github.com/stellar/starbridge/backend"
+	"github.com/stellar/starbridge/store"
+)
+
+type AssetMappingResponse struct {
+	// ID is 0 for mappings from the config file
+	ID                int64  `json:"id,string"`
+	StellarAsset      string `json:"stellar_asset"`
+	EthereumToken     string `json:"ethereum_token"`
+	StellarToEthereum string `json:"stellar_to_ethereum"`
+	FlatFee           string `json:"flat_fee,omitempty"`
+	FeeBasisPoints    uint32 `json:"fee_basis_points,omitempty"`
+	ActivationTime    int64  `json:"activation_time,string"`
+	// DeactivationTime is omitted if the mapping was never disabled
+	DeactivationTime int64 `json:"deactivation_time,string,omitempty"`
+}
+
+type AssetMappingsResponse struct {
+	AssetMappings []AssetMappingResponse `json:"asset_mappings"`
+}
+
+func newAssetMappingResponse(mapping store.AssetMapping) AssetMappingResponse {
+	return AssetMappingResponse{
+		ID:                mapping.ID,
+		StellarAsset:      mapping.StellarAsset,
+		EthereumToken:     mapping.EthereumToken,
+		StellarToEthereum: mapping.StellarToEthereum,
+		FlatFee:           mapping.FlatFee,
+		FeeBasisPoints:    mapping.FeeBasisPoints,
+		ActivationTime:    mapping.ActivationTime,
+		DeactivationTime:  mapping.DeactivationTime.Int64,
+	}
+}
+
+// ListAssetMappingsHandler lists the asset mappings from the config file
+// and the database. It must only be exposed on the admin port.
+type ListAssetMappingsHandler struct {
+	AssetMappings *backend.AssetMappings
+}
+
+func (c *ListAssetMappingsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	response := AssetMappingsResponse{AssetMappings: []AssetMappingResponse{}}
+	for _, entry := range c.AssetMappings.ConfigEntries() {
+		response.AssetMappings = append(response.AssetMappings, AssetMappingResponse{
+			StellarAsset:      entry.StellarAsset,
+			EthereumToken:     entry.EthereumToken,
+			StellarToEthereum: entry.StellarToEthereum,
+			FlatFee:           entry.FlatFee,
+			FeeBasisPoints:    entry.FeeBasisPoints,
+		})
+	}
+	for _, mapping := range c.AssetMappings.Entries() {
+		response.AssetMappings = append(response.AssetMappings, newAssetMappingResponse(mapping))
+	}
+	renderJSON(w, response)
+}
+
+// AddAssetMappingHandler adds an asset mapping which becomes active at the
+// given activation time. The same mapping must be added to all validators.
+// It must only be exposed on the admin port.
+type AddAssetMappingHandler struct {
+	AssetMappings *backend.AssetMappings
+}
+
+func (c *AddAssetMappingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	activationTime, err := strconv.ParseInt(r.PostFormValue("activation_time"), 10, 64)
+	if err != nil {
+		problem.Render(r.Context(), w, backend.InvalidAssetMappingTime)
+		return
+	}
+	var feeBasisPoints uint64
+	if value := r.PostFormValue("fee_basis_points"); value != "" {
+		feeBasisPoints, err = strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			problem.Render(r.Context(), w, backend.InvalidAssetMapping)
+			return
+		}
+	}
+
+	mapping, err := c.AssetMappings.Add(
+		r.Context(),
+		backend.AssetMappingConfigEntry{
+			StellarAsset:      r.PostFormValue("stellar_asset"),
+			EthereumToken:     r.PostFormValue("ethereum_token"),
+			StellarToEthereum: r.PostFormValue("stellar_to_ethereum"),
+			FlatFee:           r.PostFormValue("flat_fee"),
+			FeeBasisPoints:    uint32(feeBasisPoints),
+		},
+		time.Unix(activationTime, 0),
+		time.Now(),
+	)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	renderJSON(w, newAssetMappingResponse(mapping))
+}
+
+// DisableAssetMappingHandler disables an asset mapping from the given
+// deactivation time. It must only be exposed on the admin port.
+type DisableAssetMappingHandler struct {
+	AssetMappings *backend.AssetMappings
+}
+
+func (c *DisableAssetMappingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		problem.Render(r.Context(), w, backend.AssetMappingNotFound)
+		return
+	}
+	deactivationTime, err := strconv.ParseInt(r.PostFormValue("deactivation_time"), 10, 64)
+	if err != nil {
+		problem.Render(r.Context(), w, backend.InvalidAssetMappingTime)
+		return
+	}
+
+	mapping, err := c.AssetMappings.Disable(r.Context(), id, time.Unix(deactivationTime, 0), time.Now())
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	renderJSON(w, newAssetMappingResponse(mapping))
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"context"
 	"database/sql"
 	"net/http"
 	"regexp"
@@ -46,40 +47,78 @@ var (
 	validTxHash = regexp.MustCompile("^(0x)?([A-Fa-f0-9]{64})$")
 )
 
-func getEthereumDeposit(observer ethereum.Observer, depositStore *store.DB, finalityBuffer uint64, r *http.Request) (store.EthereumDeposit, error) {
-	txHash := r.PostFormValue("transaction_hash")
+func getEthereumDeposit(observer ethereum.Observer, depositStore *store.DB, r *http.Request) (store.EthereumDeposit, error) {
+	return findEthereumDeposit(
+		r.Context(),
+		observer,
+		depositStore,
+		r.PostFormValue("transaction_hash"),
+		r.PostFormValue("log_index"),
+	)
+}
+
+// findEthereumDeposit looks up the deposit identified by the given transaction
+// hash and log index. If the deposit is not present in the store it will be
+// fetched from the ethereum node and persisted once it is final.
+func findEthereumDeposit(
+	ctx context.Context,
+	observer ethereum.Observer,
+	depositStore *store.DB,
+	txHash, rawLogIndex string,
+) (store.EthereumDeposit, error) {
+	deposit, stored, err := lookupEthereumDeposit(ctx, observer, depositStore, txHash, rawLogIndex)
+	if err != nil || stored {
+		return deposit, err
+	}
+	if err = depositStore.InsertEthereumDeposit(ctx, deposit); err != nil {
+		return store.EthereumDeposit{}, err
+	}
+	return deposit, nil
+}
+
+// lookupEthereumDeposit looks up the deposit identified by the given
+// transaction hash and log index like findEthereumDeposit without persisting
+// deposits fetched from the ethereum node. stored is true if the deposit was
+// found in the store.
+func lookupEthereumDeposit(
+	ctx context.Context,
+	observer ethereum.Observer,
+	depositStore *store.DB,
+	txHash, rawLogIndex string,
+) (store.EthereumDeposit, bool, error) {
 	if !validTxHash.MatchString(txHash) {
-		return store.EthereumDeposit{}, InvalidEthereumTxHash
+		return store.EthereumDeposit{}, false, InvalidEthereumTxHash
 	}
-	parsed, err := strconv.ParseInt(r.PostFormValue("log_index"), 10, 32)
+	logIndex, err := parseLogIndex(rawLogIndex)
 	if err != nil {
-		return store.EthereumDeposit{}, InvalidLogIndex
+		return store.EthereumDeposit{}, false, err
 	}
-	logIndex := uint(parsed)
 	depositID := ethereum.DepositID(txHash, logIndex)
 
-	storeDeposit, err := depositStore.GetEthereumDeposit(r.Context(), depositID)
+	storeDeposit, err := depositStore.GetEthereumDeposit(ctx, depositID)
 	if err == nil {
-		return storeDeposit, nil
+		return storeDeposit, true, nil
 	} else if err != sql.ErrNoRows {
-		return store.EthereumDeposit{}, err
+		return store.EthereumDeposit{}, false, err
 	}
 
-	deposit, err := observer.GetDeposit(r.Context(), txHash, logIndex)
+	deposit, err := observer.GetDeposit(ctx, txHash, logIndex)
 	if ethereum.IsInvalidGetDepositRequest(err) {
-		return store.EthereumDeposit{}, InvalidDepositLog
+		return store.EthereumDeposit{}, false, InvalidDepositLog
 	} else if err == ethereum.ErrTxHashNotFound {
-		return store.EthereumDeposit{}, EthereumTxHashNotFound
+		return store.EthereumDeposit{}, false, EthereumTxHashNotFound
 	} else if err != nil {
-		return store.EthereumDeposit{}, err
+		return store.EthereumDeposit{}, false, err
 	}
 
-	block, err := observer.GetLatestBlock(r.Context())
-	if err != nil {
-		return store.EthereumDeposit{}, err
+	block, err := observer.GetLatestFinalBlock(ctx)
+	if err == ethereum.ErrNoFinalBlock {
+		return store.EthereumDeposit{}, false, EthereumTxRequiresMoreConfirmations
+	} else if err != nil {
+		return store.EthereumDeposit{}, false, err
 	}
-	if deposit.BlockNumber+finalityBuffer > block.Number {
-		return store.EthereumDeposit{}, EthereumTxRequiresMoreConfirmations
+	if deposit.BlockNumber > block.Number {
+		return store.EthereumDeposit{}, false, EthereumTxRequiresMoreConfirmations
 	}
 
 	storeDeposit = store.EthereumDeposit{
@@ -91,11 +130,16 @@ func getEthereumDeposit(observer ethereum.Observer, depositStore *store.DB, fina
 		Hash:        deposit.TxHash.String(),
 		LogIndex:    deposit.LogIndex,
 		BlockNumber: deposit.BlockNumber,
+		BlockHash:   deposit.BlockHash.String(),
 		BlockTime:   deposit.Time.Unix(),
 	}
-	if err = depositStore.InsertEthereumDeposit(r.Context(), storeDeposit); err != nil {
-		return store.EthereumDeposit{}, err
-	}
+	return storeDeposit, false, nil
+}
 
-	return storeDeposit, nil
+func parseLogIndex(rawLogIndex string) (uint, error) {
+	parsed, err := strconv.ParseInt(rawLogIndex, 10, 32)
+	if err != nil {
+		return 0, InvalidLogIndex
+	}
+	return uint(parsed), nil
 }
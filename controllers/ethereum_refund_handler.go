@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/stellar/go/support/render/problem"
 	"github.com/stellar/starbridge/backend"
@@ -18,24 +20,27 @@ type EthereumSignatureResponse struct {
 	Expiration int64  `json:"expiration,string"`
 	Token      string `json:"token"`
 	Amount     string `json:"amount"`
+	// Fee is the amount of tokens deducted from the deposit as the bridge
+	// fee. It is omitted for refunds which are not charged a fee.
+	Fee string `json:"fee,omitempty"`
 }
 
 type EthereumRefundHandler struct {
 	Observer                ethereum.Observer
 	Store                   *store.DB
 	EthereumRefundValidator backend.EthereumRefundValidator
-	EthereumFinalityBuffer  uint64
+	SignatureNotifier       *backend.SignatureNotifier
 }
 
 func (c *EthereumRefundHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	deposit, err := getEthereumDeposit(c.Observer, c.Store, c.EthereumFinalityBuffer, r)
+	deposit, err := getEthereumDeposit(c.Observer, c.Store, r)
 	if err != nil {
 		problem.Render(r.Context(), w, err)
 		return
 	}
 
 	// Check if outgoing transaction exists
-	row, err := c.Store.GetEthereumSignature(r.Context(), store.Refund, deposit.ID)
+	row, err := getEthereumSignature(r.Context(), c.Store, store.Refund, deposit.ID)
 	if err != nil && err != sql.ErrNoRows {
 		problem.Render(r.Context(), w, err)
 		return
@@ -62,15 +67,40 @@ func (c *EthereumRefundHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	err = c.Store.InsertSignatureRequest(r.Context(), store.SignatureRequest{
+	request := store.SignatureRequest{
 		DepositChain: store.Ethereum,
 		Action:       store.Refund,
 		DepositID:    deposit.ID,
-	})
+	}
+	err = c.Store.InsertSignatureRequest(r.Context(), request, time.Now().Unix())
 	if err != nil {
 		problem.Render(r.Context(), w, err)
 		return
 	}
 
+	if awaitSignatureRequest(r, c.Store, c.SignatureNotifier, request) {
+		c.ServeHTTP(w, r)
+		return
+	}
+
 	w.WriteHeader(http.StatusAccepted)
 }
+
+// getEthereumSignature returns the stored signature for the given deposit.
+// Signatures created for a previous validator set can no longer be verified
+// by the bridge contract so sql.ErrNoRows is returned for them, which causes
+// the deposit to be signed again.
+func getEthereumSignature(ctx context.Context, depositStore *store.DB, action store.Action, depositID string) (store.EthereumSignature, error) {
+	row, err := depositStore.GetEthereumSignature(ctx, action, depositID)
+	if err != nil {
+		return row, err
+	}
+	stale, err := backend.IsStaleEthereumSignature(ctx, depositStore, row)
+	if err != nil {
+		return row, err
+	}
+	if stale {
+		return store.EthereumSignature{}, sql.ErrNoRows
+	}
+	return row, nil
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/stellar/starbridge/backend"
 
@@ -14,6 +15,7 @@ import (
 type EthereumWithdrawalHandler struct {
 	Store                       *store.DB
 	EthereumWithdrawalValidator backend.EthereumWithdrawalValidator
+	SignatureNotifier           *backend.SignatureNotifier
 }
 
 func (c *EthereumWithdrawalHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -24,12 +26,17 @@ func (c *EthereumWithdrawalHandler) ServeHTTP(w http.ResponseWriter, r *http.Req
 	}
 
 	// Check if outgoing transaction exists
-	row, err := c.Store.GetEthereumSignature(r.Context(), store.Withdraw, deposit.ID)
+	row, err := getEthereumSignature(r.Context(), c.Store, store.Withdraw, deposit.ID)
 	if err != nil && err != sql.ErrNoRows {
 		problem.Render(r.Context(), w, err)
 		return
 	}
 	if err == nil {
+		fee, err := c.Store.GetBridgeFee(r.Context(), store.Stellar, deposit.ID)
+		if err != nil && err != sql.ErrNoRows {
+			problem.Render(r.Context(), w, err)
+			return
+		}
 		responseBytes, err := json.Marshal(EthereumSignatureResponse{
 			Address:    row.Address,
 			Signature:  row.Signature,
@@ -37,6 +44,7 @@ func (c *EthereumWithdrawalHandler) ServeHTTP(w http.ResponseWriter, r *http.Req
 			Expiration: row.Expiration,
 			Token:      row.Token,
 			Amount:     row.Amount,
+			Fee:        fee.Amount,
 		})
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
@@ -52,15 +60,21 @@ func (c *EthereumWithdrawalHandler) ServeHTTP(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	err = c.Store.InsertSignatureRequest(r.Context(), store.SignatureRequest{
+	request := store.SignatureRequest{
 		DepositChain: store.Stellar,
 		Action:       store.Withdraw,
 		DepositID:    deposit.ID,
-	})
+	}
+	err = c.Store.InsertSignatureRequest(r.Context(), request, time.Now().Unix())
 	if err != nil {
 		problem.Render(r.Context(), w, err)
 		return
 	}
 
+	if awaitSignatureRequest(r, c.Store, c.SignatureNotifier, request) {
+		c.ServeHTTP(w, r)
+		return
+	}
+
 	w.WriteHeader(http.StatusAccepted)
 }
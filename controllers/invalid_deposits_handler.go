@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/render/problem"
+
+	"github.com/stellar/starbridge/store"
+)
+
+const (
+	defaultInvalidDepositsLimit = 50
+	maxInvalidDepositsLimit     = 200
+)
+
+var InvalidDepositsQuery = problem.P{
+	Type:   "invalid_deposits_query",
+	Title:  "Invalid Deposits Query",
+	Status: http.StatusBadRequest,
+	Detail: "The sender must be a Stellar account and the limit must be between 1 and 200.",
+}
+
+type InvalidStellarDepositResponse struct {
+	DepositID     string `json:"deposit_id"`
+	Asset         string `json:"asset"`
+	Amount        string `json:"amount"`
+	Sender        string `json:"sender"`
+	LedgerTime    int64  `json:"ledger_time,string"`
+	InvalidReason string `json:"invalid_reason"`
+}
+
+type InvalidStellarDepositsResponse struct {
+	Deposits []InvalidStellarDepositResponse `json:"deposits"`
+}
+
+// InvalidStellarDepositsHandler lists Stellar deposits which cannot be
+// withdrawn because their memo does not contain a valid Ethereum recipient.
+// These deposits can be refunded immediately.
+type InvalidStellarDepositsHandler struct {
+	Store *store.DB
+}
+
+func (c *InvalidStellarDepositsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	sender := r.URL.Query().Get("sender")
+	if sender != "" && !strkey.IsValidEd25519PublicKey(sender) {
+		problem.Render(r.Context(), w, InvalidDepositsQuery)
+		return
+	}
+	limit := uint64(defaultInvalidDepositsLimit)
+	if rawLimit := r.URL.Query().Get("limit"); rawLimit != "" {
+		var err error
+		limit, err = strconv.ParseUint(rawLimit, 10, 64)
+		if err != nil || limit == 0 || limit > maxInvalidDepositsLimit {
+			problem.Render(r.Context(), w, InvalidDepositsQuery)
+			return
+		}
+	}
+
+	deposits, err := c.Store.GetInvalidStellarDeposits(r.Context(), sender, limit)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	response := InvalidStellarDepositsResponse{Deposits: []InvalidStellarDepositResponse{}}
+	for _, deposit := range deposits {
+		response.Deposits = append(response.Deposits, InvalidStellarDepositResponse{
+			DepositID:     deposit.ID,
+			Asset:         deposit.Asset,
+			Amount:        deposit.Amount,
+			Sender:        deposit.Sender,
+			LedgerTime:    deposit.LedgerTime,
+			InvalidReason: deposit.InvalidReason,
+		})
+	}
+	renderJSON(w, response)
+}
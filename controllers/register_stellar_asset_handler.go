@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"encoding/hex"
+	"net/http"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stellar/go/support/render/problem"
+
+	"github.com/stellar/starbridge/backend"
+	"github.com/stellar/starbridge/ethereum"
+	"github.com/stellar/starbridge/solidity-go"
+	"github.com/stellar/starbridge/store"
+)
+
+type RegisterStellarAssetSignatureResponse struct {
+	Address   string `json:"address"`
+	Signature string `json:"signature"`
+	Version   uint64 `json:"version,string"`
+	Decimals  uint8  `json:"decimals"`
+	Name      string `json:"name"`
+	Symbol    string `json:"symbol"`
+	// Token is the address of the ERC20 token which will be deployed. It is
+	// only included if the StellarAsset bytecode is known to the validator.
+	Token        string                           `json:"token,omitempty"`
+	AssetMapping *backend.AssetMappingConfigEntry `json:"asset_mapping,omitempty"`
+}
+
+// RegisterStellarAssetHandler signs registerStellarAsset requests. It must
+// only be exposed on the admin port.
+type RegisterStellarAssetHandler struct {
+	Store          *store.DB
+	EthereumSigner ethereum.Signer
+	BridgeAddress  common.Address
+	// StellarAssetBytecode is the creation code of the StellarAsset
+	// contract. It is optional and used to compute the token address.
+	StellarAssetBytecode []byte
+}
+
+func (c *RegisterStellarAssetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	asset := r.PostFormValue("asset")
+	request, err := ParseRegisterStellarAssetRequest(
+		r.PostFormValue("decimals"),
+		r.PostFormValue("name"),
+		r.PostFormValue("symbol"),
+	)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	ethSigner, err := backend.LatestEthereumSigner(r.Context(), c.Store, c.EthereumSigner)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	response, err := SignRegisterStellarAsset(
+		ethSigner,
+		c.BridgeAddress,
+		c.StellarAssetBytecode,
+		asset,
+		request,
+	)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	renderJSON(w, response)
+}
+
+// ParseRegisterStellarAssetRequest parses the fields of a registerStellarAsset
+// request
+func ParseRegisterStellarAssetRequest(decimals, name, symbol string) (solidity.RegisterStellarAssetRequest, error) {
+	parsedDecimals, err := strconv.ParseUint(decimals, 10, 8)
+	if err != nil {
+		return solidity.RegisterStellarAssetRequest{}, backend.InvalidRegisterStellarAssetRequest
+	}
+	return solidity.RegisterStellarAssetRequest{
+		Decimals: uint8(parsedDecimals),
+		Name:     name,
+		Symbol:   symbol,
+	}, nil
+}
+
+// SignRegisterStellarAsset validates and signs the given registerStellarAsset
+// request. If stellarAssetBytecode is not empty the response also contains
+// the address of the token and its asset mapping entry.
+func SignRegisterStellarAsset(
+	signer ethereum.Signer,
+	bridgeAddress common.Address,
+	stellarAssetBytecode []byte,
+	asset string,
+	request solidity.RegisterStellarAssetRequest,
+) (RegisterStellarAssetSignatureResponse, error) {
+	if err := backend.ValidateRegisterStellarAssetRequest(asset, request); err != nil {
+		return RegisterStellarAssetSignatureResponse{}, err
+	}
+	sig, err := signer.SignRegisterStellarAsset(request)
+	if err != nil {
+		return RegisterStellarAssetSignatureResponse{}, err
+	}
+	response := RegisterStellarAssetSignatureResponse{
+		Address:   signer.Address().String(),
+		Signature: hex.EncodeToString(sig),
+		Version:   signer.Version(),
+		Decimals:  request.Decimals,
+		Name:      request.Name,
+		Symbol:    request.Symbol,
+	}
+	if len(stellarAssetBytecode) > 0 {
+		token, err := ethereum.StellarAssetAddress(bridgeAddress, stellarAssetBytecode, request)
+		if err != nil {
+			return RegisterStellarAssetSignatureResponse{}, err
+		}
+		entry := backend.StellarAssetMappingEntry(asset, token, request.Decimals)
+		response.Token = entry.EthereumToken
+		response.AssetMapping = &entry
+	}
+	return response, nil
+}
@@ -0,0 +1,15 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+func renderJSON(w http.ResponseWriter, response interface{}) {
+	responseBytes, err := json.Marshal(response)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	} else {
+		_, _ = w.Write(responseBytes)
+	}
+}
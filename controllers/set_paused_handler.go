@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/stellar/go/support/render/problem"
+
+	"github.com/stellar/starbridge/backend"
+	"github.com/stellar/starbridge/ethereum"
+	"github.com/stellar/starbridge/solidity-go"
+	"github.com/stellar/starbridge/store"
+)
+
+type SetPausedSignatureResponse struct {
+	Address    string `json:"address"`
+	Signature  string `json:"signature"`
+	Value      uint8  `json:"value"`
+	Nonce      string `json:"nonce"`
+	Expiration int64  `json:"expiration,string"`
+}
+
+// SetPausedHandler signs setPaused requests. It must only be exposed on
+// the admin port.
+type SetPausedHandler struct {
+	Store          *store.DB
+	EthereumSigner ethereum.Signer
+}
+
+func (c *SetPausedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	request, err := ParseSetPausedRequest(
+		r.PostFormValue("value"),
+		r.PostFormValue("nonce"),
+		r.PostFormValue("expiration"),
+	)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	ethSigner, err := backend.LatestEthereumSigner(r.Context(), c.Store, c.EthereumSigner)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	response, err := SignSetPaused(ethSigner, request, time.Now())
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	responseBytes, err := json.Marshal(response)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	} else {
+		_, _ = w.Write(responseBytes)
+	}
+}
+
+// ParseSetPausedRequest parses the decimal encoded fields of a setPaused request
+func ParseSetPausedRequest(value, nonce, expiration string) (solidity.SetPausedRequest, error) {
+	parsedValue, err := strconv.ParseUint(value, 10, 8)
+	if err != nil {
+		return solidity.SetPausedRequest{}, backend.InvalidPausedValue
+	}
+	parsedNonce, ok := new(big.Int).SetString(nonce, 10)
+	if !ok {
+		return solidity.SetPausedRequest{}, backend.InvalidGovernanceNonce
+	}
+	parsedExpiration, err := strconv.ParseInt(expiration, 10, 64)
+	if err != nil {
+		return solidity.SetPausedRequest{}, backend.InvalidGovernanceExpiration
+	}
+	return solidity.SetPausedRequest{
+		Value:      uint8(parsedValue),
+		Nonce:      parsedNonce,
+		Expiration: big.NewInt(parsedExpiration),
+	}, nil
+}
+
+// SignSetPaused validates and signs the given setPaused request
+func SignSetPaused(signer ethereum.Signer, request solidity.SetPausedRequest, now time.Time) (SetPausedSignatureResponse, error) {
+	if err := backend.ValidateSetPausedRequest(request, now); err != nil {
+		return SetPausedSignatureResponse{}, err
+	}
+	sig, err := signer.SignSetPaused(request)
+	if err != nil {
+		return SetPausedSignatureResponse{}, err
+	}
+	return SetPausedSignatureResponse{
+		Address:    signer.Address().String(),
+		Signature:  hex.EncodeToString(sig),
+		Value:      request.Value,
+		Nonce:      request.Nonce.String(),
+		Expiration: request.Expiration.Int64(),
+	}, nil
+}
@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stellar/go/support/render/problem"
+
+	"github.com/stellar/starbridge/store"
+)
+
+const (
+	defaultSignatureRequestsLimit = 50
+	maxSignatureRequestsLimit     = 200
+)
+
+var (
+	InvalidSignatureRequestsQuery = problem.P{
+		Type:   "invalid_signature_requests_query",
+		Title:  "Invalid Signature Requests Query",
+		Status: http.StatusBadRequest,
+		Detail: "The status must be one of pending, succeeded, rejected, failed or cancelled and the limit must be between 1 and 200.",
+	}
+	SignatureRequestNotFound = problem.P{
+		Type:   "signature_request_not_found",
+		Title:  "Signature Request Not Found",
+		Status: http.StatusNotFound,
+		Detail: "The signature request does not exist.",
+	}
+	SignatureRequestNotRetryable = problem.P{
+		Type:   "signature_request_not_retryable",
+		Title:  "Signature Request Not Retryable",
+		Status: http.StatusConflict,
+		Detail: "The signature request does not exist or was already processed successfully.",
+	}
+	SignatureRequestNotCancellable = problem.P{
+		Type:   "signature_request_not_cancellable",
+		Title:  "Signature Request Not Cancellable",
+		Status: http.StatusConflict,
+		Detail: "The signature request does not exist or is not pending.",
+	}
+)
+
+type SignatureRequestResponse struct {
+	DepositChain string `json:"deposit_chain"`
+	Action       string `json:"action"`
+	DepositID    string `json:"deposit_id"`
+	Status       string `json:"status"`
+	Attempts     int    `json:"attempts"`
+	// NextAttemptAt is omitted if the request can be processed immediately
+	NextAttemptAt int64  `json:"next_attempt_at,string,omitempty"`
+	LastError     string `json:"last_error,omitempty"`
+	CreatedAt     int64  `json:"created_at,string"`
+	UpdatedAt     int64  `json:"updated_at,string"`
+}
+
+type SignatureRequestsResponse struct {
+	SignatureRequests []SignatureRequestResponse `json:"signature_requests"`
+}
+
+func newSignatureRequestResponse(request store.SignatureRequest) SignatureRequestResponse {
+	return SignatureRequestResponse{
+		DepositChain:  string(request.DepositChain),
+		Action:        string(request.Action),
+		DepositID:     request.DepositID,
+		Status:        string(request.Status),
+		Attempts:      request.Attempts,
+		NextAttemptAt: request.NextAttemptAt,
+		LastError:     request.LastError,
+		CreatedAt:     request.CreatedAt,
+		UpdatedAt:     request.UpdatedAt,
+	}
+}
+
+// signatureRequestFromURL returns the signature request identified by the
+// deposit_chain, action and deposit_id url parameters
+func signatureRequestFromURL(r *http.Request) store.SignatureRequest {
+	return store.SignatureRequest{
+		DepositChain: store.Blockchain(chi.URLParam(r, "deposit_chain")),
+		Action:       store.Action(chi.URLParam(r, "action")),
+		DepositID:    chi.URLParam(r, "deposit_id"),
+	}
+}
+
+// ListSignatureRequestsHandler lists the most recently updated signature
+// requests, optionally filtered by status. It must only be exposed on the
+// admin port.
+type ListSignatureRequestsHandler struct {
+	Store *store.DB
+}
+
+func (c *ListSignatureRequestsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	status := store.SignatureRequestStatus(r.URL.Query().Get("status"))
+	if status != "" && !store.ValidSignatureRequestStatus(status) {
+		problem.Render(r.Context(), w, InvalidSignatureRequestsQuery)
+		return
+	}
+	limit := uint64(defaultSignatureRequestsLimit)
+	if rawLimit := r.URL.Query().Get("limit"); rawLimit != "" {
+		var err error
+		limit, err = strconv.ParseUint(rawLimit, 10, 64)
+		if err != nil || limit == 0 || limit > maxSignatureRequestsLimit {
+			problem.Render(r.Context(), w, InvalidSignatureRequestsQuery)
+			return
+		}
+	}
+
+	requests, err := c.Store.GetSignatureRequests(r.Context(), status, limit)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	response := SignatureRequestsResponse{SignatureRequests: []SignatureRequestResponse{}}
+	for _, request := range requests {
+		response.SignatureRequests = append(response.SignatureRequests, newSignatureRequestResponse(request))
+	}
+	renderJSON(w, response)
+}
+
+// GetSignatureRequestHandler returns a single signature request including
+// the error of its last failed attempt. It must only be exposed on the
+// admin port.
+type GetSignatureRequestHandler struct {
+	Store *store.DB
+}
+
+func (c *GetSignatureRequestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	request, err := c.Store.GetSignatureRequest(r.Context(), signatureRequestFromURL(r))
+	if err == sql.ErrNoRows {
+		problem.Render(r.Context(), w, SignatureRequestNotFound)
+		return
+	} else if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	renderJSON(w, newSignatureRequestResponse(request))
+}
+
+// RetrySignatureRequestHandler queues a signature request again with no
+// previous attempts, for example after a failure was fixed. It must only be
+// exposed on the admin port.
+type RetrySignatureRequestHandler struct {
+	Store *store.DB
+}
+
+func (c *RetrySignatureRequestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	request := signatureRequestFromURL(r)
+	err := c.Store.RetrySignatureRequest(r.Context(), request, time.Now().Unix())
+	if err == sql.ErrNoRows {
+		problem.Render(r.Context(), w, SignatureRequestNotRetryable)
+		return
+	} else if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	renderUpdatedSignatureRequest(w, r, c.Store, request)
+}
+
+// CancelSignatureRequestHandler cancels a pending signature request. It
+// must only be exposed on the admin port.
+type CancelSignatureRequestHandler struct {
+	Store *store.DB
+}
+
+func (c *CancelSignatureRequestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	request := signatureRequestFromURL(r)
+	err := c.Store.CancelSignatureRequest(r.Context(), request, time.Now().Unix())
+	if err == sql.ErrNoRows {
+		problem.Render(r.Context(), w, SignatureRequestNotCancellable)
+		return
+	} else if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	renderUpdatedSignatureRequest(w, r, c.Store, request)
+}
+
+func renderUpdatedSignatureRequest(w http.ResponseWriter, r *http.Request, db *store.DB, request store.SignatureRequest) {
+	updated, err := db.GetSignatureRequest(r.Context(), request)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+	renderJSON(w, newSignatureRequestResponse(updated))
+}
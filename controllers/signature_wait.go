@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/stellar/starbridge/backend"
+	"github.com/stellar/starbridge/store"
+)
+
+// maxSignatureWait is the maximum time a request can wait for a signature.
+// It must be lower than the timeout of the http server.
+const maxSignatureWait = 8 * time.Second
+
+// signatureWait returns the time the client is willing to wait for the
+// signature, given in seconds in the wait parameter
+func signatureWait(r *http.Request) time.Duration {
+	seconds, err := strconv.ParseUint(r.FormValue("wait"), 10, 64)
+	if err != nil {
+		return 0
+	}
+	wait := time.Duration(seconds) * time.Second
+	if wait > maxSignatureWait {
+		return maxSignatureWait
+	}
+	return wait
+}
+
+// awaitSignatureRequest blocks until the given signature request has been
+// processed or the wait requested by the client has elapsed. It returns
+// true if the request was processed, in which case the handler must be
+// served again without waiting to return the signature.
+func awaitSignatureRequest(
+	r *http.Request,
+	depositStore *store.DB,
+	notifier *backend.SignatureNotifier,
+	request store.SignatureRequest,
+) bool {
+	wait := signatureWait(r)
+	if notifier == nil || wait == 0 {
+		return false
+	}
+
+	processed, release := notifier.Subscribe(request)
+	defer release()
+
+	// The request may have been processed before subscribing
+	pending, err := depositStore.SignatureRequestExists(r.Context(), request)
+	if err != nil {
+		return false
+	}
+	if pending {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-processed:
+		case <-timer.C:
+			return false
+		case <-r.Context().Done():
+			return false
+		}
+	}
+
+	r.Form.Del("wait")
+	r.PostForm.Del("wait")
+	return true
+}
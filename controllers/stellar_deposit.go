@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	"context"
 	"database/sql"
 	"math/big"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/stellar/go/support/log"
 	"github.com/stellar/go/support/render/problem"
 	"github.com/stellar/starbridge/ethereum"
+	"github.com/stellar/starbridge/stellar/txobserver"
 	"github.com/stellar/starbridge/store"
 )
 
@@ -21,6 +24,12 @@ var (
 		Status: http.StatusBadRequest,
 		Detail: "The transaction hash of the Stellar transaction is invalid.",
 	}
+	InvalidOperationIndex = problem.P{
+		Type:   "invalid_operation_index",
+		Title:  "Invalid Operation Index",
+		Status: http.StatusBadRequest,
+		Detail: "The given operation index for the Stellar deposit is invalid.",
+	}
 	StellarTxHashNotFound = problem.P{
 		Type:   "stellar_tx_hash_not_found",
 		Title:  "Stellar Transaction Hash Not Found",
@@ -30,12 +39,32 @@ var (
 )
 
 func getStellarDeposit(depositStore *store.DB, r *http.Request) (store.StellarDeposit, error) {
-	txHash := strings.TrimPrefix(r.PostFormValue("transaction_hash"), "0x")
+	return findStellarDeposit(
+		r.Context(),
+		depositStore,
+		r.PostFormValue("transaction_hash"),
+		r.PostFormValue("operation_index"),
+	)
+}
+
+// findStellarDeposit looks up the deposit identified by the given Stellar
+// transaction hash and operation index in the store. The operation index
+// defaults to 0 if it is empty.
+func findStellarDeposit(ctx context.Context, depositStore *store.DB, txHash, rawOperationIndex string) (store.StellarDeposit, error) {
+	txHash = strings.TrimPrefix(txHash, "0x")
 	if !validTxHash.MatchString(txHash) {
 		return store.StellarDeposit{}, InvalidStellarTxHash
 	}
+	var operationIndex uint64
+	if rawOperationIndex != "" {
+		var err error
+		operationIndex, err = strconv.ParseUint(rawOperationIndex, 10, 32)
+		if err != nil {
+			return store.StellarDeposit{}, InvalidOperationIndex
+		}
+	}
 
-	deposit, err := depositStore.GetStellarDeposit(r.Context(), txHash)
+	deposit, err := depositStore.GetStellarDeposit(ctx, txobserver.DepositID(txHash, uint(operationIndex)))
 	if err == sql.ErrNoRows {
 		return store.StellarDeposit{}, StellarTxHashNotFound
 	}
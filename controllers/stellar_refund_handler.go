@@ -3,6 +3,7 @@ package controllers
 import (
 	"database/sql"
 	"net/http"
+	"time"
 
 	"github.com/stellar/go/clients/horizonclient"
 	"github.com/stellar/go/support/render/problem"
@@ -14,6 +15,7 @@ type StellarRefundHandler struct {
 	StellarClient          *horizonclient.Client
 	Store                  *store.DB
 	StellarRefundValidator backend.StellarRefundValidator
+	SignatureNotifier      *backend.SignatureNotifier
 }
 
 func (c *StellarRefundHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -49,15 +51,21 @@ func (c *StellarRefundHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = c.Store.InsertSignatureRequest(r.Context(), store.SignatureRequest{
+	request := store.SignatureRequest{
 		DepositChain: store.Stellar,
 		Action:       store.Refund,
 		DepositID:    deposit.ID,
-	})
+	}
+	err = c.Store.InsertSignatureRequest(r.Context(), request, time.Now().Unix())
 	if err != nil {
 		problem.Render(r.Context(), w, err)
 		return
 	}
 
+	if awaitSignatureRequest(r, c.Store, c.SignatureNotifier, request) {
+		c.ServeHTTP(w, r)
+		return
+	}
+
 	w.WriteHeader(http.StatusAccepted)
 }
@@ -3,6 +3,7 @@ package controllers
 import (
 	"database/sql"
 	"net/http"
+	"time"
 
 	"github.com/stellar/go/clients/horizonclient"
 	"github.com/stellar/go/support/render/problem"
@@ -11,16 +12,25 @@ import (
 	"github.com/stellar/starbridge/store"
 )
 
+const (
+	// FeeHeader is the response header containing the bridge fee deducted
+	// from the withdrawal in the units of the withdrawn asset
+	FeeHeader = "X-Bridge-Fee"
+	// FeeAssetHeader is the response header containing the asset in which
+	// the bridge fee was charged
+	FeeAssetHeader = "X-Bridge-Fee-Asset"
+)
+
 type StellarWithdrawalHandler struct {
 	StellarClient              *horizonclient.Client
 	Observer                   ethereum.Observer
 	Store                      *store.DB
 	StellarWithdrawalValidator backend.StellarWithdrawalValidator
-	EthereumFinalityBuffer     uint64
+	SignatureNotifier          *backend.SignatureNotifier
 }
 
 func (c *StellarWithdrawalHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	deposit, err := getEthereumDeposit(c.Observer, c.Store, c.EthereumFinalityBuffer, r)
+	deposit, err := getEthereumDeposit(c.Observer, c.Store, r)
 	if err != nil {
 		problem.Render(r.Context(), w, err)
 		return
@@ -41,6 +51,15 @@ func (c *StellarWithdrawalHandler) ServeHTTP(w http.ResponseWriter, r *http.Requ
 			return
 		}
 		if sourceAccount.Sequence < outgoingTransaction.Sequence {
+			fee, err := c.Store.GetBridgeFee(r.Context(), store.Ethereum, deposit.ID)
+			if err != nil && err != sql.ErrNoRows {
+				problem.Render(r.Context(), w, err)
+				return
+			}
+			if err == nil {
+				w.Header().Set(FeeHeader, fee.Amount)
+				w.Header().Set(FeeAssetHeader, fee.Asset)
+			}
 			w.WriteHeader(http.StatusOK)
 			_, _ = w.Write([]byte(outgoingTransaction.Envelope))
 			return
@@ -54,16 +73,22 @@ func (c *StellarWithdrawalHandler) ServeHTTP(w http.ResponseWriter, r *http.Requ
 	}
 
 	// Outgoing Stellar transaction does not exist so create signature request.
-	// Duplicate requests for the same signatures are not allowed but the error is ignored.
-	err = c.Store.InsertSignatureRequest(r.Context(), store.SignatureRequest{
+	// Requests which are already pending are not queued again.
+	request := store.SignatureRequest{
 		DepositChain: store.Ethereum,
 		Action:       store.Withdraw,
 		DepositID:    deposit.ID,
-	})
+	}
+	err = c.Store.InsertSignatureRequest(r.Context(), request, time.Now().Unix())
 	if err != nil {
 		problem.Render(r.Context(), w, err)
 		return
 	}
 
+	if awaitSignatureRequest(r, c.Store, c.SignatureNotifier, request) {
+		c.ServeHTTP(w, r)
+		return
+	}
+
 	w.WriteHeader(http.StatusAccepted)
 }
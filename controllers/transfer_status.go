@@ -0,0 +1,286 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-chi/chi"
+	"github.com/stellar/go/support/render/problem"
+
+	"github.com/stellar/starbridge/ethereum"
+	"github.com/stellar/starbridge/store"
+)
+
+// TransferStatus describes the progress of a bridge transfer
+type TransferStatus string
+
+const (
+	// TransferAwaitingConfirmations indicates that the deposit was found
+	// but it does not have enough confirmations to be processed yet.
+	TransferAwaitingConfirmations TransferStatus = "awaiting_confirmations"
+	// TransferObserved indicates that the deposit was observed by the
+	// validator but no withdrawal or refund was requested yet.
+	TransferObserved TransferStatus = "observed"
+	// TransferSignaturePending indicates that a signature request was
+	// queued and is waiting to be processed by the validator.
+	TransferSignaturePending TransferStatus = "signature_pending"
+	// TransferSigned indicates that the validator has signed the
+	// withdrawal or refund.
+	TransferSigned TransferStatus = "signed"
+	// TransferExecuted indicates that the withdrawal was executed on the
+	// destination chain.
+	TransferExecuted TransferStatus = "executed"
+	// TransferWindowExpired indicates that the withdrawal window has expired
+	// but the refund cannot be authorized yet.
+	TransferWindowExpired TransferStatus = "window_expired"
+	// TransferRefundable indicates that the deposit can be refunded.
+	TransferRefundable TransferStatus = "refundable"
+	// TransferRefunded indicates that the deposit was refunded.
+	TransferRefunded TransferStatus = "refunded"
+)
+
+// TransferStatusResponse is the consolidated view of a bridge transfer
+type TransferStatusResponse struct {
+	DepositChain store.Blockchain `json:"deposit_chain"`
+	DepositID    string           `json:"deposit_id"`
+	Status       TransferStatus   `json:"status"`
+	// Action is the action (withdraw or refund) the status refers to.
+	// It is empty when no action is in progress.
+	Action             store.Action `json:"action,omitempty"`
+	Asset              string       `json:"asset,omitempty"`
+	Amount             string       `json:"amount,omitempty"`
+	Sender             string       `json:"sender,omitempty"`
+	Destination        string       `json:"destination,omitempty"`
+	WithdrawalDeadline int64        `json:"withdrawal_deadline,string,omitempty"`
+	// InvalidReason is set for Stellar deposits with an invalid memo
+	InvalidReason string `json:"invalid_reason,omitempty"`
+}
+
+// StellarDepositStatusHandler reports the status of a Stellar -> Ethereum transfer.
+// The status is derived from ingested data only so polling it does not
+// query the Ethereum node.
+type StellarDepositStatusHandler struct {
+	Store            *store.DB
+	WithdrawalWindow time.Duration
+}
+
+func (c *StellarDepositStatusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	deposit, err := findStellarDeposit(
+		r.Context(),
+		c.Store,
+		chi.URLParam(r, "transaction_hash"),
+		chi.URLParam(r, "operation_index"),
+	)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	withdrawalDeadline := time.Unix(deposit.LedgerTime, 0).Add(c.WithdrawalWindow)
+	response := TransferStatusResponse{
+		DepositChain:       store.Stellar,
+		DepositID:          deposit.ID,
+		Asset:              deposit.Asset,
+		Amount:             deposit.Amount,
+		Sender:             deposit.Sender,
+		Destination:        deposit.Destination,
+		WithdrawalDeadline: withdrawalDeadline.Unix(),
+		InvalidReason:      deposit.InvalidReason,
+	}
+	response.Status, response.Action, err = c.status(r.Context(), deposit, withdrawalDeadline)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	renderJSON(w, response)
+}
+
+func (c *StellarDepositStatusHandler) status(ctx context.Context, deposit store.StellarDeposit, withdrawalDeadline time.Time) (TransferStatus, store.Action, error) {
+	// Withdrawals of Stellar deposits are executed on Ethereum
+	_, err := c.Store.GetEthereumWithdrawal(ctx, common.HexToHash(deposit.ID).String())
+	if err == nil {
+		return TransferExecuted, store.Withdraw, nil
+	} else if err != sql.ErrNoRows {
+		return "", "", err
+	}
+
+	// Refunds of Stellar deposits are executed on Stellar
+	refunded, err := c.Store.HistoryStellarTransactionExists(ctx, deposit.ID)
+	if err != nil {
+		return "", "", err
+	}
+	if refunded {
+		return TransferRefunded, store.Refund, nil
+	}
+
+	status, err := signatureStatus(ctx, c.Store, store.Stellar, store.Refund, deposit.ID)
+	if err != nil || status != "" {
+		return status, store.Refund, err
+	}
+
+	// Deposits without a valid Ethereum recipient can never be withdrawn
+	if deposit.InvalidReason != "" {
+		return TransferRefundable, "", nil
+	}
+
+	// Withdraw events are ingested once they are final so a withdrawal can
+	// no longer be executed when the last ingested block is past the deadline
+	lastBlock, err := c.Store.GetLastEthereumBlock(ctx)
+	if err != nil && err != sql.ErrNoRows {
+		return "", "", err
+	}
+	if err == nil && time.Unix(lastBlock.Time, 0).After(withdrawalDeadline) {
+		return TransferRefundable, "", nil
+	}
+
+	lastLedgerCloseTime, err := c.Store.GetLastLedgerCloseTime(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	if lastLedgerCloseTime.After(withdrawalDeadline) {
+		return TransferWindowExpired, "", nil
+	}
+
+	status, err = signatureStatus(ctx, c.Store, store.Stellar, store.Withdraw, deposit.ID)
+	if err != nil || status != "" {
+		return status, store.Withdraw, err
+	}
+
+	return TransferObserved, "", nil
+}
+
+// EthereumDepositStatusHandler reports the status of an Ethereum -> Stellar transfer.
+// Apart from looking up deposits which were not ingested yet the status is
+// derived from ingested data only.
+type EthereumDepositStatusHandler struct {
+	Observer         ethereum.Observer
+	Store            *store.DB
+	WithdrawalWindow time.Duration
+}
+
+func (c *EthereumDepositStatusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	txHash := chi.URLParam(r, "transaction_hash")
+	logIndex := chi.URLParam(r, "log_index")
+	// The status is read only so deposits which were not ingested yet are
+	// not persisted
+	deposit, _, err := lookupEthereumDeposit(
+		r.Context(),
+		c.Observer,
+		c.Store,
+		txHash,
+		logIndex,
+	)
+	if isProblem(err, EthereumTxRequiresMoreConfirmations) {
+		// lookupEthereumDeposit has already validated the log index
+		parsed, _ := parseLogIndex(logIndex)
+		renderJSON(w, TransferStatusResponse{
+			DepositChain: store.Ethereum,
+			DepositID:    ethereum.DepositID(txHash, parsed),
+			Status:       TransferAwaitingConfirmations,
+		})
+		return
+	} else if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	withdrawalDeadline := time.Unix(deposit.BlockTime, 0).Add(c.WithdrawalWindow)
+	response := TransferStatusResponse{
+		DepositChain:       store.Ethereum,
+		DepositID:          deposit.ID,
+		Asset:              deposit.Token,
+		Amount:             deposit.Amount,
+		Sender:             deposit.Sender,
+		Destination:        deposit.Destination,
+		WithdrawalDeadline: withdrawalDeadline.Unix(),
+	}
+	response.Status, response.Action, err = c.status(r.Context(), deposit, withdrawalDeadline)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	renderJSON(w, response)
+}
+
+func (c *EthereumDepositStatusHandler) status(ctx context.Context, deposit store.EthereumDeposit, withdrawalDeadline time.Time) (TransferStatus, store.Action, error) {
+	// Withdrawals of Ethereum deposits are executed on Stellar
+	executed, err := c.Store.HistoryStellarTransactionExists(ctx, deposit.ID)
+	if err != nil {
+		return "", "", err
+	}
+	if executed {
+		return TransferExecuted, store.Withdraw, nil
+	}
+
+	// Refunds of Ethereum deposits are executed on Ethereum
+	_, err = c.Store.GetEthereumWithdrawal(ctx, common.HexToHash(deposit.ID).String())
+	if err == nil {
+		return TransferRefunded, store.Refund, nil
+	} else if err != sql.ErrNoRows {
+		return "", "", err
+	}
+
+	status, err := signatureStatus(ctx, c.Store, store.Ethereum, store.Refund, deposit.ID)
+	if err != nil || status != "" {
+		return status, store.Refund, err
+	}
+
+	// Withdrawal transactions are bounded by the deadline so they can no
+	// longer be executed once a later ledger was ingested
+	lastLedgerCloseTime, err := c.Store.GetLastLedgerCloseTime(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	if lastLedgerCloseTime.After(withdrawalDeadline) {
+		return TransferRefundable, "", nil
+	}
+
+	status, err = signatureStatus(ctx, c.Store, store.Ethereum, store.Withdraw, deposit.ID)
+	if err != nil || status != "" {
+		return status, store.Withdraw, err
+	}
+
+	return TransferObserved, "", nil
+}
+
+// signatureStatus returns TransferSigned if the validator has signed the given
+// action, TransferSignaturePending if a signature request is pending and an
+// empty status otherwise.
+func signatureStatus(ctx context.Context, depositStore *store.DB, chain store.Blockchain, action store.Action, depositID string) (TransferStatus, error) {
+	var err error
+	if chain == store.Stellar && action == store.Withdraw ||
+		chain == store.Ethereum && action == store.Refund {
+		_, err = getEthereumSignature(ctx, depositStore, action, depositID)
+	} else {
+		_, err = depositStore.GetOutgoingStellarTransaction(ctx, action, depositID)
+	}
+	if err == nil {
+		return TransferSigned, nil
+	} else if err != sql.ErrNoRows {
+		return "", err
+	}
+
+	pending, err := depositStore.SignatureRequestExists(ctx, store.SignatureRequest{
+		DepositChain: chain,
+		Action:       action,
+		DepositID:    depositID,
+	})
+	if err != nil {
+		return "", err
+	}
+	if pending {
+		return TransferSignaturePending, nil
+	}
+	return "", nil
+}
+
+// isProblem returns true if err is the given problem
+func isProblem(err error, p problem.P) bool {
+	errProblem, ok := err.(problem.P)
+	return ok && errProblem.Type == p.Type
+}
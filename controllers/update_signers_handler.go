@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/starbridge/backend"
+	"github.com/stellar/starbridge/ethereum"
+	"github.com/stellar/starbridge/stellar/signer"
+	"github.com/stellar/starbridge/stellar/txbuilder"
+	"github.com/stellar/starbridge/store"
+)
+
+type UpdateSignersSignatureResponse struct {
+	Address      string   `json:"address"`
+	Signature    string   `json:"signature"`
+	Version      uint64   `json:"version,string"`
+	Signers      []string `json:"signers"`
+	MinThreshold uint8    `json:"min_threshold"`
+}
+
+type StellarSetSignersResponse struct {
+	Address      string   `json:"address"`
+	Envelope     string   `json:"envelope"`
+	Signers      []string `json:"signers"`
+	MinThreshold uint8    `json:"min_threshold"`
+	Sequence     int64    `json:"sequence,string"`
+	Expiration   int64    `json:"expiration,string"`
+}
+
+// UpdateSignersHandler signs updateSigners requests for the bridge smart
+// contract. It must only be exposed on the admin port.
+type UpdateSignersHandler struct {
+	Store          *store.DB
+	EthereumSigner ethereum.Signer
+}
+
+func (c *UpdateSignersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	signers, minThreshold, err := ParseUpdateSignersRequest(
+		r.PostFormValue("signers"),
+		r.PostFormValue("min_threshold"),
+	)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	ethSigner, err := backend.LatestEthereumSigner(r.Context(), c.Store, c.EthereumSigner)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	response, err := SignUpdateSigners(ethSigner, signers, minThreshold)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	renderJSON(w, response)
+}
+
+// ParseUpdateSignersRequest parses a comma separated list of ethereum
+// addresses and the decimal encoded minimum threshold
+func ParseUpdateSignersRequest(signers, minThreshold string) ([]common.Address, uint8, error) {
+	var addresses []common.Address
+	for _, signer := range splitList(signers) {
+		if !common.IsHexAddress(signer) {
+			return nil, 0, backend.InvalidSigners
+		}
+		addresses = append(addresses, common.HexToAddress(signer))
+	}
+	threshold, err := strconv.ParseUint(minThreshold, 10, 8)
+	if err != nil {
+		return nil, 0, backend.InvalidMinThreshold
+	}
+	return addresses, uint8(threshold), nil
+}
+
+// SignUpdateSigners validates and signs the given validator set update
+func SignUpdateSigners(signer ethereum.Signer, signers []common.Address, minThreshold uint8) (UpdateSignersSignatureResponse, error) {
+	if err := backend.ValidateEthereumSigners(signers, minThreshold); err != nil {
+		return UpdateSignersSignatureResponse{}, err
+	}
+	sig, err := signer.SignUpdateSigners(signers, minThreshold)
+	if err != nil {
+		return UpdateSignersSignatureResponse{}, err
+	}
+	response := UpdateSignersSignatureResponse{
+		Address:      signer.Address().String(),
+		Signature:    hex.EncodeToString(sig),
+		Version:      signer.Version(),
+		MinThreshold: minThreshold,
+	}
+	for _, address := range signers {
+		response.Signers = append(response.Signers, address.String())
+	}
+	return response, nil
+}
+
+// StellarSetSignersHandler signs transactions which replace the signers of
+// the bridge account. The sequence number of the transaction is given in the
+// request so all validators sign the same transaction. It must only be
+// exposed on the admin port.
+type StellarSetSignersHandler struct {
+	StellarClient  *horizonclient.Client
+	StellarBuilder *txbuilder.Builder
+	StellarSigner  *signer.Signer
+}
+
+func (c *StellarSetSignersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	signers := splitList(r.PostFormValue("signers"))
+	minThreshold, err := strconv.ParseUint(r.PostFormValue("min_threshold"), 10, 8)
+	if err != nil {
+		problem.Render(r.Context(), w, backend.InvalidMinThreshold)
+		return
+	}
+	expiration, err := strconv.ParseInt(r.PostFormValue("expiration"), 10, 64)
+	if err != nil {
+		problem.Render(r.Context(), w, backend.InvalidGovernanceExpiration)
+		return
+	}
+	sequence, err := strconv.ParseInt(r.PostFormValue("sequence"), 10, 64)
+	if err != nil {
+		problem.Render(r.Context(), w, backend.InvalidStellarSetSignersSequence)
+		return
+	}
+
+	err = backend.ValidateStellarSetSignersRequest(signers, uint8(minThreshold), expiration, time.Now())
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	bridgeAccount, err := c.StellarClient.AccountDetail(horizonclient.AccountRequest{
+		AccountID: c.StellarBuilder.BridgeAccount,
+	})
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+	// A transaction with a sequence number which was already consumed can
+	// never be submitted
+	if sequence <= bridgeAccount.Sequence {
+		problem.Render(r.Context(), w, backend.InvalidStellarSetSignersSequence)
+		return
+	}
+	var currentSigners []string
+	for _, s := range bridgeAccount.Signers {
+		if s.Weight > 0 && s.Key != c.StellarBuilder.BridgeAccount {
+			currentSigners = append(currentSigners, s.Key)
+		}
+	}
+
+	tx, err := c.StellarBuilder.BuildSetSignersTransaction(
+		currentSigners,
+		signers,
+		uint8(minThreshold),
+		sequence,
+		expiration,
+	)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	signature, err := c.StellarSigner.Sign(tx)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+	tx.V1.Signatures = append(tx.Signatures(), signature)
+
+	txBase64, err := xdr.MarshalBase64(tx)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	renderJSON(w, StellarSetSignersResponse{
+		Address:      c.StellarSigner.Signer.Address(),
+		Envelope:     txBase64,
+		Signers:      signers,
+		MinThreshold: uint8(minThreshold),
+		Sequence:     sequence,
+		Expiration:   expiration,
+	})
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
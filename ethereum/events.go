@@ -0,0 +1,230 @@
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/starbridge/solidity-go"
+)
+
+var bridgeEventIDs = mustBridgeEventIDs()
+
+func mustBridgeEventIDs() map[string]common.Hash {
+	parsed, err := solidity.BridgeMetaData.GetAbi()
+	if err != nil {
+		log.Fatalf("invalid bridge abi %v", err)
+	}
+	ids := map[string]common.Hash{}
+	for name, event := range parsed.Events {
+		ids[name] = event.ID
+	}
+	return ids
+}
+
+// Withdrawal is a withdrawal (or refund) executed on the bridge smart contract
+type Withdrawal struct {
+	// ID is the id of the bridge transfer which was withdrawn
+	ID common.Hash
+	// Token is the address (0x0 in the case that eth was withdrawn)
+	// of the tokens which were withdrawn from the bridge
+	Token common.Address
+	// Recipient is the address which received the tokens
+	Recipient common.Address
+	// Amount is the amount of tokens which were withdrawn
+	Amount *big.Int
+	// TxHash is the hash of the transaction containing the withdrawal
+	TxHash common.Hash
+	// LogIndex is the log index within the ethereum block of the withdraw event
+	LogIndex uint
+	// BlockNumber is the sequence number of the block containing the
+	// withdrawal transaction
+	BlockNumber uint64
+}
+
+// SetPaused is emitted whenever the paused state of the bridge changes
+type SetPaused struct {
+	// Value is the bitmask describing whether deposits / withdrawals are paused
+	Value uint8
+	// TxHash is the hash of the transaction which changed the paused state
+	TxHash common.Hash
+	// LogIndex is the log index within the ethereum block of the event
+	LogIndex uint
+	// BlockNumber is the sequence number of the block containing the event
+	BlockNumber uint64
+}
+
+// RegisteredStellarAsset is emitted whenever an ERC20 token is created
+// to represent a Stellar asset
+type RegisteredStellarAsset struct {
+	// Token is the address of the ERC20 token
+	Token common.Address
+	// TxHash is the hash of the transaction which created the token
+	TxHash common.Hash
+	// LogIndex is the log index within the ethereum block of the event
+	LogIndex uint
+	// BlockNumber is the sequence number of the block containing the event
+	BlockNumber uint64
+}
+
+// RegisteredSigners is emitted whenever the validator set configuration of the
+// bridge is modified
+type RegisteredSigners struct {
+	// Version is the version of the validator set configuration
+	Version *big.Int
+	// Signers is the list of validator addresses
+	Signers []common.Address
+	// MinThreshold is the minimum amount of signers needed to approve a
+	// bridge transaction
+	MinThreshold uint8
+	// TxHash is the hash of the transaction which modified the validator set
+	TxHash common.Hash
+	// LogIndex is the log index within the ethereum block of the event
+	LogIndex uint
+	// BlockNumber is the sequence number of the block containing the event
+	BlockNumber uint64
+}
+
+// BridgeEvents contains all events emitted by the bridge smart contract
+// within a range of blocks
+type BridgeEvents struct {
+	Deposits                []Deposit
+	Withdrawals             []Withdrawal
+	SetPaused               []SetPaused
+	RegisteredStellarAssets []RegisteredStellarAsset
+	RegisteredSigners       []RegisteredSigners
+	// BlockHashes are the hashes of the blocks containing the events, keyed
+	// by block number. They are used to check that all events are part of
+	// the canonical chain.
+	BlockHashes map[uint64]common.Hash
+}
+
+// GetBridgeEvents returns all events emitted by the bridge smart contract
+// between fromBlock and toBlock (inclusive)
+func (o Observer) GetBridgeEvents(ctx context.Context, fromBlock, toBlock uint64) (BridgeEvents, error) {
+	events, err := o.call(ctx, "get_bridge_events", func(ctx context.Context, n node) (interface{}, error) {
+		return o.getBridgeEvents(ctx, n, fromBlock, toBlock)
+	})
+	if err != nil {
+		return BridgeEvents{}, err
+	}
+	return events.(BridgeEvents), nil
+}
+
+func (o Observer) getBridgeEvents(ctx context.Context, n node, fromBlock, toBlock uint64) (BridgeEvents, error) {
+	logs, err := n.client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+		Addresses: []common.Address{o.bridgeAddress},
+	})
+	if err != nil {
+		return BridgeEvents{}, err
+	}
+
+	events := BridgeEvents{BlockHashes: map[uint64]common.Hash{}}
+	blockTimes := map[common.Hash]time.Time{}
+	for _, eventLog := range logs {
+		if eventLog.Removed || len(eventLog.Topics) == 0 {
+			continue
+		}
+		// The node may have switched to another fork while collecting
+		// the logs
+		if hash, ok := events.BlockHashes[eventLog.BlockNumber]; ok && hash != eventLog.BlockHash {
+			return BridgeEvents{}, ErrBlockNotCanonical
+		}
+		events.BlockHashes[eventLog.BlockNumber] = eventLog.BlockHash
+		switch eventLog.Topics[0] {
+		case bridgeEventIDs["Deposit"]:
+			deposit, err := o.parseDeposit(ctx, n, eventLog, blockTimes)
+			if err != nil {
+				return BridgeEvents{}, err
+			}
+			events.Deposits = append(events.Deposits, deposit)
+		case bridgeEventIDs["Withdraw"]:
+			event, err := o.filterer.ParseWithdraw(eventLog)
+			if err != nil {
+				return BridgeEvents{}, err
+			}
+			events.Withdrawals = append(events.Withdrawals, Withdrawal{
+				ID:          event.Id,
+				Token:       event.Token,
+				Recipient:   event.Recipient,
+				Amount:      event.Amount,
+				TxHash:      eventLog.TxHash,
+				LogIndex:    eventLog.Index,
+				BlockNumber: eventLog.BlockNumber,
+			})
+		case bridgeEventIDs["SetPaused"]:
+			event, err := o.filterer.ParseSetPaused(eventLog)
+			if err != nil {
+				return BridgeEvents{}, err
+			}
+			events.SetPaused = append(events.SetPaused, SetPaused{
+				Value:       event.Value,
+				TxHash:      eventLog.TxHash,
+				LogIndex:    eventLog.Index,
+				BlockNumber: eventLog.BlockNumber,
+			})
+		case bridgeEventIDs["RegisterStellarAsset"]:
+			event, err := o.filterer.ParseRegisterStellarAsset(eventLog)
+			if err != nil {
+				return BridgeEvents{}, err
+			}
+			events.RegisteredStellarAssets = append(events.RegisteredStellarAssets, RegisteredStellarAsset{
+				Token:       event.Asset,
+				TxHash:      eventLog.TxHash,
+				LogIndex:    eventLog.Index,
+				BlockNumber: eventLog.BlockNumber,
+			})
+		case bridgeEventIDs["RegisterSigners"]:
+			event, err := o.filterer.ParseRegisterSigners(eventLog)
+			if err != nil {
+				return BridgeEvents{}, err
+			}
+			events.RegisteredSigners = append(events.RegisteredSigners, RegisteredSigners{
+				Version:      event.Version,
+				Signers:      event.Signers,
+				MinThreshold: event.MinThreshold,
+				TxHash:       eventLog.TxHash,
+				LogIndex:     eventLog.Index,
+				BlockNumber:  eventLog.BlockNumber,
+			})
+		}
+	}
+
+	return events, nil
+}
+
+func (o Observer) parseDeposit(ctx context.Context, n node, eventLog types.Log, blockTimes map[common.Hash]time.Time) (Deposit, error) {
+	event, err := o.filterer.ParseDeposit(eventLog)
+	if err != nil {
+		return Deposit{}, err
+	}
+
+	blockTime, ok := blockTimes[eventLog.BlockHash]
+	if !ok {
+		header, err := n.client.HeaderByHash(ctx, eventLog.BlockHash)
+		if err != nil {
+			return Deposit{}, err
+		}
+		blockTime = time.Unix(int64(header.Time), 0)
+		blockTimes[eventLog.BlockHash] = blockTime
+	}
+
+	return Deposit{
+		Token:       event.Token,
+		Sender:      event.Sender,
+		Destination: event.Destination,
+		Amount:      event.Amount,
+		TxHash:      eventLog.TxHash,
+		LogIndex:    eventLog.Index,
+		BlockNumber: eventLog.BlockNumber,
+		BlockHash:   eventLog.BlockHash,
+		Time:        blockTime,
+	}, nil
+}
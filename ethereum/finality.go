@@ -0,0 +1,95 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// FinalityMode determines how the Observer decides whether an ethereum
+// block is final
+type FinalityMode string
+
+const (
+	// FixedDepthFinality considers a block final once it is buried under
+	// a fixed number of blocks
+	FixedDepthFinality FinalityMode = "fixed_depth"
+	// SafeFinality considers a block final once it is an ancestor of the
+	// block returned by the "safe" block tag
+	SafeFinality FinalityMode = "safe"
+	// FinalizedFinality considers a block final once it is an ancestor of
+	// the block returned by the "finalized" block tag
+	FinalizedFinality FinalityMode = "finalized"
+)
+
+// ErrNoFinalBlock is returned by GetLatestFinalBlock when the ethereum node
+// does not have any block which is considered final
+var ErrNoFinalBlock = fmt.Errorf("no final block available")
+
+// Finality configures how the Observer determines finality of ethereum blocks
+type Finality struct {
+	// Mode is the finality mode
+	Mode FinalityMode
+	// Depth is the number of blocks which must follow a block for it to be
+	// considered final. It is only used in FixedDepthFinality mode.
+	Depth uint64
+}
+
+// Validate returns an error if the finality configuration is invalid
+func (f Finality) Validate() error {
+	switch f.Mode {
+	case FixedDepthFinality:
+		// A block at the tip of the chain can always be reorged
+		if f.Depth == 0 {
+			return fmt.Errorf("finality depth must be set in %s mode", f.Mode)
+		}
+		return nil
+	case SafeFinality, FinalizedFinality:
+		if f.Depth != 0 {
+			return fmt.Errorf("finality depth cannot be set in %s mode", f.Mode)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid finality mode: %q", f.Mode)
+	}
+}
+
+// GetLatestFinalBlock returns the most recent ethereum block which is
+// considered final according to the finality configuration of the Observer.
+// ErrNoFinalBlock is returned if no block is final yet.
+func (o Observer) GetLatestFinalBlock(ctx context.Context) (Block, error) {
+	if o.finality.Mode == FixedDepthFinality {
+		latest, err := o.GetLatestBlock(ctx)
+		if err != nil {
+			return Block{}, err
+		}
+		if latest.Number <= o.finality.Depth {
+			return Block{}, ErrNoFinalBlock
+		}
+		return o.GetBlockByNumber(ctx, latest.Number-o.finality.Depth)
+	}
+
+	number, err := o.quorumBlockNumber(ctx, "get_latest_final_block", func(ctx context.Context, n node) (*types.Header, error) {
+		// ethclient does not support the safe and finalized block tags
+		// so the block is fetched using the raw rpc client
+		var header *types.Header
+		err := n.rpcClient.CallContext(ctx, &header, "eth_getBlockByNumber", string(o.finality.Mode), false)
+		if err != nil {
+			return nil, err
+		}
+		if header == nil {
+			return nil, ErrNoFinalBlock
+		}
+		return header, nil
+	})
+	if err != nil {
+		return Block{}, err
+	}
+	return o.GetBlockByNumber(ctx, number)
+}
+
+// Finality returns the finality configuration of the Observer
+func (o Observer) Finality() Finality {
+	return o.finality
+}
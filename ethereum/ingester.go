@@ -0,0 +1,314 @@
+package ethereum
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stellar/go/support/errors"
+	slog "github.com/stellar/go/support/log"
+
+	"github.com/stellar/starbridge/store"
+)
+
+// ingestionBatchSize is the maximum number of blocks which
+// are ingested in a single database transaction
+const ingestionBatchSize = 1000
+
+// Ingester follows the ethereum blockchain and indexes all the events
+// emitted by the bridge smart contract. Only blocks which are older than
+// the latest final block are ingested.
+type Ingester struct {
+	observer   Observer
+	store      *store.DB
+	startBlock uint64
+	log        *slog.Entry
+
+	finalBlockGauge prometheus.Gauge
+
+	// nextBlock is the sequence number of the next block to ingest
+	nextBlock uint64
+	// parentHash is the hash of the last ingested block. It is
+	// empty if no blocks have been ingested yet.
+	parentHash common.Hash
+}
+
+// NewIngester constructs a new Ingester instance which resumes from the
+// last ingested block or from startBlock if no blocks were ingested yet.
+func NewIngester(
+	observer Observer,
+	store *store.DB,
+	startBlock uint64,
+) *Ingester {
+	i := &Ingester{
+		observer:   observer,
+		store:      store,
+		startBlock: startBlock,
+		log:        slog.DefaultLogger.WithField("service", "ethereum_ingester"),
+		nextBlock:  startBlock,
+		finalBlockGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "starbridge", Subsystem: "ethereum", Name: "latest_final_block",
+			Help: "Sequence number of the latest final ethereum block",
+		}),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	lastBlock, err := i.store.GetLastEthereumBlock(ctx)
+	if err == nil {
+		i.nextBlock = lastBlock.Number + 1
+		i.parentHash = common.HexToHash(lastBlock.Hash)
+	} else if err != sql.ErrNoRows {
+		i.log.Fatalf("Unable to load last ethereum block from db: %v", err)
+	}
+
+	return i
+}
+
+// RegisterMetrics registers the ingester metrics and the finality
+// configuration of the observer in the given registry
+func (i *Ingester) RegisterMetrics(registry *prometheus.Registry) {
+	finality := i.observer.Finality()
+	finalityGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "starbridge", Subsystem: "ethereum", Name: "finality_depth",
+			Help: "Number of confirmations required for a block to be final, labeled by finality mode",
+		},
+		[]string{"mode"},
+	)
+	finalityGauge.WithLabelValues(string(finality.Mode)).Set(float64(finality.Depth))
+
+	registry.MustRegister(finalityGauge, i.finalBlockGauge)
+}
+
+// ProcessNewBlocks ingests all blocks which are final until it reaches
+// the latest block or encounters an error.
+func (i *Ingester) ProcessNewBlocks(ctx context.Context) {
+	for ctx.Err() == nil {
+		finalBlock, err := i.observer.GetLatestFinalBlock(ctx)
+		if err == ErrNoFinalBlock {
+			return
+		} else if err != nil {
+			i.log.WithField("error", err).Error("Error getting latest final block")
+			return
+		}
+		i.finalBlockGauge.Set(float64(finalBlock.Number))
+
+		final := finalBlock.Number
+		if i.nextBlock > final {
+			return
+		}
+
+		to := i.nextBlock + ingestionBatchSize - 1
+		if to > final {
+			to = final
+		}
+		if err = i.ingestBlocks(ctx, i.nextBlock, to); err != nil {
+			i.log.WithFields(slog.F{"error": err, "from": i.nextBlock, "to": to}).
+				Error("Error ingesting blocks")
+			return
+		}
+	}
+}
+
+func (i *Ingester) ingestBlocks(ctx context.Context, from, to uint64) error {
+	first, err := i.observer.GetBlockByNumber(ctx, from)
+	if err != nil {
+		return errors.Wrap(err, "error getting first block")
+	}
+	if i.parentHash != (common.Hash{}) && first.ParentHash != i.parentHash {
+		i.log.WithFields(slog.F{"block": from, "parent_hash": i.parentHash.String()}).
+			Warn("Chain reorganization detected")
+		return i.rollback(ctx)
+	}
+
+	events, err := i.observer.GetBridgeEvents(ctx, from, to)
+	if err != nil {
+		return errors.Wrap(err, "error getting bridge events")
+	}
+	last, err := i.observer.GetBlockByNumber(ctx, to)
+	if err != nil {
+		return errors.Wrap(err, "error getting last block")
+	}
+	if err = i.verifyEventBlocks(ctx, events, last); err != nil {
+		return err
+	}
+
+	err = i.store.Session.Begin()
+	if err != nil {
+		return errors.Wrap(err, "error starting a transaction")
+	}
+	defer func() {
+		// explicitly ignore return value to make the linter happy
+		_ = i.store.Session.Rollback()
+	}()
+
+	if err = i.ingestEvents(ctx, events); err != nil {
+		return err
+	}
+
+	err = i.store.InsertEthereumBlock(ctx, store.EthereumBlock{
+		Number: last.Number,
+		Hash:   last.Hash.String(),
+		Time:   last.Time.Unix(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "error inserting ethereum block")
+	}
+
+	if err = i.store.Session.Commit(); err != nil {
+		return errors.Wrap(err, "error commiting a transaction")
+	}
+
+	i.nextBlock = to + 1
+	i.parentHash = last.Hash
+	i.log.WithFields(slog.F{"from": from, "to": to}).Info("Processed blocks")
+	return nil
+}
+
+// verifyEventBlocks checks that every block containing events is part of the
+// canonical chain. Otherwise a reorg within the batch would only be detected
+// at the start of the next batch, after the reorged events were ingested.
+func (i *Ingester) verifyEventBlocks(ctx context.Context, events BridgeEvents, last Block) error {
+	for number, hash := range events.BlockHashes {
+		block := last
+		if number != last.Number {
+			var err error
+			block, err = i.observer.GetBlockByNumber(ctx, number)
+			if err != nil {
+				return errors.Wrap(err, "error getting event block")
+			}
+		}
+		if block.Hash != hash {
+			i.log.WithFields(slog.F{"block": number, "hash": hash.String()}).
+				Warn("Events of a non canonical block received")
+			return ErrBlockNotCanonical
+		}
+	}
+	return nil
+}
+
+func (i *Ingester) ingestEvents(ctx context.Context, events BridgeEvents) error {
+	for _, deposit := range events.Deposits {
+		err := i.store.InsertEthereumDeposit(ctx, store.EthereumDeposit{
+			ID:          DepositID(deposit.TxHash.String(), deposit.LogIndex),
+			Token:       deposit.Token.String(),
+			Sender:      deposit.Sender.String(),
+			Destination: deposit.Destination.String(),
+			Amount:      deposit.Amount.String(),
+			Hash:        deposit.TxHash.String(),
+			LogIndex:    deposit.LogIndex,
+			BlockNumber: deposit.BlockNumber,
+			BlockHash:   deposit.BlockHash.String(),
+			BlockTime:   deposit.Time.Unix(),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "error inserting ethereum deposit: %s", deposit.TxHash.String())
+		}
+	}
+
+	for _, withdrawal := range events.Withdrawals {
+		err := i.store.InsertEthereumWithdrawal(ctx, store.EthereumWithdrawal{
+			ID:          withdrawal.ID.String(),
+			Token:       withdrawal.Token.String(),
+			Recipient:   withdrawal.Recipient.String(),
+			Amount:      withdrawal.Amount.String(),
+			Hash:        withdrawal.TxHash.String(),
+			LogIndex:    withdrawal.LogIndex,
+			BlockNumber: withdrawal.BlockNumber,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "error inserting ethereum withdrawal: %s", withdrawal.TxHash.String())
+		}
+	}
+
+	for _, event := range events.SetPaused {
+		err := i.store.InsertEthereumSetPaused(ctx, store.EthereumSetPaused{
+			Value:       event.Value,
+			Hash:        event.TxHash.String(),
+			LogIndex:    event.LogIndex,
+			BlockNumber: event.BlockNumber,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "error inserting set paused event: %s", event.TxHash.String())
+		}
+	}
+
+	for _, event := range events.RegisteredStellarAssets {
+		err := i.store.InsertEthereumStellarAsset(ctx, store.EthereumStellarAsset{
+			Token:       event.Token.String(),
+			Hash:        event.TxHash.String(),
+			LogIndex:    event.LogIndex,
+			BlockNumber: event.BlockNumber,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "error inserting stellar asset: %s", event.TxHash.String())
+		}
+	}
+
+	for _, event := range events.RegisteredSigners {
+		signers := make(pq.StringArray, len(event.Signers))
+		for j, signer := range event.Signers {
+			signers[j] = signer.String()
+		}
+		err := i.store.InsertEthereumSigners(ctx, store.EthereumSigners{
+			Version:      event.Version.Uint64(),
+			Signers:      signers,
+			MinThreshold: event.MinThreshold,
+			Hash:         event.TxHash.String(),
+			LogIndex:     event.LogIndex,
+			BlockNumber:  event.BlockNumber,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "error inserting signers: %s", event.TxHash.String())
+		}
+	}
+
+	return nil
+}
+
+// rollback finds the most recent ingested block which is still part of the
+// canonical chain and removes all blocks and events ingested after it.
+func (i *Ingester) rollback(ctx context.Context) error {
+	blocks, err := i.store.GetEthereumBlocks(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error getting ingested blocks")
+	}
+
+	nextBlock, parentHash := i.startBlock, common.Hash{}
+	for _, block := range blocks {
+		canonical, err := i.observer.GetBlockByNumber(ctx, block.Number)
+		if err != nil {
+			return errors.Wrap(err, "error getting canonical block")
+		}
+		if canonical.Hash == common.HexToHash(block.Hash) {
+			nextBlock, parentHash = block.Number+1, canonical.Hash
+			break
+		}
+	}
+
+	err = i.store.Session.Begin()
+	if err != nil {
+		return errors.Wrap(err, "error starting a transaction")
+	}
+	defer func() {
+		// explicitly ignore return value to make the linter happy
+		_ = i.store.Session.Rollback()
+	}()
+
+	if err = i.store.DeleteEthereumEventsFrom(ctx, nextBlock); err != nil {
+		return errors.Wrap(err, "error removing reorganized events")
+	}
+
+	if err = i.store.Session.Commit(); err != nil {
+		return errors.Wrap(err, "error commiting a transaction")
+	}
+
+	i.log.WithField("block", nextBlock).Warn("Rolled back ingestion")
+	i.nextBlock = nextBlock
+	i.parentHash = parentHash
+	return nil
+}
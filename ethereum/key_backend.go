@@ -0,0 +1,65 @@
+package ethereum
+
+import (
+	"crypto/ecdsa"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stellar/go/support/errors"
+)
+
+// PrivateKeyBackend is a KeyBackend which keeps the private key in memory
+type PrivateKeyBackend struct {
+	privateKey *ecdsa.PrivateKey
+	address    common.Address
+}
+
+// NewPrivateKeyBackend constructs a PrivateKeyBackend from a hex encoded
+// private key
+func NewPrivateKeyBackend(privateKey string) (PrivateKeyBackend, error) {
+	parsed, err := crypto.HexToECDSA(privateKey)
+	if err != nil {
+		return PrivateKeyBackend{}, err
+	}
+	return newPrivateKeyBackend(parsed), nil
+}
+
+// NewKeystoreBackend constructs a PrivateKeyBackend from an encrypted geth
+// keystore file. The password used to decrypt the keystore is read from
+// passwordPath.
+func NewKeystoreBackend(keystorePath, passwordPath string) (PrivateKeyBackend, error) {
+	keyJSON, err := ioutil.ReadFile(keystorePath)
+	if err != nil {
+		return PrivateKeyBackend{}, errors.Wrap(err, "error reading keystore file")
+	}
+	password, err := ioutil.ReadFile(passwordPath)
+	if err != nil {
+		return PrivateKeyBackend{}, errors.Wrap(err, "error reading password file")
+	}
+
+	key, err := keystore.DecryptKey(keyJSON, strings.TrimRight(string(password), "\r\n"))
+	if err != nil {
+		return PrivateKeyBackend{}, errors.Wrap(err, "error decrypting keystore file")
+	}
+	return newPrivateKeyBackend(key.PrivateKey), nil
+}
+
+func newPrivateKeyBackend(privateKey *ecdsa.PrivateKey) PrivateKeyBackend {
+	return PrivateKeyBackend{
+		privateKey: privateKey,
+		address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+	}
+}
+
+// Address returns the ethereum address corresponding to the private key
+func (b PrivateKeyBackend) Address() common.Address {
+	return b.address
+}
+
+// SignHash signs the given digest with the private key
+func (b PrivateKeyBackend) SignHash(hash []byte) ([]byte, error) {
+	return crypto.Sign(hash, b.privateKey)
+}
@@ -19,6 +19,10 @@ import (
 	"github.com/stellar/starbridge/solidity-go"
 )
 
+// maxVerifyBlockDepth is the maximum number of parent hashes walked by
+// VerifyBlock
+const maxVerifyBlockDepth = 128
+
 var (
 	// ErrLogNotFound is returned by GetDeposit when the log
 	// with the given block index cannot be found
@@ -32,6 +36,12 @@ var (
 	// ErrTxHashNotFound is returned by GetDeposit when the given transaction
 	// hash is not found
 	ErrTxHashNotFound = fmt.Errorf("deposit tx hash not found")
+	// ErrBlockNotCanonical is returned by VerifyBlock when the given block
+	// is not part of the canonical chain
+	ErrBlockNotCanonical = fmt.Errorf("block is not part of the canonical chain")
+	// ErrBlockNotFinal is returned by VerifyBlock when the given block
+	// is not final yet
+	ErrBlockNotFinal = fmt.Errorf("block is not final")
 )
 
 // IsInvalidGetDepositRequest returns true if the given error
@@ -50,6 +60,10 @@ func IsInvalidGetDepositRequest(err error) bool {
 type Block struct {
 	// Number is the sequence number of the block
 	Number uint64
+	// Hash is the hash of the block header
+	Hash common.Hash
+	// ParentHash is the hash of the previous block
+	ParentHash common.Hash
 	// Time is the timestamp when the block was executed
 	Time time.Time
 }
@@ -84,6 +98,8 @@ type Deposit struct {
 	// BlockNumber is the sequence number of the block containing the deposit
 	// transaction
 	BlockNumber uint64
+	// BlockHash is the hash of the block containing the deposit transaction
+	BlockHash common.Hash
 	// Time is the timestamp of the deposit
 	Time time.Time
 }
@@ -98,35 +114,66 @@ func DepositID(txHash string, logIndex uint) string {
 }
 
 // Observer is used to inspect the ethereum blockchain to
-// for all information relevant to bridge interactions
+// for all information relevant to bridge interactions. Every query is sent
+// to all RPC endpoints and the result is only returned if at least quorum
+// endpoints agree on it.
 type Observer struct {
-	client        *ethclient.Client
+	nodes         []node
+	quorum        int
 	filterer      *solidity.BridgeFilterer
-	caller        *solidity.BridgeCaller
 	bridgeAddress common.Address
+	finality      Finality
+	metrics       *rpcMetrics
 }
 
 // NewObserver constructs a new Observer instance
-func NewObserver(client *ethclient.Client, bridgeAddress string) (Observer, error) {
+func NewObserver(
+	endpoints []RPCEndpoint,
+	quorum int,
+	bridgeAddress string,
+	finality Finality,
+) (Observer, error) {
 	if !common.IsHexAddress(bridgeAddress) {
 		return Observer{}, fmt.Errorf("%v is not a valid ethereum address", bridgeAddress)
 	}
 	bridgeAddressParsed := common.HexToAddress(bridgeAddress)
-
-	caller, err := solidity.NewBridgeCaller(bridgeAddressParsed, client)
-	if err != nil {
+	if err := finality.Validate(); err != nil {
 		return Observer{}, err
 	}
-	filterer, err := solidity.NewBridgeFilterer(bridgeAddressParsed, client)
+	if len(endpoints) == 0 {
+		return Observer{}, fmt.Errorf("at least one rpc endpoint is required")
+	}
+	if quorum < 1 || quorum > len(endpoints) {
+		return Observer{}, fmt.Errorf("quorum must be between 1 and %d", len(endpoints))
+	}
+
+	var nodes []node
+	for _, endpoint := range endpoints {
+		client := ethclient.NewClient(endpoint.Client)
+		caller, err := solidity.NewBridgeCaller(bridgeAddressParsed, client)
+		if err != nil {
+			return Observer{}, err
+		}
+		nodes = append(nodes, node{
+			name:      endpoint.Name,
+			rpcClient: endpoint.Client,
+			client:    client,
+			caller:    caller,
+		})
+	}
+	// the filterer is only used to parse logs
+	filterer, err := solidity.NewBridgeFilterer(bridgeAddressParsed, nodes[0].client)
 	if err != nil {
 		return Observer{}, err
 	}
 
 	return Observer{
-		client:        client,
+		nodes:         nodes,
+		quorum:        quorum,
 		filterer:      filterer,
-		caller:        caller,
 		bridgeAddress: bridgeAddressParsed,
+		finality:      finality,
+		metrics:       newRPCMetrics(),
 	}, nil
 }
 
@@ -135,7 +182,19 @@ func NewObserver(client *ethclient.Client, bridgeAddress string) (Observer, erro
 func (o Observer) GetDeposit(
 	ctx context.Context, txHash string, logIndex uint,
 ) (Deposit, error) {
-	receipt, err := o.client.TransactionReceipt(ctx, common.HexToHash(txHash))
+	deposit, err := o.call(ctx, "get_deposit", func(ctx context.Context, n node) (interface{}, error) {
+		return o.getDeposit(ctx, n, txHash, logIndex)
+	})
+	if err != nil {
+		return Deposit{}, err
+	}
+	return deposit.(Deposit), nil
+}
+
+func (o Observer) getDeposit(
+	ctx context.Context, n node, txHash string, logIndex uint,
+) (Deposit, error) {
+	receipt, err := n.client.TransactionReceipt(ctx, common.HexToHash(txHash))
 	if err != nil {
 		if err == ethereum.NotFound {
 			return Deposit{}, ErrTxHashNotFound
@@ -156,7 +215,7 @@ func (o Observer) GetDeposit(
 		return Deposit{}, ErrLogNotFromBridge
 	}
 
-	header, err := o.client.HeaderByHash(ctx, log.BlockHash)
+	header, err := n.client.HeaderByHash(ctx, log.BlockHash)
 	if err != nil {
 		return Deposit{}, err
 	}
@@ -173,42 +232,134 @@ func (o Observer) GetDeposit(
 		TxHash:      log.TxHash,
 		LogIndex:    logIndex,
 		BlockNumber: log.BlockNumber,
+		BlockHash:   log.BlockHash,
 		Time:        time.Unix(int64(header.Time), 0),
 	}, nil
 }
 
-// GetLatestBlock returns the latest ethereum block
+// GetLatestBlock returns the latest ethereum block reached by at least
+// quorum RPC endpoints
 func (o Observer) GetLatestBlock(ctx context.Context) (Block, error) {
-	return o.getBlockByNumber(ctx, nil)
+	number, err := o.quorumBlockNumber(ctx, "get_latest_block", func(ctx context.Context, n node) (*types.Header, error) {
+		return n.client.HeaderByNumber(ctx, nil)
+	})
+	if err != nil {
+		return Block{}, err
+	}
+	return o.GetBlockByNumber(ctx, number)
 }
 
 // GetBlockByNumber finds an ethereum block by its sequence number
 func (o Observer) GetBlockByNumber(ctx context.Context, blockNumber uint64) (Block, error) {
-	bn := &big.Int{}
-	bn.SetUint64(blockNumber)
-	return o.getBlockByNumber(ctx, bn)
+	block, err := o.call(ctx, "get_block_by_number", func(ctx context.Context, n node) (interface{}, error) {
+		header, err := n.client.HeaderByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+		if err != nil {
+			return nil, err
+		}
+		return blockFromHeader(header), nil
+	})
+	if err != nil {
+		return Block{}, err
+	}
+	return block.(Block), nil
 }
 
-func (o Observer) getBlockByNumber(ctx context.Context, number *big.Int) (Block, error) {
-	header, err := o.client.HeaderByNumber(ctx, number)
+// GetBlockByHash finds an ethereum block by its hash
+func (o Observer) GetBlockByHash(ctx context.Context, hash common.Hash) (Block, error) {
+	block, err := o.call(ctx, "get_block_by_hash", func(ctx context.Context, n node) (interface{}, error) {
+		header, err := n.client.HeaderByHash(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		return blockFromHeader(header), nil
+	})
 	if err != nil {
 		return Block{}, err
 	}
+	return block.(Block), nil
+}
+
+// VerifyBlock checks that the block identified by the given number and hash is
+// final and part of the canonical chain. The check is performed by walking the
+// parent hashes from the latest final block, or from the block
+// maxVerifyBlockDepth blocks ahead of the given block if it is buried deeper,
+// so that the block is verified against the chain of a single final block.
+// ErrBlockNotCanonical is returned if the block was reorged out and
+// ErrBlockNotFinal is returned if the block is newer than the latest final
+// block.
+func (o Observer) VerifyBlock(ctx context.Context, number uint64, hash common.Hash) error {
+	final, err := o.GetLatestFinalBlock(ctx)
+	if err == ErrNoFinalBlock {
+		return ErrBlockNotFinal
+	} else if err != nil {
+		return err
+	}
+	if number > final.Number {
+		return ErrBlockNotFinal
+	}
+
+	block := final
+	if final.Number-number > maxVerifyBlockDepth {
+		block, err = o.GetBlockByNumber(ctx, number+maxVerifyBlockDepth)
+		if err != nil {
+			return err
+		}
+	}
+	for block.Number > number {
+		block, err = o.GetBlockByHash(ctx, block.ParentHash)
+		if err != nil {
+			return err
+		}
+	}
+	if block.Hash != hash {
+		return ErrBlockNotCanonical
+	}
+	return nil
+}
+
+func blockFromHeader(header *types.Header) Block {
 	return Block{
-		Number: header.Number.Uint64(),
-		Time:   time.Unix(int64(header.Time), 0),
-	}, nil
+		Number:     header.Number.Uint64(),
+		Hash:       header.Hash(),
+		ParentHash: header.ParentHash,
+		Time:       time.Unix(int64(header.Time), 0),
+	}
 }
 
 // GetRequestStatus calls the requestStatus() view function on the bridge contract
 // to determine the status of a bridge withdrawal
 func (o Observer) GetRequestStatus(ctx context.Context, requestID common.Hash) (RequestStatus, error) {
-	fulfilled, blockNumber, err := o.caller.RequestStatus(&bind.CallOpts{Context: ctx}, requestID)
+	const method = "get_request_status"
+	results := o.callAll(ctx, method, func(ctx context.Context, n node) (interface{}, error) {
+		fulfilled, blockNumber, err := n.caller.RequestStatus(&bind.CallOpts{Context: ctx}, requestID)
+		if err != nil {
+			return nil, err
+		}
+		return RequestStatus{
+			Fulfilled:   fulfilled,
+			BlockNumber: blockNumber.Uint64(),
+		}, nil
+	})
+
+	// Endpoints can be at different heights so only the fulfilled flag
+	// must match. The lowest block number of the matching endpoints is
+	// returned.
+	votes := make([]nodeResult, len(results))
+	for i, result := range results {
+		votes[i] = result
+		if result.err == nil {
+			votes[i].value = result.value.(RequestStatus).Fulfilled
+		}
+	}
+	agreeing, err := o.agree(method, votes)
 	if err != nil {
 		return RequestStatus{}, err
 	}
-	return RequestStatus{
-		Fulfilled:   fulfilled,
-		BlockNumber: blockNumber.Uint64(),
-	}, nil
+	status := results[agreeing[0]].value.(RequestStatus)
+	for _, i := range agreeing {
+		if blockNumber := results[i].value.(RequestStatus).BlockNumber; blockNumber < status.BlockNumber {
+			status.BlockNumber = blockNumber
+		}
+	}
+	return status, nil
 }
@@ -0,0 +1,221 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stellar/go/support/log"
+
+	"github.com/stellar/starbridge/solidity-go"
+)
+
+// rpcTimeout is the timeout of a single call to an RPC endpoint
+const rpcTimeout = 10 * time.Second
+
+// ErrNoQuorum is returned by the Observer when not enough RPC endpoints
+// returned matching results
+var ErrNoQuorum = fmt.Errorf("ethereum rpc endpoints did not reach quorum")
+
+// RPCEndpoint is an ethereum node used by the Observer
+type RPCEndpoint struct {
+	// Name identifies the endpoint in logs and metrics
+	Name   string
+	Client *rpc.Client
+}
+
+type node struct {
+	name      string
+	rpcClient *rpc.Client
+	client    *ethclient.Client
+	caller    *solidity.BridgeCaller
+}
+
+type rpcMetrics struct {
+	latency       *prometheus.HistogramVec
+	errors        *prometheus.CounterVec
+	disagreements *prometheus.CounterVec
+}
+
+func newRPCMetrics() *rpcMetrics {
+	labels := []string{"endpoint", "method"}
+	return &rpcMetrics{
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "starbridge", Subsystem: "ethereum_rpc", Name: "request_duration_seconds",
+			Help: "Duration of ethereum rpc calls, labeled by endpoint and observer method",
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "starbridge", Subsystem: "ethereum_rpc", Name: "errors_total",
+			Help: "Number of failed ethereum rpc calls, labeled by endpoint and observer method",
+		}, labels),
+		disagreements: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "starbridge", Subsystem: "ethereum_rpc", Name: "disagreements_total",
+			Help: "Number of results which differed from the quorum result, labeled by endpoint and observer method",
+		}, labels),
+	}
+}
+
+// isVote returns true if the error is a valid result which must be
+// confirmed by other endpoints, as opposed to a failure of the endpoint
+func isVote(err error) bool {
+	switch err {
+	case nil, ErrTxHashNotFound, ErrLogNotFound, ErrLogNotFromBridge, ErrLogNotDepositEvent, ErrNoFinalBlock:
+		return true
+	default:
+		return false
+	}
+}
+
+type nodeResult struct {
+	value interface{}
+	err   error
+}
+
+// callAll calls fn on every node concurrently
+func (o Observer) callAll(
+	ctx context.Context,
+	method string,
+	fn func(context.Context, node) (interface{}, error),
+) []nodeResult {
+	results := make([]nodeResult, len(o.nodes))
+	var wg sync.WaitGroup
+	for i := range o.nodes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			n := o.nodes[i]
+			callCtx, cancel := context.WithTimeout(ctx, rpcTimeout)
+			defer cancel()
+
+			start := time.Now()
+			value, err := fn(callCtx, n)
+			o.metrics.latency.WithLabelValues(n.name, method).Observe(time.Since(start).Seconds())
+			if !isVote(err) {
+				o.metrics.errors.WithLabelValues(n.name, method).Inc()
+				log.WithFields(log.F{"endpoint": n.name, "method": method, "error": err}).
+					Warn("Ethereum rpc call failed")
+			}
+			results[i] = nodeResult{value: value, err: err}
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+// call calls fn on every node and returns the result, or one of the
+// sentinel errors, returned by at least quorum nodes
+func (o Observer) call(
+	ctx context.Context,
+	method string,
+	fn func(context.Context, node) (interface{}, error),
+) (interface{}, error) {
+	results := o.callAll(ctx, method, fn)
+	agreeing, err := o.agree(method, results)
+	if err != nil {
+		return nil, err
+	}
+	result := results[agreeing[0]]
+	return result.value, result.err
+}
+
+// agree returns the indexes of the nodes which returned the result matched
+// by at least quorum nodes
+func (o Observer) agree(method string, results []nodeResult) ([]int, error) {
+	// group matching results
+	var groups [][]int
+	for i, result := range results {
+		if !isVote(result.err) {
+			continue
+		}
+		found := false
+		for g, group := range groups {
+			first := results[group[0]]
+			if first.err == result.err && reflect.DeepEqual(first.value, result.value) {
+				groups[g] = append(group, i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			groups = append(groups, []int{i})
+		}
+	}
+
+	winner := -1
+	for g, group := range groups {
+		if len(group) < o.quorum {
+			continue
+		}
+		if winner != -1 {
+			return nil, fmt.Errorf("%w: conflicting results of %s", ErrNoQuorum, method)
+		}
+		winner = g
+	}
+	if winner == -1 {
+		return nil, o.noQuorumError(method, results)
+	}
+
+	for g, group := range groups {
+		if g == winner {
+			continue
+		}
+		for _, i := range group {
+			o.metrics.disagreements.WithLabelValues(o.nodes[i].name, method).Inc()
+			log.WithFields(log.F{"endpoint": o.nodes[i].name, "method": method}).
+				Warn("Ethereum rpc endpoint disagrees with quorum")
+		}
+	}
+	return groups[winner], nil
+}
+
+func (o Observer) noQuorumError(method string, results []nodeResult) error {
+	for _, result := range results {
+		if !isVote(result.err) {
+			return fmt.Errorf("%w: %s failed: %v", ErrNoQuorum, method, result.err)
+		}
+	}
+	return fmt.Errorf("%w: %s results differ", ErrNoQuorum, method)
+}
+
+// quorumBlockNumber returns the highest block number which at least quorum
+// nodes have reached according to fn. ErrNoFinalBlock is returned if not
+// enough nodes have a block.
+func (o Observer) quorumBlockNumber(
+	ctx context.Context,
+	method string,
+	fn func(context.Context, node) (*types.Header, error),
+) (uint64, error) {
+	results := o.callAll(ctx, method, func(ctx context.Context, n node) (interface{}, error) {
+		return fn(ctx, n)
+	})
+
+	var numbers []uint64
+	noBlock := 0
+	for _, result := range results {
+		if result.err == ErrNoFinalBlock {
+			noBlock++
+		} else if result.err == nil {
+			numbers = append(numbers, result.value.(*types.Header).Number.Uint64())
+		}
+	}
+	if len(numbers) >= o.quorum {
+		sort.Slice(numbers, func(i, j int) bool { return numbers[i] > numbers[j] })
+		return numbers[o.quorum-1], nil
+	}
+	if noBlock+len(numbers) >= o.quorum {
+		return 0, ErrNoFinalBlock
+	}
+	return 0, o.noQuorumError(method, results)
+}
+
+// RegisterMetrics registers the per endpoint rpc metrics in the given registry
+func (o Observer) RegisterMetrics(registry *prometheus.Registry) {
+	registry.MustRegister(o.metrics.latency, o.metrics.errors, o.metrics.disagreements)
+}
@@ -0,0 +1,104 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func quorumObserver(quorum int, names ...string) Observer {
+	o := Observer{quorum: quorum, metrics: newRPCMetrics()}
+	for _, name := range names {
+		o.nodes = append(o.nodes, node{name: name})
+	}
+	return o
+}
+
+func TestObserver_Call(t *testing.T) {
+	errUnavailable := errors.New("unavailable")
+	for _, testCase := range []struct {
+		name          string
+		quorum        int
+		results       map[string]nodeResult
+		expectedValue interface{}
+		expectedError error
+	}{
+		{
+			name:   "quorum",
+			quorum: 2,
+			results: map[string]nodeResult{
+				"a": {value: big.NewInt(1)},
+				"b": {value: big.NewInt(2)},
+				"c": {value: big.NewInt(1)},
+			},
+			expectedValue: big.NewInt(1),
+		},
+		{
+			name:   "sentinel error",
+			quorum: 2,
+			results: map[string]nodeResult{
+				"a": {err: ErrTxHashNotFound},
+				"b": {err: ErrTxHashNotFound},
+				"c": {value: big.NewInt(1)},
+			},
+			expectedError: ErrTxHashNotFound,
+		},
+		{
+			name:   "unavailable endpoint",
+			quorum: 2,
+			results: map[string]nodeResult{
+				"a": {value: big.NewInt(1)},
+				"b": {err: errUnavailable},
+				"c": {value: big.NewInt(2)},
+			},
+			expectedError: ErrNoQuorum,
+		},
+		{
+			name:   "conflicting quorums",
+			quorum: 1,
+			results: map[string]nodeResult{
+				"a": {value: big.NewInt(1)},
+				"b": {value: big.NewInt(2)},
+				"c": {err: errUnavailable},
+			},
+			expectedError: ErrNoQuorum,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			o := quorumObserver(testCase.quorum, "a", "b", "c")
+			value, err := o.call(context.Background(), "test", func(ctx context.Context, n node) (interface{}, error) {
+				result := testCase.results[n.name]
+				return result.value, result.err
+			})
+			if testCase.expectedError != nil {
+				assert.ErrorIs(t, err, testCase.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedValue, value)
+		})
+	}
+}
+
+func TestObserver_QuorumBlockNumber(t *testing.T) {
+	heights := map[string]int64{"a": 100, "b": 102, "c": 101}
+	o := quorumObserver(2, "a", "b", "c")
+	number, err := o.quorumBlockNumber(context.Background(), "test", func(ctx context.Context, n node) (*types.Header, error) {
+		return &types.Header{Number: big.NewInt(heights[n.name])}, nil
+	})
+	assert.NoError(t, err)
+	// the highest block reached by two endpoints
+	assert.Equal(t, uint64(101), number)
+
+	_, err = o.quorumBlockNumber(context.Background(), "test", func(ctx context.Context, n node) (*types.Header, error) {
+		if n.name == "b" {
+			return &types.Header{Number: big.NewInt(heights[n.name])}, nil
+		}
+		return nil, ErrNoFinalBlock
+	})
+	assert.Equal(t, ErrNoFinalBlock, err)
+}
@@ -1,7 +1,7 @@
 package ethereum
 
 import (
-	"crypto/ecdsa"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/accounts"
@@ -15,7 +15,10 @@ import (
 
 var (
 	uint256           = mustType("uint256")
+	uint8Type         = mustType("uint8")
 	bytes32           = mustType("bytes32")
+	addressArray      = mustType("address[]")
+	stringType        = mustType("string")
 	withdrawERC20Type = mustTupleType([]abi.ArgumentMarshaling{
 		{Name: "id", Type: "bytes32"},
 		{Name: "expiration", Type: "uint256"},
@@ -29,6 +32,11 @@ var (
 		{Name: "recipient", Type: "address"},
 		{Name: "amount", Type: "uint256"},
 	})
+	setPausedType = mustTupleType([]abi.ArgumentMarshaling{
+		{Name: "value", Type: "uint8"},
+		{Name: "nonce", Type: "uint256"},
+		{Name: "expiration", Type: "uint256"},
+	})
 )
 
 func mustType(t string) abi.Type {
@@ -47,31 +55,60 @@ func mustTupleType(components []abi.ArgumentMarshaling) abi.Type {
 	return ty
 }
 
+// KeyBackend holds the private key of an ethereum validator account
+// and signs digests with it
+type KeyBackend interface {
+	// Address returns the ethereum address corresponding to the key
+	Address() common.Address
+	// SignHash signs the given 32 byte digest and returns the signature in
+	// the [R || S || V] format where V is 0 or 1
+	SignHash(hash []byte) ([]byte, error)
+}
+
 // Signer represents an ethereum validator account which is
 // authorized to approve withdrawals from the bridge smart contract.
 type Signer struct {
-	privateKey *ecdsa.PrivateKey
-	version    *big.Int
-	address    common.Address
+	backend KeyBackend
+	version *big.Int
 }
 
-// NewSigner constructs a new Signer instance
+// NewSigner constructs a new Signer instance from a hex encoded private key
 func NewSigner(privateKey string, bridgeConfigVersion uint32) (Signer, error) {
-	parsed, err := crypto.HexToECDSA(privateKey)
+	backend, err := NewPrivateKeyBackend(privateKey)
 	if err != nil {
 		return Signer{}, err
 	}
+	return NewSignerWithBackend(backend, bridgeConfigVersion), nil
+}
+
+// NewSignerWithBackend constructs a new Signer instance which signs
+// withdrawals using the given key backend
+func NewSignerWithBackend(backend KeyBackend, bridgeConfigVersion uint32) Signer {
 	return Signer{
-		privateKey: parsed,
-		version:    big.NewInt(int64(bridgeConfigVersion)),
-		address:    crypto.PubkeyToAddress(parsed.PublicKey),
-	}, nil
+		backend: backend,
+		version: big.NewInt(int64(bridgeConfigVersion)),
+	}
 }
 
 // Address returns the ethereum address corresponding to the public
 // key of the signer
 func (s Signer) Address() common.Address {
-	return s.address
+	return s.backend.Address()
+}
+
+// Version returns the version of the bridge validator set which is
+// included in all payloads signed by the signer
+func (s Signer) Version() uint64 {
+	return s.version.Uint64()
+}
+
+// WithVersion returns a copy of the signer which signs payloads for
+// the given version of the bridge validator set
+func (s Signer) WithVersion(version uint64) Signer {
+	return Signer{
+		backend: s.backend,
+		version: new(big.Int).SetUint64(version),
+	}
 }
 
 // SignWithdrawal returns a signature for the given withdrawal request
@@ -81,16 +118,48 @@ func (s Signer) SignWithdrawal(
 	recipient,
 	token common.Address, // an address of 0x0 indicates an ETH transfer
 	amount *big.Int,
+) ([]byte, error) {
+	abiEncoded, err := withdrawalPayload(s.version, id, expiration, recipient, token, amount)
+	if err != nil {
+		return nil, err
+	}
+	return s.signPayload(abiEncoded)
+}
+
+// WithdrawalHash returns the digest which validators sign to approve the
+// given withdrawal request for the given version of the bridge validator set
+func WithdrawalHash(
+	version *big.Int,
+	id common.Hash,
+	expiration int64,
+	recipient,
+	token common.Address, // an address of 0x0 indicates an ETH transfer
+	amount *big.Int,
+) ([]byte, error) {
+	abiEncoded, err := withdrawalPayload(version, id, expiration, recipient, token, amount)
+	if err != nil {
+		return nil, err
+	}
+	return payloadHash(abiEncoded), nil
+}
+
+func withdrawalPayload(
+	version *big.Int,
+	id common.Hash,
+	expiration int64,
+	recipient,
+	token common.Address,
+	amount *big.Int,
 ) ([]byte, error) {
 	if token == (common.Address{}) {
-		return s.signWithdrawETHRequest(solidity.WithdrawETHRequest{
+		return withdrawETHPayload(version, solidity.WithdrawETHRequest{
 			Id:         id,
 			Expiration: big.NewInt(expiration),
 			Recipient:  recipient,
 			Amount:     amount,
 		})
 	} else {
-		return s.signWithdrawERC20Request(solidity.WithdrawERC20Request{
+		return withdrawERC20Payload(version, solidity.WithdrawERC20Request{
 			Id:         id,
 			Expiration: big.NewInt(expiration),
 			Recipient:  recipient,
@@ -100,16 +169,45 @@ func (s Signer) SignWithdrawal(
 	}
 }
 
-func (s Signer) signWithdrawERC20Request(request solidity.WithdrawERC20Request) ([]byte, error) {
+func withdrawERC20Payload(version *big.Int, request solidity.WithdrawERC20Request) ([]byte, error) {
 	arguments := abi.Arguments{
 		{Type: uint256},
 		{Type: bytes32},
 		{Type: withdrawERC20Type},
 	}
 
+	return arguments.Pack(
+		version,
+		crypto.Keccak256Hash([]byte("withdrawERC20")),
+		request,
+	)
+}
+
+func withdrawETHPayload(version *big.Int, request solidity.WithdrawETHRequest) ([]byte, error) {
+	arguments := abi.Arguments{
+		{Type: uint256},
+		{Type: bytes32},
+		{Type: withdrawETHType},
+	}
+
+	return arguments.Pack(
+		version,
+		crypto.Keccak256Hash([]byte("withdrawETH")),
+		request,
+	)
+}
+
+// SignSetPaused returns a signature for the given setPaused request
+func (s Signer) SignSetPaused(request solidity.SetPausedRequest) ([]byte, error) {
+	arguments := abi.Arguments{
+		{Type: uint256},
+		{Type: bytes32},
+		{Type: setPausedType},
+	}
+
 	abiEncoded, err := arguments.Pack(
 		s.version,
-		crypto.Keccak256Hash([]byte("withdrawERC20")),
+		crypto.Keccak256Hash([]byte("setPaused")),
 		request,
 	)
 	if err != nil {
@@ -118,17 +216,45 @@ func (s Signer) signWithdrawERC20Request(request solidity.WithdrawERC20Request)
 	return s.signPayload(abiEncoded)
 }
 
-func (s Signer) signWithdrawETHRequest(request solidity.WithdrawETHRequest) ([]byte, error) {
+// SignUpdateSigners returns a signature approving the replacement of the
+// bridge validator set with the given signers and threshold
+func (s Signer) SignUpdateSigners(signers []common.Address, minThreshold uint8) ([]byte, error) {
 	arguments := abi.Arguments{
 		{Type: uint256},
 		{Type: bytes32},
-		{Type: withdrawETHType},
+		{Type: addressArray},
+		{Type: uint8Type},
 	}
 
 	abiEncoded, err := arguments.Pack(
 		s.version,
-		crypto.Keccak256Hash([]byte("withdrawETH")),
-		request,
+		crypto.Keccak256Hash([]byte("updateSigners")),
+		signers,
+		minThreshold,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return s.signPayload(abiEncoded)
+}
+
+// SignRegisterStellarAsset returns a signature approving the deployment of
+// the ERC20 token described by the given request
+func (s Signer) SignRegisterStellarAsset(request solidity.RegisterStellarAssetRequest) ([]byte, error) {
+	arguments := abi.Arguments{
+		{Type: uint256},
+		{Type: bytes32},
+		{Type: uint8Type},
+		{Type: bytes32},
+		{Type: bytes32},
+	}
+
+	abiEncoded, err := arguments.Pack(
+		s.version,
+		crypto.Keccak256Hash([]byte("registerStellarAsset")),
+		request.Decimals,
+		crypto.Keccak256Hash([]byte(request.Name)),
+		crypto.Keccak256Hash([]byte(request.Symbol)),
 	)
 	if err != nil {
 		return nil, err
@@ -136,18 +262,43 @@ func (s Signer) signWithdrawETHRequest(request solidity.WithdrawETHRequest) ([]b
 	return s.signPayload(abiEncoded)
 }
 
+// payloadHash returns the digest signed for the given abi encoded payload,
+// which the bridge smart contract computes with ECDSA.toEthSignedMessageHash
+func payloadHash(abiEncoded []byte) []byte {
+	return accounts.TextHash(crypto.Keccak256(abiEncoded))
+}
+
 func (s Signer) signPayload(abiEncoded []byte) ([]byte, error) {
-	sig, err := crypto.Sign(accounts.TextHash(crypto.Keccak256(abiEncoded)), s.privateKey)
+	sig, err := s.backend.SignHash(payloadHash(abiEncoded))
 	if err != nil {
 		return nil, err
 	}
 	// The ECDSA solidity library used by the bridge smart contract expects the v
 	// value to be 27 or 28, see:
 	// https://github.com/OpenZeppelin/openzeppelin-contracts/blob/v4.7.0/contracts/utils/cryptography/ECDSA.sol#L41-L43
-	// However, crypto.Sign() encodes the v value as 0 or 1, see:
+	// However, KeyBackend implementations encode the v value as 0 or 1 like
+	// crypto.Sign() does, see:
 	// https://github.com/ethereum/go-ethereum/blob/v1.10.20/crypto/signature_cgo.go#L54
 	// That is why we need to transform the signature to be compatible with the
 	// ECDSA solidity library.
 	sig[len(sig)-1] += 27
 	return sig, nil
 }
+
+// RecoverSigner returns the address of the validator which created the
+// given signature of the hash, in the format expected by the bridge smart
+// contract where the v value is 27 or 28
+func RecoverSigner(hash, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("invalid signature length %d", len(signature))
+	}
+	sig := append([]byte(nil), signature...)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	publicKey, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
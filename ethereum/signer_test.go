@@ -6,8 +6,12 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/starbridge/solidity-go"
 )
 
 func createSigner(t *testing.T) Signer {
@@ -67,5 +71,94 @@ func TestSigner_SignWithdrawal(t *testing.T) {
 			strings.ToLower(withdrawal.expected),
 			strings.ToLower(hex.EncodeToString(signature)),
 		)
+
+		hash, err := WithdrawalHash(
+			new(big.Int).SetUint64(signer.Version()),
+			withdrawal.id,
+			withdrawal.expiration,
+			withdrawal.recipient,
+			withdrawal.token,
+			withdrawal.amount,
+		)
+		assert.NoError(t, err)
+		address, err := RecoverSigner(hash, signature)
+		assert.NoError(t, err)
+		assert.Equal(t, signer.Address(), address)
+	}
+}
+
+func TestSigner_SignSetPaused(t *testing.T) {
+	signer := createSigner(t)
+	request := solidity.SetPausedRequest{
+		Value:      3,
+		Nonce:      big.NewInt(7),
+		Expiration: big.NewInt(1000),
+	}
+	signature, err := signer.SignSetPaused(request)
+	assert.NoError(t, err)
+
+	// SetPausedRequest only has static fields so it is abi encoded
+	// as a sequence of 32 byte words
+	var encoded []byte
+	encoded = append(encoded, common.LeftPadBytes(nil, 32)...)
+	encoded = append(encoded, crypto.Keccak256([]byte("setPaused"))...)
+	encoded = append(encoded, common.LeftPadBytes([]byte{3}, 32)...)
+	encoded = append(encoded, common.LeftPadBytes(request.Nonce.Bytes(), 32)...)
+	encoded = append(encoded, common.LeftPadBytes(request.Expiration.Bytes(), 32)...)
+
+	signature[64] -= 27
+	pubKey, err := crypto.SigToPub(accounts.TextHash(crypto.Keccak256(encoded)), signature)
+	assert.NoError(t, err)
+	assert.Equal(t, signer.Address(), crypto.PubkeyToAddress(*pubKey))
+}
+
+func TestSigner_SignUpdateSigners(t *testing.T) {
+	signer := createSigner(t).WithVersion(2)
+	signers := []common.Address{
+		common.HexToAddress("0x01"),
+		common.HexToAddress("0x02"),
+		common.HexToAddress("0x03"),
 	}
+	signature, err := signer.SignUpdateSigners(signers, 2)
+	assert.NoError(t, err)
+
+	// the signers array is dynamic so the head contains its offset
+	// and the tail contains its length followed by its elements
+	var encoded []byte
+	encoded = append(encoded, common.LeftPadBytes([]byte{2}, 32)...)
+	encoded = append(encoded, crypto.Keccak256([]byte("updateSigners"))...)
+	encoded = append(encoded, common.LeftPadBytes([]byte{4 * 32}, 32)...)
+	encoded = append(encoded, common.LeftPadBytes([]byte{2}, 32)...)
+	encoded = append(encoded, common.LeftPadBytes([]byte{byte(len(signers))}, 32)...)
+	for _, address := range signers {
+		encoded = append(encoded, common.LeftPadBytes(address.Bytes(), 32)...)
+	}
+
+	signature[64] -= 27
+	pubKey, err := crypto.SigToPub(accounts.TextHash(crypto.Keccak256(encoded)), signature)
+	assert.NoError(t, err)
+	assert.Equal(t, signer.Address(), crypto.PubkeyToAddress(*pubKey))
+}
+
+func TestSigner_SignRegisterStellarAsset(t *testing.T) {
+	signer := createSigner(t)
+	request := solidity.RegisterStellarAssetRequest{
+		Decimals: 18,
+		Name:     "Stellar Lumens",
+		Symbol:   "XLM",
+	}
+	signature, err := signer.SignRegisterStellarAsset(request)
+	assert.NoError(t, err)
+
+	var encoded []byte
+	encoded = append(encoded, common.LeftPadBytes(signer.version.Bytes(), 32)...)
+	encoded = append(encoded, crypto.Keccak256([]byte("registerStellarAsset"))...)
+	encoded = append(encoded, common.LeftPadBytes([]byte{request.Decimals}, 32)...)
+	encoded = append(encoded, crypto.Keccak256([]byte(request.Name))...)
+	encoded = append(encoded, crypto.Keccak256([]byte(request.Symbol))...)
+
+	signature[64] -= 27
+	pubKey, err := crypto.SigToPub(accounts.TextHash(crypto.Keccak256(encoded)), signature)
+	assert.NoError(t, err)
+	assert.Equal(t, signer.Address(), crypto.PubkeyToAddress(*pubKey))
 }
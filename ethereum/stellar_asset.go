@@ -0,0 +1,87 @@
+package ethereum
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stellar/go/support/errors"
+
+	"github.com/stellar/starbridge/solidity-go"
+)
+
+// contractArtifact is the subset of a hardhat compilation artifact
+// which contains the contract creation code
+type contractArtifact struct {
+	Bytecode string `json:"bytecode"`
+}
+
+// LoadContractBytecode reads the creation code of a contract from a hardhat
+// compilation artifact, for example:
+// solidity/artifacts/contracts/StellarAsset.sol/StellarAsset.json
+func LoadContractBytecode(artifactPath string) ([]byte, error) {
+	contents, err := ioutil.ReadFile(artifactPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading contract artifact")
+	}
+	var artifact contractArtifact
+	if err = json.Unmarshal(contents, &artifact); err != nil {
+		return nil, errors.Wrap(err, "error parsing contract artifact")
+	}
+	bytecode := common.FromHex(strings.TrimSpace(artifact.Bytecode))
+	if len(bytecode) == 0 {
+		return nil, errors.New("contract artifact does not contain bytecode")
+	}
+	return bytecode, nil
+}
+
+// StellarAssetSalt returns the CREATE2 salt used by the bridge smart contract
+// when deploying the ERC20 token described by the given request
+func StellarAssetSalt(request solidity.RegisterStellarAssetRequest) (common.Hash, error) {
+	arguments := abi.Arguments{
+		{Type: uint8Type},
+		{Type: bytes32},
+		{Type: bytes32},
+	}
+	abiEncoded, err := arguments.Pack(
+		request.Decimals,
+		crypto.Keccak256Hash([]byte(request.Name)),
+		crypto.Keccak256Hash([]byte(request.Symbol)),
+	)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(abiEncoded), nil
+}
+
+// StellarAssetAddress computes the deterministic address of the ERC20 token
+// which is deployed by registerStellarAsset. stellarAssetBytecode is the
+// creation code of the StellarAsset contract, see LoadContractBytecode.
+func StellarAssetAddress(
+	bridgeAddress common.Address,
+	stellarAssetBytecode []byte,
+	request solidity.RegisterStellarAssetRequest,
+) (common.Address, error) {
+	salt, err := StellarAssetSalt(request)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	constructorArguments := abi.Arguments{
+		{Type: stringType},
+		{Type: stringType},
+		{Type: uint8Type},
+	}
+	encodedArguments, err := constructorArguments.Pack(request.Name, request.Symbol, request.Decimals)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	initCode := make([]byte, 0, len(stellarAssetBytecode)+len(encodedArguments))
+	initCode = append(initCode, stellarAssetBytecode...)
+	initCode = append(initCode, encodedArguments...)
+	return crypto.CreateAddress2(bridgeAddress, salt, crypto.Keccak256(initCode)), nil
+}
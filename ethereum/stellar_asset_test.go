@@ -0,0 +1,90 @@
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/starbridge/solidity-go"
+)
+
+var (
+	// create2Factory deploys contracts with CREATE2 like the bridge contract
+	// does in registerStellarAsset. It is called with the salt followed by
+	// the init code and returns the address of the deployed contract:
+	//
+	//	CALLDATACOPY(0, 32, CALLDATASIZE - 32)
+	//	MSTORE(0, CREATE2(0, 0, CALLDATASIZE - 32, CALLDATALOAD(0)))
+	//	RETURN(0, 32)
+	create2Factory = common.FromHex(
+		"601b600c600039601b6000f3" +
+			"6020360380602060003760003590600060" + "00f5600052602060" + "00f3",
+	)
+	// testStellarAssetBytecode deploys a contract with a single byte of
+	// code ignoring the constructor arguments
+	testStellarAssetBytecode = common.FromHex("600160005360016000f3")
+)
+
+func TestStellarAssetAddress(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	deployer := crypto.PubkeyToAddress(key.PublicKey)
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{
+		deployer: {Balance: new(big.Int).Lsh(big.NewInt(1), 100)},
+	}, 10000000)
+	defer backend.Close()
+	opts, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	require.NoError(t, err)
+
+	factoryAddress, _, factory, err := bind.DeployContract(opts, abi.ABI{}, create2Factory, backend)
+	require.NoError(t, err)
+	backend.Commit()
+
+	request := solidity.RegisterStellarAssetRequest{
+		Decimals: 7,
+		Name:     "Stellar Lumens",
+		Symbol:   "XLM",
+	}
+	expected, err := StellarAssetAddress(factoryAddress, testStellarAssetBytecode, request)
+	require.NoError(t, err)
+
+	salt, err := StellarAssetSalt(request)
+	require.NoError(t, err)
+	constructorArguments, err := abi.Arguments{
+		{Type: stringType},
+		{Type: stringType},
+		{Type: uint8Type},
+	}.Pack(request.Name, request.Symbol, request.Decimals)
+	require.NoError(t, err)
+	callData := append(salt.Bytes(), testStellarAssetBytecode...)
+	callData = append(callData, constructorArguments...)
+
+	_, err = factory.RawTransact(opts, callData)
+	require.NoError(t, err)
+	backend.Commit()
+
+	code, err := backend.CodeAt(context.Background(), expected, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1}, code)
+
+	// A different request results in a different token
+	other, err := StellarAssetAddress(factoryAddress, testStellarAssetBytecode, solidity.RegisterStellarAssetRequest{
+		Decimals: 7,
+		Name:     "Stellar Lumens",
+		Symbol:   "XLM2",
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, expected, other)
+	code, err = backend.CodeAt(context.Background(), other, nil)
+	require.NoError(t, err)
+	assert.Empty(t, code)
+}
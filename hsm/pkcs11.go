@@ -0,0 +1,249 @@
+//go:build cgo
+// +build cgo
+
+// Package hsm implements key backends which keep validator keys in a
+// hardware security module accessed through a PKCS#11 module.
+package hsm
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/miekg/pkcs11"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// ckmEDDSA is the CKM_EDDSA mechanism introduced in PKCS#11 v3.0
+const ckmEDDSA = 0x00001057
+
+var secp256k1HalfN = new(big.Int).Rsh(crypto.S256().Params().N, 1)
+
+// Config identifies a key stored in a PKCS#11 token
+type Config struct {
+	// ModulePath is the path to the PKCS#11 shared library
+	ModulePath string
+	// TokenLabel is the label of the token holding the key
+	TokenLabel string
+	// KeyLabel is the label of the private and public key objects
+	KeyLabel string
+	// PIN is the user pin of the token
+	PIN string
+}
+
+// session is a logged in PKCS#11 session with handles to a key pair
+type session struct {
+	mu         sync.Mutex
+	ctx        *pkcs11.Ctx
+	handle     pkcs11.SessionHandle
+	privateKey pkcs11.ObjectHandle
+	// publicKey is the CKA_EC_POINT of the public key
+	publicKey []byte
+}
+
+func openSession(config Config) (*session, error) {
+	ctx := pkcs11.New(config.ModulePath)
+	if ctx == nil {
+		return nil, fmt.Errorf("cannot load pkcs11 module %s", config.ModulePath)
+	}
+	if err := ctx.Initialize(); err != nil {
+		return nil, errors.Wrap(err, "error initializing pkcs11 module")
+	}
+
+	slot, err := findSlot(ctx, config.TokenLabel)
+	if err != nil {
+		return nil, err
+	}
+	handle, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return nil, errors.Wrap(err, "error opening pkcs11 session")
+	}
+	if err = ctx.Login(handle, pkcs11.CKU_USER, config.PIN); err != nil {
+		return nil, errors.Wrap(err, "error logging in to pkcs11 token")
+	}
+
+	s := &session{ctx: ctx, handle: handle}
+	s.privateKey, err = s.findObject(pkcs11.CKO_PRIVATE_KEY, config.KeyLabel)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := s.findObject(pkcs11.CKO_PUBLIC_KEY, config.KeyLabel)
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := ctx.GetAttributeValue(handle, publicKey, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting public key")
+	}
+	// CKA_EC_POINT is usually a DER encoded octet string but some
+	// modules return the raw point
+	var point []byte
+	if _, err = asn1.Unmarshal(attributes[0].Value, &point); err != nil {
+		point = attributes[0].Value
+	}
+	s.publicKey = point
+
+	return s, nil
+}
+
+func findSlot(ctx *pkcs11.Ctx, tokenLabel string) (uint, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, errors.Wrap(err, "error listing pkcs11 slots")
+	}
+	for _, slot := range slots {
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil {
+			return 0, errors.Wrap(err, "error getting pkcs11 token info")
+		}
+		if info.Label == tokenLabel {
+			return slot, nil
+		}
+	}
+	return 0, fmt.Errorf("pkcs11 token %s not found", tokenLabel)
+}
+
+func (s *session) findObject(class uint, label string) (pkcs11.ObjectHandle, error) {
+	err := s.ctx.FindObjectsInit(s.handle, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "error searching pkcs11 objects")
+	}
+	objects, _, err := s.ctx.FindObjects(s.handle, 1)
+	if err != nil {
+		return 0, errors.Wrap(err, "error searching pkcs11 objects")
+	}
+	if err = s.ctx.FindObjectsFinal(s.handle); err != nil {
+		return 0, errors.Wrap(err, "error searching pkcs11 objects")
+	}
+	if len(objects) == 0 {
+		return 0, fmt.Errorf("pkcs11 key %s not found", label)
+	}
+	return objects[0], nil
+}
+
+func (s *session) sign(mechanism uint, data []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.ctx.SignInit(s.handle, []*pkcs11.Mechanism{pkcs11.NewMechanism(mechanism, nil)}, s.privateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "error initializing pkcs11 signature")
+	}
+	return s.ctx.Sign(s.handle, data)
+}
+
+// EthereumBackend is an ethereum.KeyBackend which signs with a secp256k1
+// key stored in a PKCS#11 token
+type EthereumBackend struct {
+	session   *session
+	publicKey *ecdsa.PublicKey
+	address   common.Address
+}
+
+// NewEthereumBackend constructs an EthereumBackend from the given config
+func NewEthereumBackend(config Config) (*EthereumBackend, error) {
+	s, err := openSession(config)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := crypto.UnmarshalPubkey(s.publicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "pkcs11 key is not a secp256k1 key")
+	}
+	return &EthereumBackend{
+		session:   s,
+		publicKey: publicKey,
+		address:   crypto.PubkeyToAddress(*publicKey),
+	}, nil
+}
+
+// Address returns the ethereum address of the key
+func (b *EthereumBackend) Address() common.Address {
+	return b.address
+}
+
+// SignHash signs the given digest. PKCS#11 only returns the R and S values
+// so the recovery id is derived by recovering the public key.
+func (b *EthereumBackend) SignHash(hash []byte) ([]byte, error) {
+	rs, err := b.session.sign(pkcs11.CKM_ECDSA, hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(rs) != 64 {
+		return nil, fmt.Errorf("invalid pkcs11 signature length: %d", len(rs))
+	}
+
+	// Ethereum only accepts signatures with a low S value
+	s := new(big.Int).SetBytes(rs[32:])
+	if s.Cmp(secp256k1HalfN) > 0 {
+		s.Sub(crypto.S256().Params().N, s)
+		s.FillBytes(rs[32:])
+	}
+
+	expected := crypto.FromECDSAPub(b.publicKey)
+	for v := byte(0); v < 2; v++ {
+		sig := append(append([]byte{}, rs...), v)
+		recovered, err := crypto.Ecrecover(hash, sig)
+		if err == nil && bytes.Equal(recovered, expected) {
+			return sig, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot determine recovery id of pkcs11 signature")
+}
+
+// StellarBackend is a signer.KeyBackend which signs with an ed25519 key
+// stored in a PKCS#11 token
+type StellarBackend struct {
+	session *session
+	kp      *keypair.FromAddress
+}
+
+// NewStellarBackend constructs a StellarBackend from the given config
+func NewStellarBackend(config Config) (*StellarBackend, error) {
+	s, err := openSession(config)
+	if err != nil {
+		return nil, err
+	}
+	address, err := strkey.Encode(strkey.VersionByteAccountID, s.publicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "pkcs11 key is not an ed25519 key")
+	}
+	kp, err := keypair.ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	return &StellarBackend{session: s, kp: kp}, nil
+}
+
+// Address returns the Stellar account id of the key
+func (b *StellarBackend) Address() string {
+	return b.kp.Address()
+}
+
+// SignDecorated signs the given transaction hash
+func (b *StellarBackend) SignDecorated(hash []byte) (xdr.DecoratedSignature, error) {
+	sig, err := b.session.sign(ckmEDDSA, hash)
+	if err != nil {
+		return xdr.DecoratedSignature{}, err
+	}
+	if err = b.kp.Verify(hash, sig); err != nil {
+		return xdr.DecoratedSignature{}, errors.Wrap(err, "invalid pkcs11 signature")
+	}
+	return xdr.DecoratedSignature{
+		Hint:      xdr.SignatureHint(b.kp.Hint()),
+		Signature: xdr.Signature(sig),
+	}, nil
+}
@@ -0,0 +1,63 @@
+//go:build !cgo
+// +build !cgo
+
+// Package hsm implements key backends which keep validator keys in a
+// hardware security module accessed through a PKCS#11 module.
+package hsm
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stellar/go/xdr"
+)
+
+var errNoCgo = fmt.Errorf("pkcs11 support requires starbridge to be built with cgo")
+
+// Config identifies a key stored in a PKCS#11 token
+type Config struct {
+	// ModulePath is the path to the PKCS#11 shared library
+	ModulePath string
+	// TokenLabel is the label of the token holding the key
+	TokenLabel string
+	// KeyLabel is the label of the private and public key objects
+	KeyLabel string
+	// PIN is the user pin of the token
+	PIN string
+}
+
+// EthereumBackend is not available without cgo
+type EthereumBackend struct{}
+
+// NewEthereumBackend always fails without cgo
+func NewEthereumBackend(config Config) (*EthereumBackend, error) {
+	return nil, errNoCgo
+}
+
+// Address is not available without cgo
+func (b *EthereumBackend) Address() common.Address {
+	return common.Address{}
+}
+
+// SignHash always fails without cgo
+func (b *EthereumBackend) SignHash(hash []byte) ([]byte, error) {
+	return nil, errNoCgo
+}
+
+// StellarBackend is not available without cgo
+type StellarBackend struct{}
+
+// NewStellarBackend always fails without cgo
+func NewStellarBackend(config Config) (*StellarBackend, error) {
+	return nil, errNoCgo
+}
+
+// Address is not available without cgo
+func (b *StellarBackend) Address() string {
+	return ""
+}
+
+// SignDecorated always fails without cgo
+func (b *StellarBackend) SignDecorated(hash []byte) (xdr.DecoratedSignature, error) {
+	return xdr.DecoratedSignature{}, errNoCgo
+}
@@ -38,7 +38,27 @@ type ServerConfig struct {
 	EthereumWithdrawalHandler *controllers.EthereumWithdrawalHandler
 	EthereumRefundHandler     *controllers.EthereumRefundHandler
 
+	StellarDepositStatusHandler   *controllers.StellarDepositStatusHandler
+	InvalidStellarDepositsHandler *controllers.InvalidStellarDepositsHandler
+	EthereumDepositStatusHandler  *controllers.EthereumDepositStatusHandler
+
 	TestDepositHandler *controllers.TestDeposit
+
+	// Admin handlers
+	SetPausedHandler         *controllers.SetPausedHandler
+	UpdateSignersHandler     *controllers.UpdateSignersHandler
+	StellarSetSignersHandler *controllers.StellarSetSignersHandler
+
+	RegisterStellarAssetHandler *controllers.RegisterStellarAssetHandler
+
+	ListAssetMappingsHandler   *controllers.ListAssetMappingsHandler
+	AddAssetMappingHandler     *controllers.AddAssetMappingHandler
+	DisableAssetMappingHandler *controllers.DisableAssetMappingHandler
+
+	ListSignatureRequestsHandler  *controllers.ListSignatureRequestsHandler
+	GetSignatureRequestHandler    *controllers.GetSignatureRequestHandler
+	RetrySignatureRequestHandler  *controllers.RetrySignatureRequestHandler
+	CancelSignatureRequestHandler *controllers.CancelSignatureRequestHandler
 }
 
 type Server struct {
@@ -84,7 +104,7 @@ func NewServer(serverConfig ServerConfig) (*Server, error) {
 			Addr:        fmt.Sprintf(":%d", serverConfig.AdminPort),
 			ReadTimeout: 5 * time.Second,
 		}
-		server.initAdminMux()
+		server.initAdminMux(serverConfig)
 	}
 
 	return server, nil
@@ -98,7 +118,7 @@ func (s *Server) initMux(serverConfig ServerConfig) {
 	c := cors.New(cors.Options{
 		AllowedOrigins: []string{"*"},
 		AllowedHeaders: []string{"*"},
-		ExposedHeaders: []string{"Date"},
+		ExposedHeaders: []string{"Date", controllers.FeeHeader, controllers.FeeAssetHeader},
 	})
 	mux.Use(c.Handler)
 	mux.Use(middleware.NoCache)
@@ -110,6 +130,10 @@ func (s *Server) initMux(serverConfig ServerConfig) {
 	mux.Method(http.MethodPost, "/stellar/withdraw/ethereum", serverConfig.EthereumWithdrawalHandler)
 	mux.Method(http.MethodPost, "/ethereum/refund", serverConfig.EthereumRefundHandler)
 	mux.Method(http.MethodPost, "/stellar/refund", serverConfig.StellarRefundHandler)
+	mux.Method(http.MethodGet, "/stellar/deposit/{transaction_hash}", serverConfig.StellarDepositStatusHandler)
+	mux.Method(http.MethodGet, "/stellar/deposit/{transaction_hash}/{operation_index}", serverConfig.StellarDepositStatusHandler)
+	mux.Method(http.MethodGet, "/stellar/invalid_deposits", serverConfig.InvalidStellarDepositsHandler)
+	mux.Method(http.MethodGet, "/ethereum/deposit/{transaction_hash}/{log_index}", serverConfig.EthereumDepositStatusHandler)
 
 	// Demo routes
 	mux.Method(http.MethodPost, "/deposit", serverConfig.TestDepositHandler)
@@ -120,7 +144,7 @@ func (s *Server) initMux(serverConfig ServerConfig) {
 	s.server.Handler = mux
 }
 
-func (s *Server) initAdminMux() {
+func (s *Server) initAdminMux(serverConfig ServerConfig) {
 	adminMux := stellarhttp.NewAPIMux(log.DefaultLogger)
 
 	// Admin middlewares
@@ -130,6 +154,17 @@ func (s *Server) initAdminMux() {
 
 	// Admin routes
 	adminMux.Get("/metrics", promhttp.HandlerFor(s.prometheusRegistry, promhttp.HandlerOpts{}).ServeHTTP)
+	adminMux.Method(http.MethodPost, "/ethereum/set_paused", serverConfig.SetPausedHandler)
+	adminMux.Method(http.MethodPost, "/ethereum/update_signers", serverConfig.UpdateSignersHandler)
+	adminMux.Method(http.MethodPost, "/stellar/update_signers", serverConfig.StellarSetSignersHandler)
+	adminMux.Method(http.MethodPost, "/ethereum/register_stellar_asset", serverConfig.RegisterStellarAssetHandler)
+	adminMux.Method(http.MethodGet, "/asset_mappings", serverConfig.ListAssetMappingsHandler)
+	adminMux.Method(http.MethodPost, "/asset_mappings", serverConfig.AddAssetMappingHandler)
+	adminMux.Method(http.MethodPost, "/asset_mappings/{id}/disable", serverConfig.DisableAssetMappingHandler)
+	adminMux.Method(http.MethodGet, "/signature_requests", serverConfig.ListSignatureRequestsHandler)
+	adminMux.Method(http.MethodGet, "/signature_requests/{deposit_chain}/{action}/{deposit_id}", serverConfig.GetSignatureRequestHandler)
+	adminMux.Method(http.MethodPost, "/signature_requests/{deposit_chain}/{action}/{deposit_id}/retry", serverConfig.RetrySignatureRequestHandler)
+	adminMux.Method(http.MethodPost, "/signature_requests/{deposit_chain}/{action}/{deposit_id}/cancel", serverConfig.CancelSignatureRequestHandler)
 
 	s.adminServer.Handler = adminMux
 }
@@ -34,7 +34,7 @@ func TestEthereumToStellarWithdrawal(t *testing.T) {
 
 	itest := NewIntegrationTest(t, Config{
 		Servers:                servers,
-		EthereumFinalityBuffer: 0,
+		EthereumFinalityBuffer: 1,
 		WithdrawalWindow:       time.Hour,
 	})
 
@@ -91,7 +91,7 @@ func TestEthereumRefund(t *testing.T) {
 
 	itest := NewIntegrationTest(t, Config{
 		Servers:                servers,
-		EthereumFinalityBuffer: 0,
+		EthereumFinalityBuffer: 1,
 		WithdrawalWindow:       time.Second,
 	})
 
@@ -147,7 +147,7 @@ func TestStellarToEthereumWithdrawal(t *testing.T) {
 
 	itest := NewIntegrationTest(t, Config{
 		Servers:                servers,
-		EthereumFinalityBuffer: 0,
+		EthereumFinalityBuffer: 1,
 		WithdrawalWindow:       time.Hour,
 	})
 
@@ -174,12 +174,13 @@ func TestStellarToEthereumWithdrawal(t *testing.T) {
 		time.Sleep(time.Second)
 	}
 
-	_, err = itest.bridgeClient.SubmitStellarRefund(tx.Hash)
+	_, err = itest.bridgeClient.SubmitStellarRefund(tx.Hash, 0)
 	require.EqualError(t, err, "problem: https://stellar.org/horizon-errors/withdrawal_window_still_active")
 
 	_, err = itest.bridgeClient.SubmitEthereumWithdrawal(
 		context.Background(),
 		tx.Hash,
+		0,
 		gasPrice,
 	)
 	require.NoError(t, err)
@@ -190,7 +191,7 @@ func TestStellarRefund(t *testing.T) {
 
 	itest := NewIntegrationTest(t, Config{
 		Servers:                servers,
-		EthereumFinalityBuffer: 0,
+		EthereumFinalityBuffer: 1,
 		WithdrawalWindow:       time.Second,
 	})
 
@@ -221,7 +222,7 @@ func TestStellarRefund(t *testing.T) {
 		time.Sleep(time.Second)
 	}
 
-	_, err = itest.bridgeClient.SubmitStellarRefund(tx.Hash)
+	_, err = itest.bridgeClient.SubmitStellarRefund(tx.Hash, 0)
 	require.EqualError(t, err, "problem: https://stellar.org/horizon-errors/withdrawal_window_still_active")
 
 	// Wait for WithdrawalWindow to pass in Ethereum
@@ -245,7 +246,7 @@ func TestStellarRefund(t *testing.T) {
 
 	t.Log("Ethereum time reached withdrawal deadline")
 
-	refundTx, err := itest.bridgeClient.SubmitStellarRefund(tx.Hash)
+	refundTx, err := itest.bridgeClient.SubmitStellarRefund(tx.Hash, 0)
 	require.NoError(t, err)
 	memoBytes, err := base64.StdEncoding.DecodeString(refundTx.Memo)
 	require.NoError(t, err)
@@ -268,6 +269,6 @@ func TestStellarRefund(t *testing.T) {
 	}
 	require.Equal(t, servers, numFound)
 
-	_, err = itest.bridgeClient.SubmitStellarRefund(tx.Hash)
+	_, err = itest.bridgeClient.SubmitStellarRefund(tx.Hash, 0)
 	require.EqualError(t, err, "problem: https://stellar.org/horizon-errors/refund_already_executed")
 }
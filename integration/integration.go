@@ -2,6 +2,7 @@
 package integration
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -18,7 +19,9 @@ import (
 	"github.com/stellar/starbridge/client"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/stellar/starbridge/backend"
+	"github.com/stellar/starbridge/ethereum"
 
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/sync/errgroup"
@@ -112,6 +115,7 @@ func NewIntegrationTest(t *testing.T, config Config) *Test {
 	test.runComposeCommand("up", "--detach", "--no-color", "ethereum-node")
 	test.runComposeCommand("up", "--no-color", "deploy-ethereum-contract")
 	test.prepareShutdownHandlers()
+	test.mineEthereumBlocks()
 	ingestSequence := test.waitForHorizon()
 	test.waitForFriendbot()
 
@@ -234,6 +238,35 @@ func (i *Test) prepareShutdownHandlers() {
 	}()
 }
 
+// mineEthereumBlocks closes an Ethereum block every second. The hardhat node
+// only mines blocks containing transactions so blocks would otherwise never
+// be buried under the finality depth.
+func (i *Test) mineEthereumBlocks() {
+	ethClient, err := rpc.DialContext(context.Background(), EthereumRPCURL)
+	if err != nil {
+		i.t.Fatalf("Failed to connect to the Ethereum node: %v", err)
+	}
+	done := make(chan struct{})
+	i.shutdownCalls = append(i.shutdownCalls, func() {
+		close(done)
+		ethClient.Close()
+	})
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := ethClient.Call(nil, "evm_mine"); err != nil {
+					i.t.Logf("Failed to mine Ethereum block: %v", err)
+				}
+			}
+		}
+	}()
+}
+
 // Shutdown stops the integration tests and destroys all its associated
 // resources. It will be implicitly called when the calling test (i.e. the
 // `testing.Test` passed to `New()`) is finished if it hasn't been explicitly
@@ -261,7 +294,8 @@ func (i *Test) StartStarbridge(id int, config Config, ingestSequence uint32) err
 		EthereumBridgeAddress:       EthereumBridgeAddress,
 		EthereumBridgeConfigVersion: 0,
 		EthereumPrivateKey:          ethPrivateKeys[id],
-		EthereumFinalityBuffer:      0,
+		EthereumFinalityMode:        string(ethereum.FixedDepthFinality),
+		EthereumFinalityBuffer:      config.EthereumFinalityBuffer,
 		WithdrawalWindow:            config.WithdrawalWindow,
 		AssetMapping: []backend.AssetMappingConfigEntry{
 			{
@@ -277,14 +311,12 @@ func (i *Test) StartStarbridge(id int, config Config, ingestSequence uint32) err
 		},
 	})
 
-	i.runningApps.Add(2)
-	go func() {
-		defer i.runningApps.Done()
-		i.app[id].RunHTTPServer()
-	}()
+	// Run starts the same services as the starbridge command, including the
+	// signature notifier, horizon pool and asset mapping reloads
+	i.runningApps.Add(1)
 	go func() {
 		defer i.runningApps.Done()
-		i.app[id].RunBackendWorker()
+		i.app[id].Run()
 	}()
 
 	return nil
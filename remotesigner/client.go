@@ -0,0 +1,213 @@
+// Package remotesigner implements key backends which delegate signing to a
+// remote signer service over HTTP so that validators never hold their
+// private keys.
+//
+// The remote signer exposes the following endpoints for every key it holds:
+//
+//	GET  /keys/{key_id}       returns {"address": "..."}
+//	POST /keys/{key_id}/sign  accepts {"hash": "<hex>"} and returns {"signature": "<hex>"}
+//
+// Ethereum signatures are returned in the [R || S || V] format where V is 0
+// or 1. Stellar signatures are raw ed25519 signatures.
+package remotesigner
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// AddressResponse is the response of the key endpoint
+type AddressResponse struct {
+	Address string `json:"address"`
+}
+
+// SignRequest is the request body of the sign endpoint
+type SignRequest struct {
+	Hash string `json:"hash"`
+}
+
+// SignResponse is the response of the sign endpoint
+type SignResponse struct {
+	Signature string `json:"signature"`
+}
+
+// Client is used to communicate with a remote signer
+type Client struct {
+	// URL is the base url of the remote signer
+	URL string
+	// KeyID identifies the key held by the remote signer
+	KeyID string
+	// AuthToken is sent as a bearer token if it is not empty
+	AuthToken string
+	// HTTP is the http client used to send requests
+	HTTP *http.Client
+	// Timeout is the maximum duration of a single request
+	Timeout time.Duration
+}
+
+// NewClient constructs a Client with sensible defaults
+func NewClient(signerURL, keyID, authToken string) *Client {
+	return &Client{
+		URL:       signerURL,
+		KeyID:     keyID,
+		AuthToken: authToken,
+		HTTP:      http.DefaultClient,
+		Timeout:   10 * time.Second,
+	}
+}
+
+// Address fetches the address of the remote key
+func (c *Client) Address(ctx context.Context) (string, error) {
+	var response AddressResponse
+	if err := c.do(ctx, http.MethodGet, "", nil, &response); err != nil {
+		return "", err
+	}
+	return response.Address, nil
+}
+
+// Sign asks the remote signer to sign the given hash
+func (c *Client) Sign(ctx context.Context, hash []byte) ([]byte, error) {
+	var response SignResponse
+	err := c.do(ctx, http.MethodPost, "/sign", SignRequest{Hash: hex.EncodeToString(hash)}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(strings.TrimPrefix(response.Signature, "0x"))
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, response interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return err
+		}
+	}
+
+	endpoint := strings.TrimRight(c.URL, "/") + "/keys/" + url.PathEscape(c.KeyID) + path
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, &reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.AuthToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.AuthToken)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "error sending request to remote signer")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("remote signer returned status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(response)
+}
+
+// EthereumBackend is an ethereum.KeyBackend which signs using a remote signer
+type EthereumBackend struct {
+	client  *Client
+	address common.Address
+}
+
+// NewEthereumBackend constructs an EthereumBackend. The address of the
+// remote key is fetched once during construction.
+func NewEthereumBackend(ctx context.Context, client *Client) (*EthereumBackend, error) {
+	address, err := client.Address(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("remote signer returned invalid ethereum address: %s", address)
+	}
+	return &EthereumBackend{
+		client:  client,
+		address: common.HexToAddress(address),
+	}, nil
+}
+
+// Address returns the ethereum address of the remote key
+func (b *EthereumBackend) Address() common.Address {
+	return b.address
+}
+
+// SignHash signs the given digest with the remote key. The signature is
+// verified against the address of the remote key before it is returned.
+func (b *EthereumBackend) SignHash(hash []byte) ([]byte, error) {
+	sig, err := b.client.Sign(context.Background(), hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != crypto.SignatureLength {
+		return nil, fmt.Errorf("invalid signature length: %d", len(sig))
+	}
+	pubKey, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid signature from remote signer")
+	}
+	if crypto.PubkeyToAddress(*pubKey) != b.address {
+		return nil, fmt.Errorf("remote signer signed with a different key")
+	}
+	return sig, nil
+}
+
+// StellarBackend is a signer.KeyBackend which signs using a remote signer
+type StellarBackend struct {
+	client *Client
+	kp     *keypair.FromAddress
+}
+
+// NewStellarBackend constructs a StellarBackend. The address of the remote
+// key is fetched once during construction.
+func NewStellarBackend(ctx context.Context, client *Client) (*StellarBackend, error) {
+	address, err := client.Address(ctx)
+	if err != nil {
+		return nil, err
+	}
+	kp, err := keypair.ParseAddress(address)
+	if err != nil {
+		return nil, errors.Wrap(err, "remote signer returned invalid stellar address")
+	}
+	return &StellarBackend{
+		client: client,
+		kp:     kp,
+	}, nil
+}
+
+// Address returns the Stellar account id of the remote key
+func (b *StellarBackend) Address() string {
+	return b.kp.Address()
+}
+
+// SignDecorated signs the given hash with the remote key. The signature is
+// verified against the public key of the remote key before it is returned.
+func (b *StellarBackend) SignDecorated(hash []byte) (xdr.DecoratedSignature, error) {
+	sig, err := b.client.Sign(context.Background(), hash)
+	if err != nil {
+		return xdr.DecoratedSignature{}, err
+	}
+	if err = b.kp.Verify(hash, sig); err != nil {
+		return xdr.DecoratedSignature{}, errors.Wrap(err, "invalid signature from remote signer")
+	}
+	return xdr.DecoratedSignature{
+		Hint:      xdr.SignatureHint(b.kp.Hint()),
+		Signature: xdr.Signature(sig),
+	}, nil
+}
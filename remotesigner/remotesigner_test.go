@@ -0,0 +1,92 @@
+package remotesigner
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/starbridge/ethereum"
+	"github.com/stellar/starbridge/stellar/signer"
+)
+
+func startServer(t *testing.T) (*httptest.Server, *ecdsa.PrivateKey, *keypair.Full) {
+	ethKey, err := crypto.HexToECDSA("51138e68e8a5fa906d38c5b42bc01b805d7adb3fce037743fb406bb10aa83307")
+	require.NoError(t, err)
+	stellarKey := keypair.MustParseFull("SCSTO3PMPM2BNLR2MYKVHWCJ2FNHQGFWKPOFH6UX4N3HO6HMK4JBSJ6F")
+
+	server := httptest.NewServer((&Server{
+		EthereumKeys: map[string]*ecdsa.PrivateKey{"eth": ethKey},
+		StellarKeys:  map[string]*keypair.Full{"stellar": stellarKey},
+		AuthToken:    "secret",
+	}).Handler())
+	t.Cleanup(server.Close)
+	return server, ethKey, stellarKey
+}
+
+func TestEthereumBackend(t *testing.T) {
+	server, ethKey, _ := startServer(t)
+
+	remoteBackend, err := NewEthereumBackend(context.Background(), NewClient(server.URL, "eth", "secret"))
+	require.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(ethKey.PublicKey), remoteBackend.Address())
+
+	localSigner, err := ethereum.NewSigner(common.Bytes2Hex(crypto.FromECDSA(ethKey)), 0)
+	require.NoError(t, err)
+	remoteSigner := ethereum.NewSignerWithBackend(remoteBackend, 0)
+
+	id := common.HexToHash("0x01")
+	recipient := common.HexToAddress("0x02")
+	token := common.HexToAddress("0x03")
+	expected, err := localSigner.SignWithdrawal(id, 100, recipient, token, big.NewInt(5))
+	require.NoError(t, err)
+	sig, err := remoteSigner.SignWithdrawal(id, 100, recipient, token, big.NewInt(5))
+	require.NoError(t, err)
+	assert.Equal(t, expected, sig)
+}
+
+func TestStellarBackend(t *testing.T) {
+	server, _, stellarKey := startServer(t)
+
+	remoteBackend, err := NewStellarBackend(context.Background(), NewClient(server.URL, "stellar", "secret"))
+	require.NoError(t, err)
+	assert.Equal(t, stellarKey.Address(), remoteBackend.Address())
+
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        &txnbuild.SimpleAccount{AccountID: stellarKey.Address(), Sequence: 1},
+		IncrementSequenceNum: true,
+		BaseFee:              txnbuild.MinBaseFee,
+		Preconditions:        txnbuild.Preconditions{TimeBounds: txnbuild.NewInfiniteTimeout()},
+		Operations: []txnbuild.Operation{
+			&txnbuild.BumpSequence{BumpTo: 10},
+		},
+	})
+	require.NoError(t, err)
+	envelope := tx.ToXDR()
+
+	localSigner := signer.Signer{NetworkPassphrase: network.TestNetworkPassphrase, Signer: stellarKey}
+	remoteSigner := signer.Signer{NetworkPassphrase: network.TestNetworkPassphrase, Signer: remoteBackend}
+	expected, err := localSigner.Sign(envelope)
+	require.NoError(t, err)
+	sig, err := remoteSigner.Sign(envelope)
+	require.NoError(t, err)
+	assert.Equal(t, expected, sig)
+}
+
+func TestUnauthorized(t *testing.T) {
+	server, _, _ := startServer(t)
+
+	_, err := NewEthereumBackend(context.Background(), NewClient(server.URL, "eth", "wrong"))
+	assert.EqualError(t, err, "remote signer returned status 401")
+	_, err = NewStellarBackend(context.Background(), NewClient(server.URL, "missing", "secret"))
+	assert.EqualError(t, err, "remote signer returned status 404")
+}
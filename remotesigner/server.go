@@ -0,0 +1,97 @@
+package remotesigner
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-chi/chi"
+	"github.com/stellar/go/keypair"
+)
+
+// Server is a minimal remote signer which holds its keys in memory.
+// It is intended for tests and local development only.
+type Server struct {
+	// EthereumKeys maps key ids to ethereum private keys
+	EthereumKeys map[string]*ecdsa.PrivateKey
+	// StellarKeys maps key ids to Stellar keypairs
+	StellarKeys map[string]*keypair.Full
+	// AuthToken is the bearer token required from clients. Authentication
+	// is disabled if it is empty.
+	AuthToken string
+}
+
+// Handler returns the http handler serving the remote signer endpoints
+func (s *Server) Handler() http.Handler {
+	mux := chi.NewMux()
+	mux.Get("/keys/{key_id}", s.address)
+	mux.Post("/keys/{key_id}/sign", s.sign)
+	return mux
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	return s.AuthToken == "" || r.Header.Get("Authorization") == "Bearer "+s.AuthToken
+}
+
+func (s *Server) address(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	keyID := chi.URLParam(r, "key_id")
+	if key, ok := s.EthereumKeys[keyID]; ok {
+		writeJSON(w, AddressResponse{Address: crypto.PubkeyToAddress(key.PublicKey).String()})
+	} else if kp, ok := s.StellarKeys[keyID]; ok {
+		writeJSON(w, AddressResponse{Address: kp.Address()})
+	} else {
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *Server) sign(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var request SignRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	hash, err := hex.DecodeString(strings.TrimPrefix(request.Hash, "0x"))
+	if err != nil || len(hash) != 32 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var sig []byte
+	keyID := chi.URLParam(r, "key_id")
+	if key, ok := s.EthereumKeys[keyID]; ok {
+		sig, err = crypto.Sign(hash, key)
+	} else if kp, ok := s.StellarKeys[keyID]; ok {
+		sig, err = kp.Sign(hash)
+	} else {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, SignResponse{Signature: hex.EncodeToString(sig)})
+}
+
+func writeJSON(w http.ResponseWriter, response interface{}) {
+	responseBytes, err := json.Marshal(response)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	} else {
+		_, _ = w.Write(responseBytes)
+	}
+}
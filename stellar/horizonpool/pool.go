@@ -0,0 +1,220 @@
+package horizonpool
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/log"
+)
+
+const (
+	// DefaultTimeout is the timeout of a single request to Horizon
+	DefaultTimeout = 10 * time.Second
+	// healthCheckInterval is the interval between health checks
+	healthCheckInterval = 10 * time.Second
+	// maxLedgerLag is the number of ledgers a Horizon instance can be
+	// behind the most recent instance before it is considered unhealthy
+	maxLedgerLag = 5
+)
+
+type endpoint struct {
+	url          string
+	healthy      bool
+	latestLedger int32
+}
+
+// Pool is a horizonclient.HTTP which sends requests to the first healthy
+// Horizon instance from a list and fails over to the next ones when
+// requests fail. Instances are considered unhealthy when they cannot be
+// reached, return server errors or lag behind other instances.
+type Pool struct {
+	http *http.Client
+	log  *log.Entry
+
+	lock      sync.Mutex
+	endpoints []endpoint
+}
+
+// NewPool creates a Pool with the given Horizon urls in order of preference
+func NewPool(urls []string, timeout time.Duration) (*Pool, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("at least one horizon url is required")
+	}
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	p := &Pool{
+		http: &http.Client{Timeout: timeout},
+		log:  log.WithField("service", "horizon_pool"),
+	}
+	for _, rawURL := range urls {
+		if _, err := url.Parse(rawURL); err != nil {
+			return nil, errors.Wrapf(err, "invalid horizon url: %s", rawURL)
+		}
+		if !strings.HasSuffix(rawURL, "/") {
+			rawURL += "/"
+		}
+		p.endpoints = append(p.endpoints, endpoint{url: rawURL, healthy: true})
+	}
+	return p, nil
+}
+
+// URL returns the url which should be used as the HorizonURL of clients
+// using the pool. Requests to it are routed to the healthy instances.
+func (p *Pool) URL() string {
+	return p.endpoints[0].url
+}
+
+// Client returns a horizonclient.Client using the pool
+func (p *Pool) Client() *horizonclient.Client {
+	return &horizonclient.Client{
+		HorizonURL: p.URL(),
+		HTTP:       p,
+	}
+}
+
+// Run performs health checks periodically until the context is cancelled
+func (p *Pool) Run(ctx context.Context) {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.CheckHealth(ctx)
+		}
+	}
+}
+
+// CheckHealth queries the root resource of every Horizon instance and
+// updates their health
+func (p *Pool) CheckHealth(ctx context.Context) {
+	p.lock.Lock()
+	endpoints := append([]endpoint(nil), p.endpoints...)
+	p.lock.Unlock()
+
+	var latestLedger int32
+	errs := make([]error, len(endpoints))
+	for i := range endpoints {
+		client := &horizonclient.Client{HorizonURL: endpoints[i].url, HTTP: p.http}
+		root, err := client.Root()
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		endpoints[i].latestLedger = root.HorizonSequence
+		if root.HorizonSequence > latestLedger {
+			latestLedger = root.HorizonSequence
+		}
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for i, e := range endpoints {
+		healthy := errs[i] == nil && e.latestLedger+maxLedgerLag >= latestLedger
+		if !healthy {
+			p.log.WithFields(log.F{
+				"url":           e.url,
+				"error":         errs[i],
+				"latest_ledger": e.latestLedger,
+			}).Warn("Horizon instance is unhealthy")
+		}
+		p.endpoints[i].healthy = healthy
+		p.endpoints[i].latestLedger = e.latestLedger
+	}
+}
+
+// candidates returns the indexes of the endpoints in the order they should
+// be tried: healthy endpoints first, unhealthy ones as a last resort
+func (p *Pool) candidates() []int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	var healthy, unhealthy []int
+	for i, e := range p.endpoints {
+		if e.healthy {
+			healthy = append(healthy, i)
+		} else {
+			unhealthy = append(unhealthy, i)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+func (p *Pool) markUnhealthy(i int, err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.endpoints[i].healthy {
+		p.log.WithFields(log.F{"url": p.endpoints[i].url, "error": err}).
+			Warn("Request to Horizon failed, failing over")
+	}
+	p.endpoints[i].healthy = false
+}
+
+// Do sends the request to the first healthy Horizon instance. Requests
+// which fail or return a server error are retried on the next instances.
+func (p *Pool) Do(req *http.Request) (*http.Response, error) {
+	path := strings.TrimPrefix(req.URL.String(), p.URL())
+	candidates := p.candidates()
+	for n, i := range candidates {
+		attempt, err := p.rewrite(req, p.endpoints[i].url+path)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := p.http.Do(attempt)
+		if err == nil && resp.StatusCode < http.StatusInternalServerError {
+			return resp, nil
+		}
+		if n == len(candidates)-1 {
+			return resp, err
+		}
+		if err == nil {
+			err = errors.Errorf("server error: %s", resp.Status)
+			resp.Body.Close()
+		}
+		p.markUnhealthy(i, err)
+	}
+	// unreachable, there is always at least one endpoint
+	return nil, errors.New("no horizon instances")
+}
+
+func (p *Pool) rewrite(req *http.Request, rawURL string) (*http.Request, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid url: %s", rawURL)
+	}
+	attempt := req.Clone(req.Context())
+	attempt.URL = u
+	attempt.Host = ""
+	if req.GetBody != nil {
+		if attempt.Body, err = req.GetBody(); err != nil {
+			return nil, errors.Wrap(err, "error copying request body")
+		}
+	}
+	return attempt, nil
+}
+
+func (p *Pool) Get(url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return p.Do(req)
+}
+
+func (p *Pool) PostForm(url string, data url.Values) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return p.Do(req)
+}
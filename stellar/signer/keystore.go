@@ -0,0 +1,96 @@
+package signer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/errors"
+)
+
+// keystoreVersion is the version of the geth keystore format
+// used to encrypt Stellar keys
+const keystoreVersion = 3
+
+// encryptedKey is the JSON representation of an encrypted Stellar key. It
+// follows the geth keystore format with the raw ed25519 seed as the
+// encrypted payload.
+type encryptedKey struct {
+	Address string              `json:"address"`
+	Crypto  keystore.CryptoJSON `json:"crypto"`
+	Version int                 `json:"version"`
+}
+
+// EncryptKey encrypts the given keypair with the password using the geth
+// keystore format
+func EncryptKey(kp *keypair.Full, password string) ([]byte, error) {
+	rawSeed, err := strkey.Decode(strkey.VersionByteSeed, kp.Seed())
+	if err != nil {
+		return nil, err
+	}
+	cryptoJSON, err := keystore.EncryptDataV3(
+		rawSeed,
+		[]byte(password),
+		keystore.StandardScryptN,
+		keystore.StandardScryptP,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(encryptedKey{
+		Address: kp.Address(),
+		Crypto:  cryptoJSON,
+		Version: keystoreVersion,
+	})
+}
+
+// DecryptKey decrypts a keypair which was encrypted with EncryptKey
+func DecryptKey(keyJSON []byte, password string) (*keypair.Full, error) {
+	var key encryptedKey
+	if err := json.Unmarshal(keyJSON, &key); err != nil {
+		return nil, errors.Wrap(err, "error parsing keystore json")
+	}
+	if key.Version != keystoreVersion {
+		return nil, errors.Errorf("unsupported keystore version: %d", key.Version)
+	}
+
+	seed, err := keystore.DecryptDataV3(key.Crypto, password)
+	if err != nil {
+		return nil, err
+	}
+	if len(seed) != 32 {
+		return nil, errors.Errorf("invalid seed length: %d", len(seed))
+	}
+	var rawSeed [32]byte
+	copy(rawSeed[:], seed)
+	kp, err := keypair.FromRawSeed(rawSeed)
+	if err != nil {
+		return nil, err
+	}
+	if kp.Address() != key.Address {
+		return nil, errors.Errorf("keystore address %s does not match decrypted key", key.Address)
+	}
+	return kp, nil
+}
+
+// NewKeystoreBackend loads an encrypted Stellar key from keystorePath. The
+// password used to decrypt the keystore is read from passwordPath.
+func NewKeystoreBackend(keystorePath, passwordPath string) (*keypair.Full, error) {
+	keyJSON, err := ioutil.ReadFile(keystorePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading keystore file")
+	}
+	password, err := ioutil.ReadFile(passwordPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading password file")
+	}
+
+	kp, err := DecryptKey(keyJSON, strings.TrimRight(string(password), "\r\n"))
+	if err != nil {
+		return nil, errors.Wrap(err, "error decrypting keystore file")
+	}
+	return kp, nil
+}
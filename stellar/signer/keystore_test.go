@@ -0,0 +1,49 @@
+package signer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	kp := keypair.MustRandom()
+	keyJSON, err := EncryptKey(kp, "password")
+	require.NoError(t, err)
+	assert.NotContains(t, string(keyJSON), kp.Seed())
+
+	decrypted, err := DecryptKey(keyJSON, "password")
+	require.NoError(t, err)
+	assert.Equal(t, kp.Seed(), decrypted.Seed())
+
+	_, err = DecryptKey(keyJSON, "wrong password")
+	assert.Error(t, err)
+
+	var key encryptedKey
+	require.NoError(t, json.Unmarshal(keyJSON, &key))
+	key.Address = keypair.MustRandom().Address()
+	tampered, err := json.Marshal(key)
+	require.NoError(t, err)
+	_, err = DecryptKey(tampered, "password")
+	assert.EqualError(t, err, "keystore address "+key.Address+" does not match decrypted key")
+
+	key.Version = 4
+	unsupported, err := json.Marshal(key)
+	require.NoError(t, err)
+	_, err = DecryptKey(unsupported, "password")
+	assert.EqualError(t, err, "unsupported keystore version: 4")
+
+	dir := t.TempDir()
+	keystorePath := filepath.Join(dir, "keystore.json")
+	passwordPath := filepath.Join(dir, "password")
+	require.NoError(t, ioutil.WriteFile(keystorePath, keyJSON, 0600))
+	require.NoError(t, ioutil.WriteFile(passwordPath, []byte("password\n"), 0600))
+	loaded, err := NewKeystoreBackend(keystorePath, passwordPath)
+	require.NoError(t, err)
+	assert.Equal(t, kp.Seed(), loaded.Seed())
+}
@@ -1,15 +1,23 @@
 package signer
 
 import (
-	"github.com/stellar/go/keypair"
 	"github.com/stellar/go/network"
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/xdr"
 )
 
+// KeyBackend holds the private key of a Stellar validator account and
+// signs transaction hashes with it. *keypair.Full implements KeyBackend.
+type KeyBackend interface {
+	// Address returns the Stellar account id corresponding to the key
+	Address() string
+	// SignDecorated signs the given transaction hash
+	SignDecorated(hash []byte) (xdr.DecoratedSignature, error)
+}
+
 type Signer struct {
 	NetworkPassphrase string
-	Signer            *keypair.Full
+	Signer            KeyBackend
 }
 
 // Sign signs an envelope.
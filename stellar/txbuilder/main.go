@@ -14,6 +14,26 @@ type Builder struct {
 
 // BuildTransaction builds a transaction. It does not check if expirationTimestamp is valid.
 func (b *Builder) BuildTransaction(asset, txSource, destination, amount string, sequence, expirationTimestamp int64, memoHash []byte) (xdr.TransactionEnvelope, error) {
+	return b.BuildWithdrawalTransaction(
+		txSource,
+		[]txnbuild.Operation{
+			&txnbuild.Payment{
+				SourceAccount: b.BridgeAccount,
+				Amount:        amount,
+				Destination:   destination,
+				Asset:         parseAsset(asset),
+			},
+		},
+		sequence,
+		expirationTimestamp,
+		memoHash,
+	)
+}
+
+// BuildWithdrawalTransaction builds a transaction containing the given
+// operations, usually returned by WithdrawalOperations. It does not check if
+// expirationTimestamp is valid.
+func (b *Builder) BuildWithdrawalTransaction(txSource string, operations []txnbuild.Operation, sequence, expirationTimestamp int64, memoHash []byte) (xdr.TransactionEnvelope, error) {
 	if txSource == b.BridgeAccount {
 		return xdr.TransactionEnvelope{}, errors.New("bridge account cannot be used as a transaction source")
 	}
@@ -26,30 +46,131 @@ func (b *Builder) BuildTransaction(asset, txSource, destination, amount string,
 	var memoHashArray txnbuild.MemoHash
 	copy(memoHashArray[:], memoHash)
 
-	var txAsset txnbuild.Asset
-	if asset == "native" {
-		txAsset = txnbuild.NativeAsset{}
-	} else {
-		parts := strings.Split(asset, ":")
-		txAsset = txnbuild.CreditAsset{
-			Code:   parts[0],
-			Issuer: parts[1],
-		}
+	tx, err := txnbuild.NewTransaction(
+		txnbuild.TransactionParams{
+			SourceAccount: &sourceAccount,
+			Memo:          memoHashArray,
+			Operations:    operations,
+			BaseFee:       txnbuild.MinBaseFee,
+			Preconditions: txnbuild.Preconditions{
+				TimeBounds: txnbuild.NewTimebounds(0, expirationTimestamp),
+			},
+		},
+	)
+	if err != nil {
+		return xdr.TransactionEnvelope{}, errors.Wrap(err, "error building transaction")
+	}
+
+	return tx.ToXDR(), nil
+}
+
+// BuildClaimableBalanceTransaction builds a transaction sourced from the
+// bridge account which creates a claimable balance for the destination. The
+// destination can claim the balance at any time and does not have to exist
+// or trust the asset when the transaction is executed. The transaction is
+// valid as long as the sequence number of the bridge account is at least
+// minSequence and lower than sequence, so bridge transactions with a lower
+// sequence number do not invalidate it. It does not check if
+// expirationTimestamp is valid.
+func (b *Builder) BuildClaimableBalanceTransaction(asset, destination, amount string, sequence, minSequence, expirationTimestamp int64, memoHash []byte) (xdr.TransactionEnvelope, error) {
+	if destination == b.BridgeAccount {
+		return xdr.TransactionEnvelope{}, errors.New("bridge account cannot be the destination")
+	}
+	if minSequence >= sequence {
+		return xdr.TransactionEnvelope{}, errors.New("minimum sequence must be lower than the sequence")
 	}
 
+	sourceAccount := txnbuild.SimpleAccount{
+		AccountID: b.BridgeAccount,
+		Sequence:  sequence,
+	}
+
+	var memoHashArray txnbuild.MemoHash
+	copy(memoHashArray[:], memoHash)
+
 	tx, err := txnbuild.NewTransaction(
 		txnbuild.TransactionParams{
 			SourceAccount: &sourceAccount,
 			Memo:          memoHashArray,
 			Operations: []txnbuild.Operation{
-				&txnbuild.Payment{
-					SourceAccount: b.BridgeAccount,
-					Amount:        amount,
-					Destination:   destination,
-					Asset:         txAsset,
+				&txnbuild.CreateClaimableBalance{
+					Amount: amount,
+					Asset:  parseAsset(asset),
+					Destinations: []txnbuild.Claimant{
+						txnbuild.NewClaimant(destination, nil),
+					},
 				},
 			},
 			BaseFee: txnbuild.MinBaseFee,
+			Preconditions: txnbuild.Preconditions{
+				TimeBounds:        txnbuild.NewTimebounds(0, expirationTimestamp),
+				MinSequenceNumber: &minSequence,
+			},
+		},
+	)
+	if err != nil {
+		return xdr.TransactionEnvelope{}, errors.Wrap(err, "error building transaction")
+	}
+
+	return tx.ToXDR(), nil
+}
+
+func parseAsset(asset string) txnbuild.Asset {
+	if asset == "native" {
+		return txnbuild.NativeAsset{}
+	}
+	parts := strings.Split(asset, ":")
+	return txnbuild.CreditAsset{
+		Code:   parts[0],
+		Issuer: parts[1],
+	}
+}
+
+// BuildSetSignersTransaction builds a transaction which replaces the signers
+// of the bridge account with newSigners, each with a weight of 1. All
+// thresholds are set to minThreshold, matching the minimum threshold of the
+// bridge smart contract. It does not check if expirationTimestamp is valid.
+func (b *Builder) BuildSetSignersTransaction(currentSigners, newSigners []string, minThreshold uint8, sequence, expirationTimestamp int64) (xdr.TransactionEnvelope, error) {
+	if len(newSigners) == 0 {
+		return xdr.TransactionEnvelope{}, errors.New("at least one signer is required")
+	}
+
+	sourceAccount := txnbuild.SimpleAccount{
+		AccountID: b.BridgeAccount,
+		Sequence:  sequence,
+	}
+
+	isNewSigner := map[string]bool{}
+	var operations []txnbuild.Operation
+	for _, signer := range newSigners {
+		if signer == b.BridgeAccount {
+			return xdr.TransactionEnvelope{}, errors.New("bridge account cannot be used as a signer")
+		}
+		isNewSigner[signer] = true
+		operations = append(operations, &txnbuild.SetOptions{
+			Signer: &txnbuild.Signer{Address: signer, Weight: 1},
+		})
+	}
+	for _, signer := range currentSigners {
+		if isNewSigner[signer] || signer == b.BridgeAccount {
+			continue
+		}
+		operations = append(operations, &txnbuild.SetOptions{
+			Signer: &txnbuild.Signer{Address: signer, Weight: 0},
+		})
+	}
+	operations = append(operations, &txnbuild.SetOptions{
+		MasterWeight:    txnbuild.NewThreshold(0),
+		LowThreshold:    txnbuild.NewThreshold(txnbuild.Threshold(minThreshold)),
+		MediumThreshold: txnbuild.NewThreshold(txnbuild.Threshold(minThreshold)),
+		HighThreshold:   txnbuild.NewThreshold(txnbuild.Threshold(minThreshold)),
+	})
+
+	tx, err := txnbuild.NewTransaction(
+		txnbuild.TransactionParams{
+			SourceAccount: &sourceAccount,
+			Operations:    operations,
+			BaseFee:       txnbuild.MinBaseFee,
 			Preconditions: txnbuild.Preconditions{
 				TimeBounds: txnbuild.NewTimebounds(0, expirationTimestamp),
 			},
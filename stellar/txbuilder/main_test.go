@@ -0,0 +1,63 @@
+package txbuilder
+
+import (
+	"testing"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testBridgeAccount = "GCRCUEU6ONALGPIOULQWP45M753AV42IJAKAZYUVF55GHUEERAQMMLVV"
+	testRecipient     = "GCPEROUJKO733ME3FOCXCKUBANXLXCGEFEVPHVNSDWMHIJ73UYZ5HLZH"
+	testUSDC          = "USDC:GABPNFVU2NHSHRCJ4HLW6BAMVOT7YIHMJBBQ3DMSSPRKEYDSYGRE5JWH"
+)
+
+func TestBuildClaimableBalanceTransaction(t *testing.T) {
+	builder := &Builder{BridgeAccount: testBridgeAccount}
+	memoHash := make([]byte, 32)
+	memoHash[31] = 1
+
+	tx, err := builder.BuildClaimableBalanceTransaction(
+		testUSDC, testRecipient, "12.5", 110, 100, 1700000000, memoHash,
+	)
+	require.NoError(t, err)
+
+	// The transaction does not depend on the recipient account and remains
+	// valid after bridge transactions with a lower sequence number
+	assert.Equal(t, testBridgeAccount, tx.SourceAccount().ToAccountId().Address())
+	assert.Equal(t, int64(110), tx.SeqNum())
+	require.NotNil(t, tx.MinSeqNum())
+	assert.Equal(t, int64(100), *tx.MinSeqNum())
+	assert.Equal(t, xdr.TimePoint(1700000000), tx.TimeBounds().MaxTime)
+	hash, ok := tx.Memo().GetHash()
+	require.True(t, ok)
+	assert.Equal(t, memoHash, hash[:])
+
+	require.Len(t, tx.Operations(), 1)
+	op := tx.Operations()[0]
+	assert.Nil(t, op.SourceAccount)
+	createBalance := op.Body.MustCreateClaimableBalanceOp()
+	assert.Equal(t, testUSDC, createBalance.Asset.StringCanonical())
+	assert.Equal(t, "12.5000000", amount.String(createBalance.Amount))
+	require.Len(t, createBalance.Claimants, 1)
+	claimant := createBalance.Claimants[0].MustV0()
+	assert.Equal(t, testRecipient, claimant.Destination.Address())
+	assert.Equal(t, xdr.ClaimPredicateTypeClaimPredicateUnconditional, claimant.Predicate.Type)
+}
+
+func TestBuildClaimableBalanceTransactionInvalid(t *testing.T) {
+	builder := &Builder{BridgeAccount: testBridgeAccount}
+
+	_, err := builder.BuildClaimableBalanceTransaction(
+		"native", testBridgeAccount, "1", 2, 1, 1700000000, nil,
+	)
+	assert.EqualError(t, err, "bridge account cannot be the destination")
+
+	_, err = builder.BuildClaimableBalanceTransaction(
+		"native", testRecipient, "1", 2, 2, 1700000000, nil,
+	)
+	assert.EqualError(t, err, "minimum sequence must be lower than the sequence")
+}
@@ -0,0 +1,126 @@
+package txbuilder
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/txnbuild"
+)
+
+var (
+	// ErrRecipientNotFound is returned when the recipient account does not
+	// exist. Withdrawal transactions are sourced from the recipient so it
+	// must be created before withdrawing.
+	ErrRecipientNotFound = errors.New("recipient account does not exist")
+	// ErrTrustlineNotAuthorized is returned when the issuer has not
+	// authorized the recipient trustline
+	ErrTrustlineNotAuthorized = errors.New("recipient trustline is not authorized")
+	// ErrTrustlineLimitExceeded is returned when the recipient trustline
+	// cannot hold the amount
+	ErrTrustlineLimitExceeded = errors.New("amount exceeds the limit of the recipient trustline")
+)
+
+// Recipient is the state of the recipient of a payment from the bridge
+// account as seen by Horizon.
+type Recipient struct {
+	AccountID string
+	// Exists is false if the recipient account has not been created yet
+	Exists bool
+	// Account is the recipient account. It is only set if Exists is true.
+	Account horizon.Account
+	// Trustline is the balance of the asset held by the recipient. It is
+	// nil for the native asset or if the recipient does not trust the asset.
+	Trustline *horizon.Balance
+}
+
+// LoadRecipient loads the state of the recipient account from Horizon so
+// that the operations required to deliver the given asset can be determined.
+func LoadRecipient(client horizonclient.ClientInterface, asset, accountID string) (Recipient, error) {
+	recipient := Recipient{AccountID: accountID}
+	account, err := client.AccountDetail(horizonclient.AccountRequest{
+		AccountID: accountID,
+	})
+	if herr, ok := err.(*horizonclient.Error); ok && herr.Response.StatusCode == http.StatusNotFound {
+		return recipient, nil
+	} else if err != nil {
+		return recipient, errors.Wrap(err, "error getting account details")
+	}
+
+	recipient.Exists = true
+	recipient.Account = account
+	if asset == "native" {
+		return recipient, nil
+	}
+	creditAsset := parseAsset(asset)
+	for i, balance := range account.Balances {
+		if balance.Code == creditAsset.GetCode() && balance.Issuer == creditAsset.GetIssuer() {
+			recipient.Trustline = &account.Balances[i]
+			break
+		}
+	}
+	return recipient, nil
+}
+
+// WithdrawalOperations returns the operations which deliver the amount of
+// asset from the bridge account to the recipient:
+//   - a ChangeTrust operation sourced from the recipient followed by a
+//     Payment operation if the recipient does not trust the asset,
+//   - a single Payment operation otherwise.
+//
+// An error is returned if the withdrawal cannot succeed.
+func (b *Builder) WithdrawalOperations(recipient Recipient, asset, paymentAmount string) ([]txnbuild.Operation, error) {
+	stroops, err := amount.ParseInt64(paymentAmount)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid amount %s", paymentAmount)
+	}
+
+	payment := &txnbuild.Payment{
+		SourceAccount: b.BridgeAccount,
+		Amount:        paymentAmount,
+		Destination:   recipient.AccountID,
+		Asset:         parseAsset(asset),
+	}
+
+	if !recipient.Exists {
+		return nil, ErrRecipientNotFound
+	}
+
+	if asset == "native" {
+		return []txnbuild.Operation{payment}, nil
+	}
+
+	if recipient.Trustline == nil {
+		line, err := parseAsset(asset).ToChangeTrustAsset()
+		if err != nil {
+			return nil, errors.Wrap(err, "error converting asset")
+		}
+		return []txnbuild.Operation{
+			&txnbuild.ChangeTrust{
+				SourceAccount: recipient.AccountID,
+				Line:          line,
+				Limit:         txnbuild.MaxTrustlineLimit,
+			},
+			payment,
+		}, nil
+	}
+
+	if recipient.Trustline.IsAuthorized != nil && !*recipient.Trustline.IsAuthorized {
+		return nil, ErrTrustlineNotAuthorized
+	}
+	balance, err := amount.ParseInt64(recipient.Trustline.Balance)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid trustline balance")
+	}
+	limit, err := amount.ParseInt64(recipient.Trustline.Limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid trustline limit")
+	}
+	if stroops > limit-balance {
+		return nil, ErrTrustlineLimitExceeded
+	}
+
+	return []txnbuild.Operation{payment}, nil
+}
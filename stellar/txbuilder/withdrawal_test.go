@@ -0,0 +1,103 @@
+package txbuilder
+
+import (
+	"testing"
+
+	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithdrawalOperations(t *testing.T) {
+	builder := &Builder{BridgeAccount: testBridgeAccount}
+	authorized, unauthorized := true, false
+	trustline := func(balance, limit string, isAuthorized *bool) *horizon.Balance {
+		return &horizon.Balance{Balance: balance, Limit: limit, IsAuthorized: isAuthorized}
+	}
+
+	for _, testCase := range []struct {
+		name          string
+		recipient     Recipient
+		asset         string
+		amount        string
+		expectedTypes []string
+		expectedErr   error
+	}{
+		{
+			name:        "missing native recipient",
+			recipient:   Recipient{AccountID: testRecipient},
+			asset:       "native",
+			amount:      "100",
+			expectedErr: ErrRecipientNotFound,
+		},
+		{
+			name:        "missing credit recipient",
+			recipient:   Recipient{AccountID: testRecipient},
+			asset:       testUSDC,
+			amount:      "1",
+			expectedErr: ErrRecipientNotFound,
+		},
+		{
+			name:          "native",
+			recipient:     Recipient{AccountID: testRecipient, Exists: true},
+			asset:         "native",
+			amount:        "1",
+			expectedTypes: []string{"payment"},
+		},
+		{
+			name:          "missing trustline",
+			recipient:     Recipient{AccountID: testRecipient, Exists: true},
+			asset:         testUSDC,
+			amount:        "1",
+			expectedTypes: []string{"change_trust", "payment"},
+		},
+		{
+			name:          "authorized trustline",
+			recipient:     Recipient{AccountID: testRecipient, Exists: true, Trustline: trustline("1", "10", &authorized)},
+			asset:         testUSDC,
+			amount:        "9",
+			expectedTypes: []string{"payment"},
+		},
+		{
+			name:        "unauthorized trustline",
+			recipient:   Recipient{AccountID: testRecipient, Exists: true, Trustline: trustline("0", "10", &unauthorized)},
+			asset:       testUSDC,
+			amount:      "1",
+			expectedErr: ErrTrustlineNotAuthorized,
+		},
+		{
+			name:        "trustline limit exceeded",
+			recipient:   Recipient{AccountID: testRecipient, Exists: true, Trustline: trustline("1", "10", &authorized)},
+			asset:       testUSDC,
+			amount:      "9.0000001",
+			expectedErr: ErrTrustlineLimitExceeded,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			operations, err := builder.WithdrawalOperations(testCase.recipient, testCase.asset, testCase.amount)
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var types []string
+			for _, op := range operations {
+				switch op := op.(type) {
+				case *txnbuild.Payment:
+					assert.Equal(t, testBridgeAccount, op.SourceAccount)
+					assert.Equal(t, testRecipient, op.Destination)
+					assert.Equal(t, testCase.amount, op.Amount)
+					types = append(types, "payment")
+				case *txnbuild.ChangeTrust:
+					assert.Equal(t, testRecipient, op.SourceAccount)
+					types = append(types, "change_trust")
+				default:
+					t.Fatalf("unexpected operation %T", op)
+				}
+			}
+			assert.Equal(t, testCase.expectedTypes, types)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package txobserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stellar/go/historyarchive"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+)
+
+// ArchiveBackend is a LedgerBackend which reads transactions and results
+// from a history archive. Ledgers are only available once the checkpoint
+// containing them was published so the backend is mostly useful to replay
+// past ledgers.
+type ArchiveBackend struct {
+	Archive           historyarchive.ArchiveInterface
+	NetworkPassphrase string
+
+	// checkpoint holds the ledgers of the most recently fetched checkpoint
+	checkpoint map[uint32]*historyarchive.Ledger
+}
+
+func (b *ArchiveBackend) GetLedger(ctx context.Context, sequence uint32) (Ledger, error) {
+	archiveLedger, ok := b.checkpoint[sequence]
+	if !ok {
+		has, err := b.Archive.GetRootHAS()
+		if err != nil {
+			return Ledger{}, errors.Wrap(err, "error getting history archive state")
+		}
+		if sequence > has.CurrentLedger {
+			return Ledger{}, ErrLedgerNotClosed
+		}
+		b.checkpoint, err = b.Archive.GetLedgers(sequence, sequence)
+		if err != nil {
+			return Ledger{}, errors.Wrapf(err, "error getting checkpoint of ledger %d", sequence)
+		}
+		if archiveLedger, ok = b.checkpoint[sequence]; !ok {
+			return Ledger{}, errors.Errorf("ledger %d not found in history archive", sequence)
+		}
+	}
+
+	header := archiveLedger.Header
+	ledger := Ledger{
+		Sequence:  uint32(header.Header.LedgerSeq),
+		Hash:      header.Hash.HexString(),
+		CloseTime: time.Unix(int64(header.Header.ScpValue.CloseTime), 0).UTC(),
+	}
+
+	// results are stored in application order while the transaction set is
+	// ordered by hash
+	envelopes := map[xdr.Hash]xdr.TransactionEnvelope{}
+	for _, envelope := range archiveLedger.Transaction.TxSet.Txs {
+		hash, err := network.HashTransactionInEnvelope(envelope, b.NetworkPassphrase)
+		if err != nil {
+			return Ledger{}, errors.Wrapf(err, "error hashing transaction in ledger %d", sequence)
+		}
+		envelopes[hash] = envelope
+	}
+	for _, result := range archiveLedger.TransactionResult.TxResultSet.Results {
+		envelope, ok := envelopes[result.TransactionHash]
+		if !ok {
+			return Ledger{}, errors.Errorf(
+				"transaction %s not found in ledger %d",
+				result.TransactionHash.HexString(), sequence,
+			)
+		}
+		operations, err := transactionOperations(
+			result.TransactionHash,
+			envelope,
+			result.Result,
+			ledger.CloseTime,
+		)
+		if err != nil {
+			return Ledger{}, err
+		}
+		ledger.Operations = append(ledger.Operations, operations...)
+	}
+
+	return ledger, ctx.Err()
+}
@@ -0,0 +1,135 @@
+package txobserver
+
+import (
+	"context"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/xdr"
+)
+
+// ErrLedgerNotClosed is returned by a LedgerBackend when the requested
+// ledger has not been closed yet
+var ErrLedgerNotClosed = errors.New("ledger not closed yet")
+
+// LedgerBackend provides the contents of closed Stellar ledgers to the
+// Observer. Implementations are backed by Horizon, history archives or a
+// stream of LedgerCloseMeta produced by captive stellar-core.
+type LedgerBackend interface {
+	// GetLedger returns the ledger with the given sequence number or
+	// ErrLedgerNotClosed if it is not available yet.
+	GetLedger(ctx context.Context, sequence uint32) (Ledger, error)
+}
+
+// Ledger contains the operations of a closed Stellar ledger which can be
+// relevant to the bridge
+type Ledger struct {
+	Sequence uint32
+	// Hash is the hex encoded hash of the ledger header
+	Hash      string
+	CloseTime time.Time
+	// Operations are ordered by transaction application order and by
+	// index within each transaction
+	Operations []Operation
+}
+
+// Operation is a payment, path payment, create account or create claimable
+// balance operation included in a Stellar ledger
+type Operation struct {
+	Type xdr.OperationType
+	// TransactionHash is the hex encoded hash of the transaction
+	TransactionHash string
+	// Index is the index of the operation within its transaction
+	Index uint
+	// Successful is false if the transaction failed
+	Successful bool
+	Envelope   xdr.TransactionEnvelope
+	// LedgerCloseTime is the close time of the ledger which included the
+	// transaction
+	LedgerCloseTime time.Time
+	// From is the account which sent the funds
+	From string
+	// To is the account which received the funds. It is empty for
+	// claimable balances.
+	To string
+	// Asset is the asset received by To, either native or CODE:ISSUER
+	Asset string
+	// Amount is the amount received by To
+	Amount string
+}
+
+// transactionOperations extracts the operations relevant to the bridge from
+// a transaction and its result. Path payment strict send operations are
+// only included if the transaction was successful because the received
+// amount is only known from the result.
+func transactionOperations(
+	hash xdr.Hash,
+	envelope xdr.TransactionEnvelope,
+	result xdr.TransactionResult,
+	closeTime time.Time,
+) ([]Operation, error) {
+	txSource := envelope.SourceAccount().ToAccountId()
+	opResults, _ := result.OperationResults()
+	successful := result.Successful()
+
+	var operations []Operation
+	for i, op := range envelope.Operations() {
+		operation := Operation{
+			Type:            op.Body.Type,
+			TransactionHash: hex.EncodeToString(hash[:]),
+			Index:           uint(i),
+			Successful:      successful,
+			Envelope:        envelope,
+			LedgerCloseTime: closeTime,
+			From:            txSource.Address(),
+		}
+		if op.SourceAccount != nil {
+			operation.From = op.SourceAccount.ToAccountId().Address()
+		}
+
+		switch op.Body.Type {
+		case xdr.OperationTypePayment:
+			payment := op.Body.MustPaymentOp()
+			operation.To = payment.Destination.ToAccountId().Address()
+			operation.Asset = payment.Asset.StringCanonical()
+			operation.Amount = amount.String(payment.Amount)
+		case xdr.OperationTypePathPaymentStrictReceive:
+			payment := op.Body.MustPathPaymentStrictReceiveOp()
+			operation.To = payment.Destination.ToAccountId().Address()
+			operation.Asset = payment.DestAsset.StringCanonical()
+			operation.Amount = amount.String(payment.DestAmount)
+		case xdr.OperationTypePathPaymentStrictSend:
+			if !successful || i >= len(opResults) {
+				continue
+			}
+			payment := op.Body.MustPathPaymentStrictSendOp()
+			opResult, ok := opResults[i].GetTr()
+			if !ok {
+				return nil, errors.Errorf("missing result of operation %d in transaction %x", i, hash)
+			}
+			sendResult := opResult.MustPathPaymentStrictSendResult()
+			success, ok := sendResult.GetSuccess()
+			if !ok {
+				return nil, errors.Errorf("invalid result of operation %d in transaction %x", i, hash)
+			}
+			operation.To = payment.Destination.ToAccountId().Address()
+			operation.Asset = payment.DestAsset.StringCanonical()
+			operation.Amount = amount.String(success.Last.Amount)
+		case xdr.OperationTypeCreateAccount:
+			createAccount := op.Body.MustCreateAccountOp()
+			operation.To = createAccount.Destination.Address()
+			operation.Asset = "native"
+			operation.Amount = amount.String(createAccount.StartingBalance)
+		case xdr.OperationTypeCreateClaimableBalance:
+			createBalance := op.Body.MustCreateClaimableBalanceOp()
+			operation.Asset = createBalance.Asset.StringCanonical()
+			operation.Amount = amount.String(createBalance.Amount)
+		default:
+			continue
+		}
+		operations = append(operations, operation)
+	}
+	return operations, nil
+}
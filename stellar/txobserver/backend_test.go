@@ -0,0 +1,127 @@
+package txobserver
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Accounts used in testdata/ledger_1000.xdr. The ledger contains:
+//   - a single payment deposit with a hash memo
+//   - a transaction with two payments and a path payment to the bridge
+//   - a failed payment to the bridge
+//   - a withdrawal creating the trustline of the recipient
+const (
+	fixtureBridgeAccount = "GCRCUEU6ONALGPIOULQWP45M753AV42IJAKAZYUVF55GHUEERAQMMLVV"
+	fixtureSender        = "GD4N5QYZOOFXLRX2POOPOJYB2JBVJFAPPJ3UMBXTQO7TULEYHPKYF4BY"
+	fixtureRecipient     = "GCPEROUJKO733ME3FOCXCKUBANXLXCGEFEVPHVNSDWMHIJ73UYZ5HLZH"
+	fixtureUSDC          = "USDC:GABPNFVU2NHSHRCJ4HLW6BAMVOT7YIHMJBBQ3DMSSPRKEYDSYGRE5JWH"
+)
+
+// fixtureBackend is a ledgerbackend.LedgerBackend serving recorded ledgers
+type fixtureBackend struct {
+	ledgers map[uint32]xdr.LedgerCloseMeta
+	latest  uint32
+}
+
+func loadFixtureBackend(t *testing.T, paths ...string) *fixtureBackend {
+	backend := &fixtureBackend{ledgers: map[uint32]xdr.LedgerCloseMeta{}}
+	for _, path := range paths {
+		contents, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		var meta xdr.LedgerCloseMeta
+		require.NoError(t, xdr.SafeUnmarshalBase64(strings.TrimSpace(string(contents)), &meta))
+		backend.ledgers[meta.LedgerSequence()] = meta
+		if meta.LedgerSequence() > backend.latest {
+			backend.latest = meta.LedgerSequence()
+		}
+	}
+	return backend
+}
+
+func (b *fixtureBackend) GetLatestLedgerSequence(ctx context.Context) (uint32, error) {
+	return b.latest, nil
+}
+
+func (b *fixtureBackend) GetLedger(ctx context.Context, sequence uint32) (xdr.LedgerCloseMeta, error) {
+	return b.ledgers[sequence], nil
+}
+
+func (b *fixtureBackend) PrepareRange(ctx context.Context, ledgerRange ledgerbackend.Range) error {
+	return nil
+}
+
+func (b *fixtureBackend) IsPrepared(ctx context.Context, ledgerRange ledgerbackend.Range) (bool, error) {
+	return true, nil
+}
+
+func (b *fixtureBackend) Close() error {
+	return nil
+}
+
+func TestMetaBackend_GetLedger(t *testing.T) {
+	backend := &MetaBackend{
+		Backend:           loadFixtureBackend(t, "testdata/ledger_1000.xdr"),
+		NetworkPassphrase: network.TestNetworkPassphrase,
+	}
+
+	ledger, err := backend.GetLedger(context.Background(), 1000)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1000), ledger.Sequence)
+	assert.Equal(t, time.Unix(1656000000, 0).UTC(), ledger.CloseTime)
+	assert.Len(t, ledger.Hash, 64)
+
+	type expectedOperation struct {
+		txHash     string
+		index      uint
+		successful bool
+		from       string
+		to         string
+		asset      string
+		amount     string
+	}
+	expected := []expectedOperation{
+		{"1a22a64b3aa1c024bd61428ecffed3c4b58f4518ba4a8c9e785aacd818825ec9", 0, true, fixtureSender, fixtureBridgeAccount, fixtureUSDC, "1.5000000"},
+		{"1a22a64b3aa1c024bd61428ecffed3c4b58f4518ba4a8c9e785aacd818825ec9", 1, true, fixtureSender, fixtureBridgeAccount, fixtureUSDC, "2.5000000"},
+		// strict send amount is taken from the result
+		{"1a22a64b3aa1c024bd61428ecffed3c4b58f4518ba4a8c9e785aacd818825ec9", 2, true, fixtureSender, fixtureBridgeAccount, fixtureUSDC, "9.5000000"},
+		{"19d373a2f9cff637ae93bb96fb0254de00491fcf870c6f3d5b2f361c6442ce55", 0, true, fixtureSender, fixtureBridgeAccount, "native", "10.0000000"},
+		{"98a186d0ffb80bd69adae8e77c0cd971a14cb6cfbc518e99cea504d30178f741", 0, false, fixtureSender, fixtureBridgeAccount, "native", "1000000.0000000"},
+		// change trust operation is skipped
+		{"88142d7d6b594109696be065e55ed3f73028994953c19b8c8454f584366938cc", 1, true, fixtureBridgeAccount, fixtureRecipient, fixtureUSDC, "3.0000000"},
+	}
+	require.Len(t, ledger.Operations, len(expected))
+	for i, op := range ledger.Operations {
+		assert.Equal(t, expected[i], expectedOperation{
+			txHash:     op.TransactionHash,
+			index:      op.Index,
+			successful: op.Successful,
+			from:       op.From,
+			to:         op.To,
+			asset:      op.Asset,
+			amount:     op.Amount,
+		})
+		assert.Equal(t, ledger.CloseTime, op.LedgerCloseTime)
+	}
+
+	observer := &Observer{bridgeAccount: fixtureBridgeAccount}
+	assert.False(t, observer.validTransaction(ledger.Operations[0]))
+	assert.True(t, observer.validTransaction(ledger.Operations[3]))
+	assert.False(t, observer.validTransaction(ledger.Operations[4]))
+	assert.True(t, observer.validTransaction(ledger.Operations[5]))
+
+	destination, invalidReason := parseDestination(ledger.Operations[3].Envelope.Memo())
+	assert.Equal(t, "0x2A1D3D4A31c1DCB1ef5f0eA4d6B4f4A2De1a5B15", destination)
+	assert.Empty(t, invalidReason)
+
+	_, err = backend.GetLedger(context.Background(), 1001)
+	assert.Equal(t, ErrLedgerNotClosed, err)
+}
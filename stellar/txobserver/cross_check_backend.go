@@ -0,0 +1,119 @@
+package txobserver
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	slog "github.com/stellar/go/support/log"
+	"github.com/stellar/go/xdr"
+)
+
+// CrossCheckBackend is a LedgerBackend which only returns a ledger once two
+// of its backends agree on the ledger header and on the successful
+// operations relevant to the bridge. Backends are queried in order so the
+// following backends are only used when previous ones fail or lag behind.
+// A single compromised or lagging source cannot make the Observer ingest
+// a ledger.
+type CrossCheckBackend struct {
+	Backends []LedgerBackend
+}
+
+func (b *CrossCheckBackend) GetLedger(ctx context.Context, sequence uint32) (Ledger, error) {
+	log := slog.DefaultLogger.WithFields(slog.F{"service": "stellar_txobserver", "sequence": sequence})
+
+	var ledgers []Ledger
+	var lastErr error
+	notClosed := false
+	for i, backend := range b.Backends {
+		ledger, err := backend.GetLedger(ctx, sequence)
+		if err == ErrLedgerNotClosed {
+			notClosed = true
+			continue
+		} else if err != nil {
+			log.WithFields(slog.F{"backend": i, "error": err}).Warn("Error getting ledger from backend")
+			lastErr = err
+			continue
+		}
+
+		for _, other := range ledgers {
+			if err := compareLedgers(other, ledger); err != nil {
+				return Ledger{}, errors.Wrapf(err, "backend %d disagrees on ledger %d", i, sequence)
+			}
+		}
+		ledgers = append(ledgers, ledger)
+		if len(ledgers) == 2 {
+			return ledgers[0], nil
+		}
+	}
+
+	if notClosed {
+		return Ledger{}, ErrLedgerNotClosed
+	}
+	if lastErr == nil {
+		lastErr = errors.New("not enough backends")
+	}
+	return Ledger{}, errors.Wrapf(lastErr, "ledger %d confirmed by %d backends", sequence, len(ledgers))
+}
+
+// compareLedgers returns an error if the ledgers have different headers or
+// successful operations. Failed transactions are ignored because some
+// backends do not include them.
+func compareLedgers(a, b Ledger) error {
+	if a.Sequence != b.Sequence || a.Hash != b.Hash {
+		return errors.Errorf("ledger hash mismatch: %s != %s", a.Hash, b.Hash)
+	}
+	if !a.CloseTime.Equal(b.CloseTime) {
+		return errors.Errorf("ledger close time mismatch: %s != %s", a.CloseTime, b.CloseTime)
+	}
+
+	aOps, bOps := successfulOperations(a), successfulOperations(b)
+	if len(aOps) != len(bOps) {
+		return errors.Errorf("operation count mismatch: %d != %d", len(aOps), len(bOps))
+	}
+	for i := range aOps {
+		equal, err := sameOperation(aOps[i], bOps[i])
+		if err != nil {
+			return err
+		}
+		if !equal {
+			return errors.Errorf(
+				"operation %d of transaction %s mismatch",
+				aOps[i].Index, aOps[i].TransactionHash,
+			)
+		}
+	}
+	return nil
+}
+
+func successfulOperations(ledger Ledger) []Operation {
+	var operations []Operation
+	for _, op := range ledger.Operations {
+		if op.Successful {
+			operations = append(operations, op)
+		}
+	}
+	return operations
+}
+
+func sameOperation(a, b Operation) (bool, error) {
+	if !a.LedgerCloseTime.Equal(b.LedgerCloseTime) {
+		return false, nil
+	}
+	aEnvelope, err := xdr.MarshalBase64(a.Envelope)
+	if err != nil {
+		return false, errors.Wrap(err, "error encoding envelope")
+	}
+	bEnvelope, err := xdr.MarshalBase64(b.Envelope)
+	if err != nil {
+		return false, errors.Wrap(err, "error encoding envelope")
+	}
+	if aEnvelope != bEnvelope {
+		return false, nil
+	}
+
+	a.LedgerCloseTime, b.LedgerCloseTime = time.Time{}, time.Time{}
+	a.Envelope, b.Envelope = xdr.TransactionEnvelope{}, xdr.TransactionEnvelope{}
+	return reflect.DeepEqual(a, b), nil
+}
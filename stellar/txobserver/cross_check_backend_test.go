@@ -0,0 +1,81 @@
+package txobserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stellar/go/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticBackend struct {
+	ledger Ledger
+	err    error
+}
+
+func (b staticBackend) GetLedger(ctx context.Context, sequence uint32) (Ledger, error) {
+	return b.ledger, b.err
+}
+
+func TestCrossCheckBackend_GetLedger(t *testing.T) {
+	ledger, err := (&MetaBackend{
+		Backend:           loadFixtureBackend(t, "testdata/ledger_1000.xdr"),
+		NetworkPassphrase: network.TestNetworkPassphrase,
+	}).GetLedger(context.Background(), 1000)
+	require.NoError(t, err)
+	// Horizon does not include failed transactions
+	horizonLedger := ledger
+	horizonLedger.Operations = successfulOperations(ledger)
+	forged := ledger
+	forged.Operations = append([]Operation(nil), ledger.Operations...)
+	forged.Operations[3].Amount = "1000.0000000"
+
+	for _, testCase := range []struct {
+		name          string
+		backends      []LedgerBackend
+		expectedError string
+	}{
+		{
+			"agree",
+			[]LedgerBackend{staticBackend{ledger: ledger}, staticBackend{ledger: horizonLedger}},
+			"",
+		},
+		{
+			"failover",
+			[]LedgerBackend{
+				staticBackend{err: ErrLedgerNotClosed},
+				staticBackend{err: context.DeadlineExceeded},
+				staticBackend{ledger: ledger},
+				staticBackend{ledger: ledger},
+			},
+			"",
+		},
+		{
+			"forged operation",
+			[]LedgerBackend{staticBackend{ledger: ledger}, staticBackend{ledger: forged}},
+			"backend 1 disagrees on ledger 1000: operation 0 of transaction 19d373a2f9cff637ae93bb96fb0254de00491fcf870c6f3d5b2f361c6442ce55 mismatch",
+		},
+		{
+			"lagging",
+			[]LedgerBackend{staticBackend{ledger: ledger}, staticBackend{err: ErrLedgerNotClosed}},
+			ErrLedgerNotClosed.Error(),
+		},
+		{
+			"single source",
+			[]LedgerBackend{staticBackend{ledger: ledger}, staticBackend{err: context.DeadlineExceeded}},
+			"ledger 1000 confirmed by 1 backends: context deadline exceeded",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			backend := &CrossCheckBackend{Backends: testCase.backends}
+			result, err := backend.GetLedger(context.Background(), 1000)
+			if testCase.expectedError != "" {
+				require.EqualError(t, err, testCase.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, ledger, result)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package txobserver
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/operations"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+)
+
+// HorizonBackend is a LedgerBackend which pages through the operations of
+// each ledger using the Horizon API
+type HorizonBackend struct {
+	Client *horizonclient.Client
+}
+
+func (b *HorizonBackend) GetLedger(ctx context.Context, sequence uint32) (Ledger, error) {
+	// Get ledger data first to ensure there are no gaps
+	horizonLedger, err := b.Client.LedgerDetail(sequence)
+	if herr, ok := err.(*horizonclient.Error); ok && herr.Response.StatusCode == http.StatusNotFound {
+		// Ledger not found means we reached the latest ledger
+		return Ledger{}, ErrLedgerNotClosed
+	} else if err != nil {
+		return Ledger{}, errors.Wrap(err, "error getting ledger details")
+	}
+
+	ledger := Ledger{
+		Sequence:  uint32(horizonLedger.Sequence),
+		Hash:      horizonLedger.Hash,
+		CloseTime: horizonLedger.ClosedAt,
+	}
+	cursor := ""
+	for ctx.Err() == nil {
+		ops, err := b.Client.Operations(horizonclient.OperationRequest{
+			ForLedger:     uint(sequence),
+			Cursor:        cursor,
+			Limit:         200,
+			IncludeFailed: false,
+			Join:          "transactions",
+		})
+		if err != nil {
+			return Ledger{}, errors.Wrap(err, "error getting operations")
+		}
+
+		if len(ops.Embedded.Records) == 0 {
+			break
+		}
+
+		for _, record := range ops.Embedded.Records {
+			operation, ok, err := horizonOperation(record)
+			if err != nil {
+				return Ledger{}, err
+			}
+			if ok {
+				ledger.Operations = append(ledger.Operations, operation)
+			}
+		}
+
+		cursor = ops.Embedded.Records[len(ops.Embedded.Records)-1].PagingToken()
+	}
+
+	return ledger, ctx.Err()
+}
+
+// horizonOperation converts a Horizon operation to an Operation. It returns
+// false if the operation is not relevant to the bridge.
+func horizonOperation(record operations.Operation) (Operation, bool, error) {
+	var operation Operation
+	var tx *horizon.Transaction
+	switch op := record.(type) {
+	case operations.Payment:
+		operation = horizonPayment(xdr.OperationTypePayment, op)
+		tx = op.Transaction
+	case operations.PathPayment:
+		operation = horizonPayment(xdr.OperationTypePathPaymentStrictReceive, op.Payment)
+		tx = op.Transaction
+	case operations.PathPaymentStrictSend:
+		operation = horizonPayment(xdr.OperationTypePathPaymentStrictSend, op.Payment)
+		tx = op.Transaction
+	case operations.CreateAccount:
+		operation = Operation{
+			Type:   xdr.OperationTypeCreateAccount,
+			From:   op.Funder,
+			To:     op.Account,
+			Asset:  "native",
+			Amount: op.StartingBalance,
+		}
+		tx = op.Transaction
+	case operations.CreateClaimableBalance:
+		operation = Operation{
+			Type:   xdr.OperationTypeCreateClaimableBalance,
+			From:   op.SourceAccount,
+			Asset:  op.Asset,
+			Amount: op.Amount,
+		}
+		tx = op.Transaction
+	default:
+		return Operation{}, false, nil
+	}
+	if tx == nil {
+		return Operation{}, false, errors.Errorf("transaction of operation %s is not joined", record.GetID())
+	}
+
+	id, err := strconv.ParseInt(record.GetID(), 10, 64)
+	if err != nil {
+		return Operation{}, false, errors.Wrapf(err, "invalid operation id: %s", record.GetID())
+	}
+	if err = xdr.SafeUnmarshalBase64(tx.EnvelopeXdr, &operation.Envelope); err != nil {
+		return Operation{}, false, errors.Wrapf(err, "error decoding envelope of transaction %s", tx.Hash)
+	}
+	operation.TransactionHash = tx.Hash
+	operation.Index = uint(toid.Parse(id).OperationOrder - 1)
+	operation.Successful = tx.Successful
+	operation.LedgerCloseTime = tx.LedgerCloseTime
+	return operation, true, nil
+}
+
+func horizonPayment(opType xdr.OperationType, payment operations.Payment) Operation {
+	asset := "native"
+	if payment.Asset.Type != "native" {
+		asset = payment.Asset.Code + ":" + payment.Asset.Issuer
+	}
+	return Operation{
+		Type:   opType,
+		From:   payment.From,
+		To:     payment.To,
+		Asset:  asset,
+		Amount: payment.Amount,
+	}
+}
@@ -1,43 +1,63 @@
 package txobserver
 
 import (
-	"bytes"
 	"context"
-	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
-	"net/http"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 
 	"github.com/pkg/errors"
 	"github.com/stellar/go/clients/horizonclient"
-	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/historyarchive"
 	"github.com/stellar/go/protocols/horizon/operations"
 	slog "github.com/stellar/go/support/log"
 	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
 	"github.com/stellar/starbridge/store"
 )
 
+// DepositID returns the globally unique id of the deposit made by the
+// operation at the given index of a Stellar transaction. Deposits made by
+// the first operation are identified by the transaction hash so the ids of
+// deposits from single operation transactions remain unchanged.
+func DepositID(txHash string, operationIndex uint) string {
+	hash := common.HexToHash(txHash)
+	if operationIndex == 0 {
+		return hex.EncodeToString(hash.Bytes())
+	}
+	operationIndexBytes := [32]byte{}
+	binary.PutUvarint(operationIndexBytes[:], uint64(operationIndex))
+	id := crypto.Keccak256Hash(hash[:], operationIndexBytes[:])
+	return hex.EncodeToString(id.Bytes())
+}
+
 type Observer struct {
 	bridgeAccount string
 
-	client *horizonclient.Client
-	store  *store.DB
-	log    *slog.Entry
+	backend LedgerBackend
+	store   *store.DB
+	log     *slog.Entry
 
 	ledgerSequence uint32
 	catchup        bool
 }
 
+// NewObserver creates an Observer ingesting ledgers from the given backend.
+// If no ledger was ingested yet, ingestion starts from the checkpoint
+// containing startLedger. When startLedger is 0 the history of the bridge
+// account is loaded from Horizon which requires a HorizonBackend.
 func NewObserver(
 	bridgeAccount string,
-	client *horizonclient.Client,
+	backend LedgerBackend,
 	store *store.DB,
+	startLedger uint32,
 ) *Observer {
 	o := &Observer{
 		bridgeAccount: bridgeAccount,
-		client:        client,
+		backend:       backend,
 		store:         store,
 		log:           slog.DefaultLogger.WithField("service", "stellar_txobserver"),
 	}
@@ -49,11 +69,17 @@ func NewObserver(
 		o.log.Fatalf("Unable to load last ledger sequence from db: %v", err)
 	}
 
-	if ledgerSeq == 0 {
+	if ledgerSeq != 0 {
+		o.ledgerSequence = ledgerSeq
+	} else if startLedger != 0 {
+		// Replay from the beginning of the checkpoint so that the whole
+		// checkpoint can be loaded from history archives
+		o.ledgerSequence = historyarchive.NewCheckpointManager(0).GetCheckpointRange(startLedger).Low
+	} else if _, ok := backend.(*HorizonBackend); ok {
 		// Perform catchup on the first call to ProcessNewLedgers
 		o.catchup = true
 	} else {
-		o.ledgerSequence = ledgerSeq
+		o.log.Fatal("Start ledger is required unless ledgers are ingested from Horizon without cross checking")
 	}
 
 	return o
@@ -69,15 +95,11 @@ func (o *Observer) ProcessNewLedgers(ctx context.Context) {
 				o.catchup = false
 			}
 		} else {
-			// Get ledger data first to ensure there are no gaps
-			ledger, err := o.client.LedgerDetail(o.ledgerSequence)
-			if err != nil {
-				if herr, ok := err.(*horizonclient.Error); ok && herr.Response.StatusCode == http.StatusNotFound {
-					// Ledger not found means we reached the latest ledger
-					return
-				} else {
-					o.log.WithField("error", err).Error("Error getting ledger details")
-				}
+			ledger, err := o.backend.GetLedger(ctx, o.ledgerSequence)
+			if err == ErrLedgerNotClosed {
+				return
+			} else if err != nil {
+				o.log.WithFields(slog.F{"error": err, "sequence": o.ledgerSequence}).Error("Error getting ledger")
 			} else {
 				o.log.WithField("sequence", o.ledgerSequence).Info("Processing ledger...")
 
@@ -95,9 +117,10 @@ func (o *Observer) ProcessNewLedgers(ctx context.Context) {
 }
 
 func (o *Observer) catchupLedgers(ctx context.Context) error {
-	root, err := o.client.Root()
+	client := o.backend.(*HorizonBackend).Client
+	root, err := client.Root()
 	if err != nil {
-		o.log.Fatalf("Unable to access Horizon (%s) root resource: %v", o.client.HorizonURL, err)
+		o.log.Fatalf("Unable to access Horizon (%s) root resource: %v", client.HorizonURL, err)
 	}
 
 	ledgerSeq := root.HorizonSequence
@@ -113,11 +136,11 @@ func (o *Observer) catchupLedgers(ctx context.Context) error {
 		_ = o.store.Session.Rollback()
 	}()
 
-	// Process past bridge account payments
+	// Process past bridge account operations
 	cursor := toid.AfterLedger(ledgerSeq).String()
 	var lastOp operations.Operation
 	for ctx.Err() == nil {
-		ops, err := o.client.Payments(horizonclient.OperationRequest{
+		ops, err := client.Operations(horizonclient.OperationRequest{
 			ForAccount:    o.bridgeAccount,
 			Cursor:        cursor,
 			Order:         horizonclient.OrderDesc,
@@ -133,9 +156,17 @@ func (o *Observer) catchupLedgers(ctx context.Context) error {
 			break
 		}
 
-		err = o.ingestPage(ctx, ops.Embedded.Records)
-		if err != nil {
-			return err
+		for _, record := range ops.Embedded.Records {
+			op, ok, err := horizonOperation(record)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			if err = o.ingestOperation(ctx, op); err != nil {
+				return err
+			}
 		}
 
 		lastOp = ops.Embedded.Records[len(ops.Embedded.Records)-1]
@@ -169,7 +200,7 @@ func (o *Observer) catchupLedgers(ctx context.Context) error {
 	return nil
 }
 
-func (o *Observer) ingestLedger(ctx context.Context, ledger horizon.Ledger) error {
+func (o *Observer) ingestLedger(ctx context.Context, ledger Ledger) error {
 	err := o.store.Session.Begin()
 	if err != nil {
 		return errors.Wrap(err, "error starting a transaction")
@@ -178,39 +209,19 @@ func (o *Observer) ingestLedger(ctx context.Context, ledger horizon.Ledger) erro
 		// explicitly ignore return value to make the linter happy
 		_ = o.store.Session.Rollback()
 	}()
-	// Process operations
-	cursor := ""
-	for {
-		ops, err := o.client.Payments(horizonclient.OperationRequest{
-			ForLedger:     uint(ledger.Sequence),
-			Cursor:        cursor,
-			Limit:         200,
-			IncludeFailed: false,
-			Join:          "transactions",
-		})
-		if err != nil {
-			return errors.Wrap(err, "error getting operations")
-		}
 
-		if len(ops.Embedded.Records) == 0 {
-			break
-		}
-
-		err = o.ingestPage(ctx, ops.Embedded.Records)
-		if err != nil {
+	for _, op := range ledger.Operations {
+		if err = o.ingestOperation(ctx, op); err != nil {
 			return err
 		}
-
-		lastOp := ops.Embedded.Records[len(ops.Embedded.Records)-1].GetBase()
-		cursor = lastOp.PagingToken()
 	}
 
-	err = o.store.UpdateLastLedgerSequence(ctx, uint32(ledger.Sequence))
+	err = o.store.UpdateLastLedgerSequence(ctx, ledger.Sequence)
 	if err != nil {
 		return errors.Wrap(err, "error updating last ledger sequence")
 	}
 
-	err = o.store.UpdateLastLedgerCloseTime(ctx, ledger.ClosedAt)
+	err = o.store.UpdateLastLedgerCloseTime(ctx, ledger.CloseTime)
 	if err != nil {
 		return errors.Wrap(err, "error updating last ledger sequence")
 	}
@@ -224,87 +235,129 @@ func (o *Observer) ingestLedger(ctx context.Context, ledger horizon.Ledger) erro
 	return nil
 }
 
-func validTransaction(horizonTx *horizon.Transaction) bool {
+func (o *Observer) validTransaction(op Operation) bool {
 	// ignore failed transactions
-	return horizonTx.Successful &&
-		// Skip inserting transactions with multiple ops. Currently Starbridge
-		// does not create such transactions but it can change in the future.
-		horizonTx.OperationCount == 1
+	if !op.Successful {
+		return false
+	}
+	if len(op.Envelope.Operations()) == 1 {
+		return true
+	}
+	// Skip inserting transactions with multiple ops unless they are
+	// withdrawals built by Starbridge.
+	return o.isWithdrawalTransaction(op.Envelope)
 }
 
-func (o *Observer) ingestPage(ctx context.Context, ops []operations.Operation) error {
-	for _, op := range ops {
-		payment, ok := op.(operations.Payment)
-		// only consider payment operations
-		if !ok {
-			continue
+// isWithdrawalTransaction returns true if the transaction contains a single
+// Payment or CreateClaimableBalance operation sourced from the bridge account
+// and any number of ChangeTrust operations sourced from other accounts.
+func (o *Observer) isWithdrawalTransaction(envelope xdr.TransactionEnvelope) bool {
+	txSource := envelope.SourceAccount().ToAccountId()
+	bridgeOps := 0
+	for _, op := range envelope.Operations() {
+		opSource := txSource.Address()
+		if op.SourceAccount != nil {
+			opSource = op.SourceAccount.ToAccountId().Address()
 		}
-
-		tx := payment.Transaction
-		if !validTransaction(tx) {
-			continue
+		switch op.Body.Type {
+		case xdr.OperationTypePayment, xdr.OperationTypeCreateClaimableBalance:
+			if opSource != o.bridgeAccount {
+				return false
+			}
+			bridgeOps++
+		case xdr.OperationTypeChangeTrust:
+			if opSource == o.bridgeAccount {
+				return false
+			}
+		default:
+			return false
 		}
+	}
+	return bridgeOps == 1
+}
 
-		if payment.From == o.bridgeAccount {
-			if err := o.ingestOutgoingPayment(ctx, payment); err != nil {
-				return err
+func (o *Observer) ingestOperation(ctx context.Context, op Operation) error {
+	from, to := accountID(op.From), accountID(op.To)
+	switch op.Type {
+	case xdr.OperationTypePayment:
+		if from == o.bridgeAccount {
+			if !o.validTransaction(op) {
+				return nil
 			}
-		} else if payment.To == o.bridgeAccount {
-			if err := o.ingestIncomingPayment(ctx, payment); err != nil {
-				return err
+			return o.ingestOutgoingTransaction(ctx, op)
+		} else if to == o.bridgeAccount {
+			// a transaction can contain multiple deposits, each
+			// identified by its operation index
+			if !op.Successful {
+				return nil
 			}
+			return o.ingestIncomingPayment(ctx, op)
+		}
+	case xdr.OperationTypePathPaymentStrictReceive, xdr.OperationTypePathPaymentStrictSend:
+		// path payments are only considered as deposits, the received
+		// asset and amount are credited to the sender
+		if from == o.bridgeAccount || to != o.bridgeAccount || !op.Successful {
+			return nil
+		}
+		return o.ingestIncomingPayment(ctx, op)
+	case xdr.OperationTypeCreateClaimableBalance:
+		// withdrawals in claimable balance mode
+		if from != o.bridgeAccount || !o.validTransaction(op) {
+			return nil
 		}
+		return o.ingestOutgoingTransaction(ctx, op)
 	}
-
 	return nil
 }
 
-func (o *Observer) ingestOutgoingPayment(ctx context.Context, payment operations.Payment) error {
-	if payment.Transaction.MemoType != "hash" || payment.Transaction.Memo == "" {
+// accountID returns the G... address of the given account which can be a
+// muxed M... address
+func accountID(address string) string {
+	muxed, err := xdr.AddressToMuxedAccount(address)
+	if err != nil {
+		return address
+	}
+	return muxed.ToAccountId().Address()
+}
+
+func (o *Observer) ingestOutgoingTransaction(ctx context.Context, op Operation) error {
+	memoHash, ok := op.Envelope.Memo().GetHash()
+	if !ok {
 		return nil
 	}
 
-	memoBytes, err := base64.StdEncoding.DecodeString(payment.Transaction.Memo)
+	envelopeXdr, err := xdr.MarshalBase64(op.Envelope)
 	if err != nil {
-		return errors.Wrapf(err, "error decoding memo: %s", payment.Transaction.Memo)
+		return errors.Wrapf(err, "error encoding envelope: %s", op.TransactionHash)
 	}
 
 	err = o.store.InsertHistoryStellarTransaction(ctx, store.HistoryStellarTransaction{
-		Hash:     payment.Transaction.Hash,
-		Envelope: payment.Transaction.EnvelopeXdr,
-		MemoHash: hex.EncodeToString(memoBytes),
+		Hash:     op.TransactionHash,
+		Envelope: envelopeXdr,
+		MemoHash: hex.EncodeToString(memoHash[:]),
 	})
 	if err != nil {
-		return errors.Wrapf(err, "error inserting history transaction: %s", payment.Transaction.Hash)
+		return errors.Wrapf(err, "error inserting history transaction: %s", op.TransactionHash)
 	}
 
 	return nil
 }
 
-func (o *Observer) ingestIncomingPayment(ctx context.Context, payment operations.Payment) error {
-	var assetString string
-	if payment.Asset.Type == "native" {
-		assetString = "native"
-	} else {
-		assetString = payment.Asset.Code + ":" + payment.Asset.Issuer
-	}
-
-	var destinationAddress string
-	memoBytes, err := base64.StdEncoding.DecodeString(payment.Transaction.Memo)
-	if err == nil && bytes.Equal(common.BytesToAddress(memoBytes).Hash().Bytes(), memoBytes) {
-		destinationAddress = common.BytesToAddress(memoBytes).String()
-	}
+func (o *Observer) ingestIncomingPayment(ctx context.Context, op Operation) error {
+	destinationAddress, invalidReason := parseDestination(op.Envelope.Memo())
 
 	deposit := store.StellarDeposit{
-		ID:          payment.Transaction.Hash,
-		Asset:       assetString,
-		LedgerTime:  payment.LedgerCloseTime.Unix(),
-		Sender:      payment.From,
-		Destination: destinationAddress,
-		Amount:      payment.Amount,
+		ID:         DepositID(op.TransactionHash, op.Index),
+		Asset:      op.Asset,
+		LedgerTime: op.LedgerCloseTime.Unix(),
+		// refunds are sent to the underlying account of muxed senders
+		Sender:        accountID(op.From),
+		Destination:   destinationAddress,
+		Amount:        op.Amount,
+		InvalidReason: invalidReason,
 	}
 	if err := o.store.InsertStellarDeposit(ctx, deposit); err != nil {
-		return errors.Wrapf(err, "error inserting stellar deposit: %s", payment.Transaction.Hash)
+		return errors.Wrapf(err, "error inserting stellar deposit: %s", op.TransactionHash)
 	}
 
 	return nil
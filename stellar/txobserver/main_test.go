@@ -0,0 +1,89 @@
+package txobserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/starbridge/stellar/txbuilder"
+)
+
+func TestClaimableBalanceWithdrawalOperations(t *testing.T) {
+	builder := &txbuilder.Builder{BridgeAccount: fixtureBridgeAccount}
+	envelope, err := builder.BuildClaimableBalanceTransaction(
+		fixtureUSDC, fixtureRecipient, "3", 7, 6, 1700000000, make([]byte, 32),
+	)
+	require.NoError(t, err)
+
+	closeTime := time.Unix(1656000000, 0).UTC()
+	result := xdr.TransactionResult{
+		Result: xdr.TransactionResultResult{
+			Code:    xdr.TransactionResultCodeTxSuccess,
+			Results: &[]xdr.OperationResult{},
+		},
+	}
+	operations, err := transactionOperations(xdr.Hash{1}, envelope, result, closeTime)
+	require.NoError(t, err)
+	require.Len(t, operations, 1)
+
+	op := operations[0]
+	assert.Equal(t, xdr.OperationTypeCreateClaimableBalance, op.Type)
+	assert.True(t, op.Successful)
+	assert.Equal(t, fixtureBridgeAccount, op.From)
+	assert.Empty(t, op.To)
+	assert.Equal(t, fixtureUSDC, op.Asset)
+	assert.Equal(t, "3.0000000", op.Amount)
+
+	observer := &Observer{bridgeAccount: fixtureBridgeAccount}
+	assert.True(t, observer.validTransaction(op))
+	assert.True(t, observer.isWithdrawalTransaction(envelope))
+
+	op.Successful = false
+	assert.False(t, observer.validTransaction(op))
+}
+
+func TestIsWithdrawalTransaction(t *testing.T) {
+	builder := &txbuilder.Builder{BridgeAccount: fixtureBridgeAccount}
+	payment := func(source string) txnbuild.Operation {
+		return &txnbuild.Payment{
+			SourceAccount: source,
+			Destination:   fixtureRecipient,
+			Amount:        "1",
+			Asset:         txnbuild.NativeAsset{},
+		}
+	}
+	changeTrust := func(source string) txnbuild.Operation {
+		line, err := txnbuild.CreditAsset{Code: "USDC", Issuer: fixtureSender}.ToChangeTrustAsset()
+		require.NoError(t, err)
+		return &txnbuild.ChangeTrust{SourceAccount: source, Line: line, Limit: txnbuild.MaxTrustlineLimit}
+	}
+	observer := &Observer{bridgeAccount: fixtureBridgeAccount}
+
+	for _, testCase := range []struct {
+		name       string
+		operations []txnbuild.Operation
+		expected   bool
+	}{
+		{"payment", []txnbuild.Operation{payment(fixtureBridgeAccount)}, true},
+		{"trustline and payment", []txnbuild.Operation{changeTrust(fixtureRecipient), payment(fixtureBridgeAccount)}, true},
+		{"payment not from bridge", []txnbuild.Operation{changeTrust(fixtureRecipient), payment(fixtureRecipient)}, false},
+		{"trustline of bridge", []txnbuild.Operation{changeTrust(fixtureBridgeAccount), payment(fixtureBridgeAccount)}, false},
+		{"two payments", []txnbuild.Operation{payment(fixtureBridgeAccount), payment(fixtureBridgeAccount)}, false},
+		{"trustline only", []txnbuild.Operation{changeTrust(fixtureRecipient)}, false},
+		{
+			"unsupported operation",
+			[]txnbuild.Operation{payment(fixtureBridgeAccount), &txnbuild.BumpSequence{BumpTo: 10}},
+			false,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			envelope, err := builder.BuildWithdrawalTransaction(fixtureRecipient, testCase.operations, 1, 1700000000, nil)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, observer.isWithdrawalTransaction(envelope))
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package txobserver
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/starbridge/store"
+)
+
+// parseDestination extracts the Ethereum recipient from the memo of a
+// deposit transaction. The recipient can be encoded as a hash memo holding
+// the address left padded to 32 bytes or as a text memo holding the base64
+// encoded 20 address bytes, for example
+// "KhB9dKMRTtpRvbpQ/NZ75jQDmPo=" for 0x2a107d74a3114eda51bdba50fcd67be6340398fa.
+// Hex encoded addresses do not fit in the 28 bytes of a text memo. Standard
+// and URL-safe base64 are accepted, with or without padding. If the memo is
+// not valid the reason is returned instead.
+func parseDestination(memo xdr.Memo) (string, string) {
+	var address common.Address
+	switch memo.Type {
+	case xdr.MemoTypeMemoNone:
+		return "", store.InvalidReasonMissingMemo
+	case xdr.MemoTypeMemoHash:
+		memoBytes := memo.MustHash()
+		if !bytes.Equal(common.BytesToAddress(memoBytes[:]).Hash().Bytes(), memoBytes[:]) {
+			return "", store.InvalidReasonInvalidMemo
+		}
+		address = common.BytesToAddress(memoBytes[:])
+	case xdr.MemoTypeMemoText:
+		addressBytes, ok := decodeBase64(memo.MustText())
+		if !ok || len(addressBytes) != common.AddressLength {
+			return "", store.InvalidReasonInvalidMemo
+		}
+		address = common.BytesToAddress(addressBytes)
+	default:
+		return "", store.InvalidReasonUnsupportedMemo
+	}
+
+	if address == (common.Address{}) {
+		return "", store.InvalidReasonInvalidMemo
+	}
+	return address.String(), ""
+}
+
+// decodeBase64 decodes standard or URL-safe base64 with optional padding
+func decodeBase64(text string) ([]byte, bool) {
+	text = strings.TrimRight(text, "=")
+	encoding := base64.RawStdEncoding
+	if strings.ContainsAny(text, "-_") {
+		encoding = base64.RawURLEncoding
+	}
+	decoded, err := encoding.DecodeString(text)
+	return decoded, err == nil
+}
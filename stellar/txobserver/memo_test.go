@@ -0,0 +1,58 @@
+package txobserver
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/starbridge/store"
+)
+
+func TestParseDestination(t *testing.T) {
+	address := common.HexToAddress("0x2a107d74a3114eda51bdba50fcd67be6340398fa")
+	paddedAddress := xdr.Hash(address.Hash())
+	var unpaddedAddress xdr.Hash
+	copy(unpaddedAddress[:], address.Bytes())
+	id := xdr.Uint64(1)
+
+	// text memos are built like wallets do so they fit in a transaction
+	textMemo := func(text string) xdr.Memo {
+		memo, err := txnbuild.MemoText(text).ToXDR()
+		require.NoError(t, err)
+		return memo
+	}
+
+	for _, testCase := range []struct {
+		name                  string
+		memo                  xdr.Memo
+		expectedDestination   string
+		expectedInvalidReason string
+	}{
+		{"none", xdr.Memo{Type: xdr.MemoTypeMemoNone}, "", store.InvalidReasonMissingMemo},
+		{"hash", xdr.Memo{Type: xdr.MemoTypeMemoHash, Hash: &paddedAddress}, address.String(), ""},
+		{"unpadded hash", xdr.Memo{Type: xdr.MemoTypeMemoHash, Hash: &unpaddedAddress}, "", store.InvalidReasonInvalidMemo},
+		{"zero address", xdr.Memo{Type: xdr.MemoTypeMemoHash, Hash: &xdr.Hash{}}, "", store.InvalidReasonInvalidMemo},
+		{"text", textMemo("KhB9dKMRTtpRvbpQ/NZ75jQDmPo="), address.String(), ""},
+		{"unpadded text", textMemo("KhB9dKMRTtpRvbpQ/NZ75jQDmPo"), address.String(), ""},
+		{"url safe text", textMemo(base64.URLEncoding.EncodeToString(address.Bytes())), address.String(), ""},
+		{"short text", textMemo(base64.StdEncoding.EncodeToString(address.Bytes()[:19])), "", store.InvalidReasonInvalidMemo},
+		{"zero address text", textMemo(base64.StdEncoding.EncodeToString(make([]byte, 20))), "", store.InvalidReasonInvalidMemo},
+		{"invalid text", textMemo("not an address"), "", store.InvalidReasonInvalidMemo},
+		{"id", xdr.Memo{Type: xdr.MemoTypeMemoId, Id: &id}, "", store.InvalidReasonUnsupportedMemo},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			destination, invalidReason := parseDestination(testCase.memo)
+			assert.Equal(t, testCase.expectedDestination, destination)
+			assert.Equal(t, testCase.expectedInvalidReason, invalidReason)
+		})
+	}
+
+	// hex encoded addresses do not fit in a text memo
+	_, err := txnbuild.MemoText(address.Hex()[2:]).ToXDR()
+	assert.Error(t, err)
+}
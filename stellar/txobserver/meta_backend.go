@@ -0,0 +1,76 @@
+package txobserver
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/ingest/ledgerbackend"
+)
+
+// MetaBackend is a LedgerBackend which reads LedgerCloseMeta from a stellar/go
+// ledger backend, typically captive stellar-core
+type MetaBackend struct {
+	Backend           ledgerbackend.LedgerBackend
+	NetworkPassphrase string
+}
+
+func (b *MetaBackend) GetLedger(ctx context.Context, sequence uint32) (Ledger, error) {
+	prepared, err := b.Backend.IsPrepared(ctx, ledgerbackend.UnboundedRange(sequence))
+	if err != nil {
+		return Ledger{}, errors.Wrap(err, "error checking if range is prepared")
+	}
+	if !prepared {
+		if err = b.Backend.PrepareRange(ctx, ledgerbackend.UnboundedRange(sequence)); err != nil {
+			return Ledger{}, errors.Wrap(err, "error preparing range")
+		}
+	}
+
+	latest, err := b.Backend.GetLatestLedgerSequence(ctx)
+	if err != nil {
+		return Ledger{}, errors.Wrap(err, "error getting latest ledger sequence")
+	}
+	if sequence > latest {
+		return Ledger{}, ErrLedgerNotClosed
+	}
+
+	meta, err := b.Backend.GetLedger(ctx, sequence)
+	if err != nil {
+		return Ledger{}, errors.Wrapf(err, "error getting ledger %d", sequence)
+	}
+	reader, err := ingest.NewLedgerTransactionReaderFromLedgerCloseMeta(b.NetworkPassphrase, meta)
+	if err != nil {
+		return Ledger{}, errors.Wrapf(err, "error reading ledger %d", sequence)
+	}
+	defer reader.Close()
+
+	header := reader.GetHeader()
+	ledger := Ledger{
+		Sequence:  uint32(header.Header.LedgerSeq),
+		Hash:      header.Hash.HexString(),
+		CloseTime: time.Unix(int64(header.Header.ScpValue.CloseTime), 0).UTC(),
+	}
+	for {
+		tx, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return Ledger{}, errors.Wrapf(err, "error reading transaction in ledger %d", sequence)
+		}
+
+		operations, err := transactionOperations(
+			tx.Result.TransactionHash,
+			tx.Envelope,
+			tx.Result.Result,
+			ledger.CloseTime,
+		)
+		if err != nil {
+			return Ledger{}, err
+		}
+		ledger.Operations = append(ledger.Operations, operations...)
+	}
+
+	return ledger, nil
+}
@@ -0,0 +1,119 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+	"github.com/stellar/go/support/errors"
+)
+
+// ErrAssetMappingConflict is returned when an inserted asset mapping maps a
+// Stellar asset or Ethereum token which is mapped by a mapping that was never
+// disabled
+var ErrAssetMappingConflict = errors.New("asset mapping conflict")
+
+// uniqueViolation is the postgres error code of unique constraint violations
+const uniqueViolation = "23505"
+
+// AssetMapping is a mapping between a Stellar asset and an Ethereum token
+// which was added through the admin api. A mapping applies to deposits made
+// at or after ActivationTime and before DeactivationTime.
+type AssetMapping struct {
+	ID                int64  `db:"id"`
+	StellarAsset      string `db:"stellar_asset"`
+	EthereumToken     string `db:"ethereum_token"`
+	StellarToEthereum string `db:"stellar_to_ethereum"`
+	// FlatFee is the fixed fee in Stellar units charged on every withdrawal
+	FlatFee string `db:"flat_fee"`
+	// FeeBasisPoints is the proportional fee charged on every withdrawal
+	FeeBasisPoints uint32 `db:"fee_basis_points"`
+	// ActivationTime is the unix timestamp from which the mapping is active
+	ActivationTime int64 `db:"activation_time"`
+	// DeactivationTime is the unix timestamp from which the mapping is
+	// disabled. It is not valid if the mapping was never disabled.
+	DeactivationTime sql.NullInt64 `db:"deactivation_time"`
+}
+
+// ActiveAt returns true if the mapping applies to deposits made at the
+// given unix timestamp
+func (m AssetMapping) ActiveAt(timestamp int64) bool {
+	if timestamp < m.ActivationTime {
+		return false
+	}
+	return !m.DeactivationTime.Valid || timestamp < m.DeactivationTime.Int64
+}
+
+func (m *DB) GetAssetMappings(ctx context.Context) ([]AssetMapping, error) {
+	sql := sq.Select("*").From("asset_mappings").OrderBy("id")
+
+	var results []AssetMapping
+	if err := m.Session.Select(ctx, &results, sql); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (m *DB) GetAssetMapping(ctx context.Context, id int64) (AssetMapping, error) {
+	sql := sq.Select("*").From("asset_mappings").Where(sq.Eq{"id": id})
+
+	var result AssetMapping
+	if err := m.Session.Get(ctx, &result, sql); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// InsertAssetMapping inserts the given mapping and returns its id.
+// ErrAssetMappingConflict is returned if the mapping conflicts with a mapping
+// which was never disabled.
+func (m *DB) InsertAssetMapping(ctx context.Context, mapping AssetMapping) (int64, error) {
+	query := sq.Insert("asset_mappings").
+		SetMap(map[string]interface{}{
+			"stellar_asset":       mapping.StellarAsset,
+			"ethereum_token":      strings.ToLower(mapping.EthereumToken),
+			"stellar_to_ethereum": mapping.StellarToEthereum,
+			"flat_fee":            mapping.FlatFee,
+			"fee_basis_points":    mapping.FeeBasisPoints,
+			"activation_time":     mapping.ActivationTime,
+			"deactivation_time":   mapping.DeactivationTime,
+		}).
+		Suffix("RETURNING id")
+
+	var id int64
+	if err := m.Session.Get(ctx, &id, query); err != nil {
+		if pqErr, ok := errors.Cause(err).(*pq.Error); ok && pqErr.Code == uniqueViolation {
+			return 0, ErrAssetMappingConflict
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// DisableAssetMapping sets the deactivation time of the given mapping.
+// sql.ErrNoRows is returned if the mapping does not exist or was already
+// disabled.
+func (m *DB) DisableAssetMapping(ctx context.Context, id int64, deactivationTime int64) error {
+	query := sq.Update("asset_mappings").
+		Set("deactivation_time", deactivationTime).
+		Where(sq.Eq{"id": id}).
+		Where("deactivation_time IS NULL")
+
+	result, err := m.Session.Exec(ctx, query)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"context"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// BridgeFee is the fee collected by the validators on the withdrawal of a
+// deposit. Amounts are expressed in the units of the withdrawal chain.
+type BridgeFee struct {
+	DepositChain Blockchain `db:"deposit_chain"`
+	DepositID    string     `db:"deposit_id"`
+	// Asset is the Stellar asset or Ethereum token which is withdrawn
+	Asset string `db:"asset"`
+	// Amount is the fee which was deducted from the deposit
+	Amount string `db:"amount"`
+	// WithdrawalAmount is the amount received by the recipient
+	WithdrawalAmount string `db:"withdrawal_amount"`
+}
+
+func (m *DB) GetBridgeFee(ctx context.Context, depositChain Blockchain, depositID string) (BridgeFee, error) {
+	sql := sq.Select("*").From("bridge_fees").Where(map[string]interface{}{
+		"deposit_chain": depositChain,
+		"deposit_id":    strings.ToLower(depositID),
+	})
+
+	var result BridgeFee
+	if err := m.Session.Get(ctx, &result, sql); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (m *DB) UpsertBridgeFee(ctx context.Context, fee BridgeFee) error {
+	query := sq.Insert("bridge_fees").
+		SetMap(map[string]interface{}{
+			"deposit_chain":     fee.DepositChain,
+			"deposit_id":        strings.ToLower(fee.DepositID),
+			"asset":             fee.Asset,
+			"amount":            fee.Amount,
+			"withdrawal_amount": fee.WithdrawalAmount,
+		}).
+		Suffix("ON CONFLICT (deposit_id, deposit_chain) " +
+			"DO UPDATE SET " +
+			"asset=EXCLUDED.asset, amount=EXCLUDED.amount, withdrawal_amount=EXCLUDED.withdrawal_amount",
+		)
+
+	_, err := m.Session.Exec(ctx, query)
+	return err
+}
@@ -0,0 +1,159 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// DepositVolume is the number of deposits and their total amount within a
+// time window
+type DepositVolume struct {
+	Count int64 `db:"count"`
+	// Amount is the decimal encoded sum of all deposit amounts
+	Amount string `db:"amount"`
+}
+
+// AmountRange is an inclusive range of decimal encoded deposit amounts. Empty
+// bounds are unbounded.
+type AmountRange struct {
+	Min string
+	Max string
+}
+
+// DepositVolumeFilter selects the deposits which precede a given deposit
+// within a time window
+type DepositVolumeFilter struct {
+	// Asset restricts the deposits to the given Stellar asset or Ethereum
+	// token if it is not empty
+	Asset string
+	// Sender restricts the deposits to the given sender if it is not empty
+	Sender string
+	// Since is the unix timestamp from which deposits are included
+	Since int64
+	// Ranges excludes the deposits of the given assets with an amount
+	// outside of the range of the asset
+	Ranges map[string]AmountRange
+	// Values weights the amount of each deposit with the value of its asset
+	// if it is not empty. Deposits of assets without a value are not
+	// included in the amount.
+	Values map[string]string
+}
+
+func depositVolumeQuery(table, assetColumn, timeColumn string, filter DepositVolumeFilter) sq.SelectBuilder {
+	query := sq.Select("COUNT(*) AS count").
+		Column(depositAmountColumn(assetColumn, filter.Values)).
+		From(table).
+		Where(sq.GtOrEq{timeColumn: filter.Since})
+	// Ethereum addresses are stored with mixed case checksums
+	if filter.Asset != "" {
+		query = query.Where(sq.Eq{"LOWER(" + assetColumn + ")": strings.ToLower(filter.Asset)})
+	}
+	if filter.Sender != "" {
+		query = query.Where(sq.Eq{"LOWER(sender)": strings.ToLower(filter.Sender)})
+	}
+
+	// Sorted so that the generated query is deterministic
+	assets := make([]string, 0, len(filter.Ranges))
+	for asset := range filter.Ranges {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+	for _, asset := range assets {
+		amountRange := filter.Ranges[asset]
+		var outside sq.Or
+		if amountRange.Min != "" {
+			outside = append(outside, sq.Expr("amount::numeric < ?::numeric", amountRange.Min))
+		}
+		if amountRange.Max != "" {
+			outside = append(outside, sq.Expr("amount::numeric > ?::numeric", amountRange.Max))
+		}
+		if len(outside) == 0 {
+			continue
+		}
+		excluded, args, _ := sq.And{
+			sq.Eq{"LOWER(" + assetColumn + ")": strings.ToLower(asset)},
+			outside,
+		}.ToSql()
+		query = query.Where("NOT "+excluded, args...)
+	}
+	return query
+}
+
+func depositAmountColumn(assetColumn string, values map[string]string) sq.Sqlizer {
+	if len(values) == 0 {
+		return sq.Expr("COALESCE(SUM(amount::numeric), 0)::text AS amount")
+	}
+
+	// Sorted so that the generated query is deterministic
+	assets := make([]string, 0, len(values))
+	for asset := range values {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+	var cases strings.Builder
+	args := make([]interface{}, 0, 2*len(assets))
+	for _, asset := range assets {
+		cases.WriteString(" WHEN LOWER(" + assetColumn + ") = ? THEN amount::numeric * ?::numeric")
+		args = append(args, strings.ToLower(asset), values[asset])
+	}
+	return sq.Expr("COALESCE(SUM(CASE"+cases.String()+" END), 0)::text AS amount", args...)
+}
+
+// stellarDepositVolumeQuery selects the Stellar deposits with a valid
+// destination matching the filter which were made before the given deposit.
+// Deposits in the same ledger are ordered by id.
+func stellarDepositVolumeQuery(before StellarDeposit, filter DepositVolumeFilter) sq.SelectBuilder {
+	return depositVolumeQuery("stellar_deposits", "asset", "ledger_time", filter).
+		Where(sq.Eq{"invalid_reason": ""}).
+		Where(sq.Or{
+			sq.Lt{"ledger_time": before.LedgerTime},
+			sq.And{
+				sq.Eq{"ledger_time": before.LedgerTime},
+				sq.Lt{"id": strings.ToLower(before.ID)},
+			},
+		})
+}
+
+// ethereumDepositVolumeQuery selects the Ethereum deposits matching the
+// filter which were made before the given deposit. Deposits in the same
+// block are ordered by log index.
+func ethereumDepositVolumeQuery(before EthereumDeposit, filter DepositVolumeFilter) sq.SelectBuilder {
+	return depositVolumeQuery("ethereum_deposits", "token", "block_time", filter).
+		Where(sq.Or{
+			sq.Lt{"block_number": before.BlockNumber},
+			sq.And{
+				sq.Eq{"block_number": before.BlockNumber},
+				sq.Lt{"log_index": before.LogIndex},
+			},
+		})
+}
+
+// GetStellarDepositVolume returns the volume of Stellar deposits matching
+// the filter which were made before the given deposit. Deposits with an
+// invalid destination are excluded because they can only be refunded.
+func (m *DB) GetStellarDepositVolume(ctx context.Context, before StellarDeposit, filter DepositVolumeFilter) (DepositVolume, error) {
+	sql := stellarDepositVolumeQuery(before, filter)
+
+	var result DepositVolume
+	if err := m.Session.Get(ctx, &result, sql); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// GetEthereumDepositVolume returns the volume of Ethereum deposits matching
+// the filter which were made before the given deposit
+func (m *DB) GetEthereumDepositVolume(ctx context.Context, before EthereumDeposit, filter DepositVolumeFilter) (DepositVolume, error) {
+	sql := ethereumDepositVolumeQuery(before, filter)
+
+	var result DepositVolume
+	if err := m.Session.Get(ctx, &result, sql); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
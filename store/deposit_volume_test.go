@@ -0,0 +1,90 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStellarDepositVolumeQuery(t *testing.T) {
+	query, args, err := stellarDepositVolumeQuery(
+		StellarDeposit{ID: "ABCD", LedgerTime: 1000},
+		DepositVolumeFilter{
+			Asset: "native",
+			Since: 900,
+			Ranges: map[string]AmountRange{
+				"native": {Min: "1"},
+				"USDC:GABPNFVU2NHSHRCJ4HLW6BAMVOT7YIHMJBBQ3DMSSPRKEYDSYGRE5JWH": {Min: "2", Max: "10"},
+			},
+		},
+	).ToSql()
+	require.NoError(t, err)
+
+	// Deposits in the same ledger are ordered by id and deposits outside of
+	// the amount range of their asset or with an invalid destination are
+	// excluded
+	assert.Equal(t,
+		"SELECT COUNT(*) AS count, COALESCE(SUM(amount::numeric), 0)::text AS amount FROM stellar_deposits "+
+			"WHERE ledger_time >= ? AND LOWER(asset) = ? "+
+			"AND NOT (LOWER(asset) = ? AND (amount::numeric < ?::numeric OR amount::numeric > ?::numeric)) "+
+			"AND NOT (LOWER(asset) = ? AND (amount::numeric < ?::numeric)) "+
+			"AND invalid_reason = ? "+
+			"AND (ledger_time < ? OR (ledger_time = ? AND id < ?))",
+		query,
+	)
+	assert.Equal(t, []interface{}{
+		int64(900), "native",
+		"usdc:gabpnfvu2nhshrcj4hlw6bamvot7yihmjbbq3dmssprkeydsygre5jwh", "2", "10",
+		"native", "1",
+		"",
+		int64(1000), int64(1000), "abcd",
+	}, args)
+}
+
+func TestEthereumDepositVolumeQuery(t *testing.T) {
+	query, args, err := ethereumDepositVolumeQuery(
+		EthereumDeposit{BlockNumber: 12, LogIndex: 3},
+		DepositVolumeFilter{Sender: "0xABC", Since: 900},
+	).ToSql()
+	require.NoError(t, err)
+
+	// Deposits in the same block are ordered by log index
+	assert.Equal(t,
+		"SELECT COUNT(*) AS count, COALESCE(SUM(amount::numeric), 0)::text AS amount FROM ethereum_deposits "+
+			"WHERE block_time >= ? AND LOWER(sender) = ? "+
+			"AND (block_number < ? OR (block_number = ? AND log_index < ?))",
+		query,
+	)
+	assert.Equal(t, []interface{}{int64(900), "0xabc", uint64(12), uint64(12), uint(3)}, args)
+}
+
+func TestDepositVolumeQueryValues(t *testing.T) {
+	query, args, err := ethereumDepositVolumeQuery(
+		EthereumDeposit{BlockNumber: 12, LogIndex: 3},
+		DepositVolumeFilter{
+			Since: 900,
+			Values: map[string]string{
+				"0xdef": "0.5",
+				"0xABC": "2",
+			},
+		},
+	).ToSql()
+	require.NoError(t, err)
+
+	// Amounts are weighted with the value of their asset and deposits of
+	// assets without a value are not summed
+	assert.Equal(t,
+		"SELECT COUNT(*) AS count, COALESCE(SUM(CASE "+
+			"WHEN LOWER(token) = ? THEN amount::numeric * ?::numeric "+
+			"WHEN LOWER(token) = ? THEN amount::numeric * ?::numeric END), 0)::text AS amount "+
+			"FROM ethereum_deposits "+
+			"WHERE block_time >= ? "+
+			"AND (block_number < ? OR (block_number = ? AND log_index < ?))",
+		query,
+	)
+	assert.Equal(t, []interface{}{
+		"0xabc", "2", "0xdef", "0.5",
+		int64(900), uint64(12), uint64(12), uint(3),
+	}, args)
+}
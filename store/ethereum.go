@@ -28,6 +28,9 @@ type EthereumDeposit struct {
 	// BlockNumber is the sequence number of the block containing the deposit
 	// transaction
 	BlockNumber uint64 `db:"block_number"`
+	// BlockHash is the hash of the block containing the deposit transaction.
+	// It is used to detect deposits which were removed by chain reorganizations.
+	BlockHash string `db:"block_hash"`
 	// BlockTime is the unix timestamp of the deposit
 	BlockTime int64 `db:"block_time"`
 }
@@ -42,6 +45,9 @@ type EthereumSignature struct {
 	Expiration int64  `db:"expiration"`
 	Action     Action `db:"requested_action"`
 	DepositID  string `db:"deposit_id"`
+	// Version is the bridge contract version the signature was created for.
+	// Signatures become invalid once the validator set is updated.
+	Version uint64 `db:"version"`
 }
 
 func (m *DB) GetEthereumDeposit(ctx context.Context, id string) (EthereumDeposit, error) {
@@ -64,12 +70,16 @@ func (m *DB) InsertEthereumDeposit(ctx context.Context, deposit EthereumDeposit)
 			"hash":         deposit.Hash,
 			"log_index":    deposit.LogIndex,
 			"block_number": deposit.BlockNumber,
+			"block_hash":   strings.ToLower(deposit.BlockHash),
 			"block_time":   deposit.BlockTime,
 			"amount":       deposit.Amount,
 			"destination":  deposit.Destination,
 			"sender":       deposit.Sender,
 			"token":        deposit.Token,
-		})
+		}).
+		// Deposits can be inserted both by the ethereum ingester and when
+		// they are requested over the http api
+		Suffix("ON CONFLICT (id) DO NOTHING")
 
 	_, err := m.Session.Exec(ctx, query)
 	return err
@@ -99,13 +109,42 @@ func (m *DB) UpsertEthereumSignature(ctx context.Context, newSig EthereumSignatu
 			"deposit_id":       strings.ToLower(newSig.DepositID),
 			"token":            newSig.Token,
 			"amount":           newSig.Amount,
+			"version":          newSig.Version,
 		}).
 		Suffix("ON CONFLICT (requested_action, deposit_id) " +
 			"DO UPDATE SET " +
 			"signature=EXCLUDED.signature, address=EXCLUDED.address, " +
-			"expiration=EXCLUDED.expiration, token=EXCLUDED.token, amount=EXCLUDED.amount",
+			"expiration=EXCLUDED.expiration, token=EXCLUDED.token, amount=EXCLUDED.amount, " +
+			"version=EXCLUDED.version",
 		)
 
 	_, err := m.Session.Exec(ctx, query)
 	return err
 }
+
+// InvalidateEthereumDeposit removes the given deposit along with cached
+// signatures for the deposit and rejects its signature requests. It is used
+// when the block containing the deposit is no longer part of the canonical
+// chain.
+func (m *DB) InvalidateEthereumDeposit(ctx context.Context, id string) error {
+	id = strings.ToLower(id)
+	if err := m.rejectReorgedSignatureRequests(ctx, sq.Eq{"deposit_id": id}); err != nil {
+		return err
+	}
+	for _, del := range []sq.DeleteBuilder{
+		sq.Delete("outgoing_stellar_transactions").Where(map[string]interface{}{
+			"requested_action": Withdraw,
+			"deposit_id":       id,
+		}),
+		sq.Delete("ethereum_signatures").Where(map[string]interface{}{
+			"requested_action": Refund,
+			"deposit_id":       id,
+		}),
+		sq.Delete("ethereum_deposits").Where(sq.Eq{"id": id}),
+	} {
+		if _, err := m.Session.Exec(ctx, del); err != nil {
+			return err
+		}
+	}
+	return nil
+}
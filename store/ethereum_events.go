@@ -0,0 +1,243 @@
+package store
+
+import (
+	"context"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+)
+
+// EthereumBlock is a block which was ingested from the ethereum
+// blockchain
+type EthereumBlock struct {
+	Number uint64 `db:"number"`
+	Hash   string `db:"hash"`
+	// Time is the unix timestamp of the block
+	Time int64 `db:"time"`
+}
+
+// EthereumWithdrawal represents a Withdraw event emitted by the bridge
+// smart contract
+type EthereumWithdrawal struct {
+	// ID is the id of the bridge transfer which was withdrawn
+	ID        string `db:"id"`
+	Token     string `db:"token"`
+	Recipient string `db:"recipient"`
+	Amount    string `db:"amount"`
+	// Hash is the hash of the transaction containing the withdrawal
+	Hash        string `db:"hash"`
+	LogIndex    uint   `db:"log_index"`
+	BlockNumber uint64 `db:"block_number"`
+}
+
+// EthereumSetPaused represents a SetPaused event emitted by the bridge
+// smart contract
+type EthereumSetPaused struct {
+	Value       uint8  `db:"value"`
+	Hash        string `db:"hash"`
+	LogIndex    uint   `db:"log_index"`
+	BlockNumber uint64 `db:"block_number"`
+}
+
+// EthereumStellarAsset represents a RegisterStellarAsset event emitted by the
+// bridge smart contract
+type EthereumStellarAsset struct {
+	Token       string `db:"token"`
+	Hash        string `db:"hash"`
+	LogIndex    uint   `db:"log_index"`
+	BlockNumber uint64 `db:"block_number"`
+}
+
+// EthereumSigners represents a RegisterSigners event emitted by the bridge
+// smart contract
+type EthereumSigners struct {
+	Version      uint64         `db:"version"`
+	Signers      pq.StringArray `db:"signers"`
+	MinThreshold uint8          `db:"min_threshold"`
+	Hash         string         `db:"hash"`
+	LogIndex     uint           `db:"log_index"`
+	BlockNumber  uint64         `db:"block_number"`
+}
+
+// GetLastEthereumBlock returns the most recent block ingested from the
+// ethereum blockchain. sql.ErrNoRows is returned if no blocks were ingested.
+func (m *DB) GetLastEthereumBlock(ctx context.Context) (EthereumBlock, error) {
+	sql := sq.Select("*").From("ethereum_ingested_blocks").
+		OrderBy("number DESC").
+		Limit(1)
+
+	var result EthereumBlock
+	if err := m.Session.Get(ctx, &result, sql); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// GetEthereumBlocks returns all ingested blocks in descending order
+func (m *DB) GetEthereumBlocks(ctx context.Context) ([]EthereumBlock, error) {
+	sql := sq.Select("*").From("ethereum_ingested_blocks").OrderBy("number DESC")
+
+	var results []EthereumBlock
+	if err := m.Session.Select(ctx, &results, sql); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (m *DB) InsertEthereumBlock(ctx context.Context, block EthereumBlock) error {
+	query := sq.Insert("ethereum_ingested_blocks").
+		SetMap(map[string]interface{}{
+			"number": block.Number,
+			"hash":   strings.ToLower(block.Hash),
+			"time":   block.Time,
+		})
+
+	_, err := m.Session.Exec(ctx, query)
+	return err
+}
+
+// DeleteEthereumEventsFrom removes all blocks and events ingested from the
+// ethereum blockchain starting from the given block number. It is used to roll
+// back ingestion when a chain reorganization is detected.
+func (m *DB) DeleteEthereumEventsFrom(ctx context.Context, blockNumber uint64) error {
+	// Signature requests for removed deposits can never be fulfilled
+	err := m.rejectReorgedSignatureRequests(
+		ctx,
+		"deposit_id IN (SELECT id FROM ethereum_deposits WHERE block_number >= ?)",
+		blockNumber,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range []string{
+		"ethereum_deposits",
+		"ethereum_withdrawals",
+		"ethereum_set_paused_events",
+		"ethereum_stellar_assets",
+		"ethereum_signers",
+	} {
+		del := sq.Delete(table).Where(sq.GtOrEq{"block_number": blockNumber})
+		if _, err := m.Session.Exec(ctx, del); err != nil {
+			return err
+		}
+	}
+
+	del := sq.Delete("ethereum_ingested_blocks").Where(sq.GtOrEq{"number": blockNumber})
+	_, err = m.Session.Exec(ctx, del)
+	return err
+}
+
+func (m *DB) GetEthereumWithdrawal(ctx context.Context, id string) (EthereumWithdrawal, error) {
+	sql := sq.Select("*").From("ethereum_withdrawals").Where(
+		sq.Eq{"id": strings.ToLower(id)},
+	)
+
+	var result EthereumWithdrawal
+	if err := m.Session.Get(ctx, &result, sql); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (m *DB) InsertEthereumWithdrawal(ctx context.Context, withdrawal EthereumWithdrawal) error {
+	query := sq.Insert("ethereum_withdrawals").
+		SetMap(map[string]interface{}{
+			"id":           strings.ToLower(withdrawal.ID),
+			"token":        withdrawal.Token,
+			"recipient":    withdrawal.Recipient,
+			"amount":       withdrawal.Amount,
+			"hash":         withdrawal.Hash,
+			"log_index":    withdrawal.LogIndex,
+			"block_number": withdrawal.BlockNumber,
+		})
+
+	_, err := m.Session.Exec(ctx, query)
+	return err
+}
+
+// GetLastEthereumSetPaused returns the most recent SetPaused event.
+// sql.ErrNoRows is returned if the bridge was never paused.
+func (m *DB) GetLastEthereumSetPaused(ctx context.Context) (EthereumSetPaused, error) {
+	sql := sq.Select("*").From("ethereum_set_paused_events").
+		OrderBy("block_number DESC", "log_index DESC").
+		Limit(1)
+
+	var result EthereumSetPaused
+	if err := m.Session.Get(ctx, &result, sql); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (m *DB) InsertEthereumSetPaused(ctx context.Context, event EthereumSetPaused) error {
+	query := sq.Insert("ethereum_set_paused_events").
+		SetMap(map[string]interface{}{
+			"value":        event.Value,
+			"hash":         event.Hash,
+			"log_index":    event.LogIndex,
+			"block_number": event.BlockNumber,
+		})
+
+	_, err := m.Session.Exec(ctx, query)
+	return err
+}
+
+func (m *DB) GetEthereumStellarAssets(ctx context.Context) ([]EthereumStellarAsset, error) {
+	sql := sq.Select("*").From("ethereum_stellar_assets").OrderBy("block_number", "log_index")
+
+	var results []EthereumStellarAsset
+	if err := m.Session.Select(ctx, &results, sql); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (m *DB) InsertEthereumStellarAsset(ctx context.Context, asset EthereumStellarAsset) error {
+	query := sq.Insert("ethereum_stellar_assets").
+		SetMap(map[string]interface{}{
+			"token":        asset.Token,
+			"hash":         asset.Hash,
+			"log_index":    asset.LogIndex,
+			"block_number": asset.BlockNumber,
+		})
+
+	_, err := m.Session.Exec(ctx, query)
+	return err
+}
+
+// GetLatestEthereumSigners returns the most recent validator set configuration.
+// sql.ErrNoRows is returned if no RegisterSigners events were ingested.
+func (m *DB) GetLatestEthereumSigners(ctx context.Context) (EthereumSigners, error) {
+	sql := sq.Select("*").From("ethereum_signers").
+		OrderBy("version DESC").
+		Limit(1)
+
+	var result EthereumSigners
+	if err := m.Session.Get(ctx, &result, sql); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (m *DB) InsertEthereumSigners(ctx context.Context, signers EthereumSigners) error {
+	query := sq.Insert("ethereum_signers").
+		SetMap(map[string]interface{}{
+			"version":       signers.Version,
+			"signers":       signers.Signers,
+			"min_threshold": signers.MinThreshold,
+			"hash":          signers.Hash,
+			"log_index":     signers.LogIndex,
+			"block_number":  signers.BlockNumber,
+		})
+
+	_, err := m.Session.Exec(ctx, query)
+	return err
+}
@@ -0,0 +1,50 @@
+-- +migrate Up
+CREATE TABLE ethereum_ingested_blocks (
+    number BIGINT NOT NULL PRIMARY KEY,
+    hash TEXT NOT NULL
+);
+
+CREATE TABLE ethereum_withdrawals (
+    id TEXT NOT NULL PRIMARY KEY,
+    token TEXT NOT NULL,
+    recipient TEXT NOT NULL,
+    amount TEXT NOT NULL,
+    hash TEXT NOT NULL,
+    log_index INTEGER NOT NULL,
+    block_number BIGINT NOT NULL
+);
+CREATE INDEX ethereum_withdrawals_block_number ON ethereum_withdrawals USING BTREE(block_number);
+
+CREATE TABLE ethereum_set_paused_events (
+    value INTEGER NOT NULL,
+    hash TEXT NOT NULL,
+    log_index INTEGER NOT NULL,
+    block_number BIGINT NOT NULL,
+    PRIMARY KEY (block_number, log_index)
+);
+
+CREATE TABLE ethereum_stellar_assets (
+    token TEXT NOT NULL PRIMARY KEY,
+    hash TEXT NOT NULL,
+    log_index INTEGER NOT NULL,
+    block_number BIGINT NOT NULL
+);
+
+CREATE TABLE ethereum_signers (
+    version BIGINT NOT NULL PRIMARY KEY,
+    signers TEXT[] NOT NULL,
+    min_threshold INTEGER NOT NULL,
+    hash TEXT NOT NULL,
+    log_index INTEGER NOT NULL,
+    block_number BIGINT NOT NULL
+);
+
+CREATE INDEX ethereum_deposits_block_number ON ethereum_deposits USING BTREE(block_number);
+
+-- +migrate Down
+drop index ethereum_deposits_block_number;
+drop table ethereum_signers cascade;
+drop table ethereum_stellar_assets cascade;
+drop table ethereum_set_paused_events cascade;
+drop table ethereum_withdrawals cascade;
+drop table ethereum_ingested_blocks cascade;
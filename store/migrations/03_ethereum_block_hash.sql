@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE ethereum_deposits ADD COLUMN block_hash TEXT NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE ethereum_deposits DROP COLUMN block_hash;
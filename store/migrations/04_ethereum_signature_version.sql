@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE ethereum_signatures ADD COLUMN version BIGINT NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE ethereum_signatures DROP COLUMN version;
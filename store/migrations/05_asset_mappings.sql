@@ -0,0 +1,12 @@
+-- +migrate Up
+CREATE TABLE asset_mappings (
+    id BIGSERIAL PRIMARY KEY,
+    stellar_asset TEXT NOT NULL,
+    ethereum_token TEXT NOT NULL,
+    stellar_to_ethereum TEXT NOT NULL,
+    activation_time BIGINT NOT NULL,
+    deactivation_time BIGINT
+);
+
+-- +migrate Down
+drop table asset_mappings cascade;
@@ -0,0 +1,17 @@
+-- +migrate Up
+ALTER TABLE asset_mappings ADD COLUMN flat_fee TEXT NOT NULL DEFAULT '';
+ALTER TABLE asset_mappings ADD COLUMN fee_basis_points INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE bridge_fees (
+    deposit_chain character varying(40) NOT NULL,
+    deposit_id TEXT NOT NULL,
+    asset TEXT NOT NULL,
+    amount TEXT NOT NULL,
+    withdrawal_amount TEXT NOT NULL,
+    PRIMARY KEY (deposit_id, deposit_chain)
+);
+
+-- +migrate Down
+drop table bridge_fees cascade;
+ALTER TABLE asset_mappings DROP COLUMN fee_basis_points;
+ALTER TABLE asset_mappings DROP COLUMN flat_fee;
@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE stellar_deposits ADD COLUMN invalid_reason TEXT NOT NULL DEFAULT '';
+-- deposits ingested before memos were validated have an empty destination
+UPDATE stellar_deposits SET invalid_reason = 'invalid_memo' WHERE destination = '';
+CREATE INDEX stellar_deposits_invalid_reason ON stellar_deposits (ledger_time) WHERE invalid_reason <> '';
+
+-- +migrate Down
+DROP INDEX stellar_deposits_invalid_reason;
+ALTER TABLE stellar_deposits DROP COLUMN invalid_reason;
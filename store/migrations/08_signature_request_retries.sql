@@ -0,0 +1,12 @@
+-- +migrate Up
+ALTER TABLE signature_requests ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
+-- next_attempt_at is the unix timestamp from which the request can be retried
+ALTER TABLE signature_requests ADD COLUMN next_attempt_at BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE signature_requests ADD COLUMN last_error TEXT NOT NULL DEFAULT '';
+CREATE INDEX signature_requests_next_attempt_at ON signature_requests (next_attempt_at);
+
+-- +migrate Down
+DROP INDEX signature_requests_next_attempt_at;
+ALTER TABLE signature_requests DROP COLUMN last_error;
+ALTER TABLE signature_requests DROP COLUMN next_attempt_at;
+ALTER TABLE signature_requests DROP COLUMN attempts;
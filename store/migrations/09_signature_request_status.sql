@@ -0,0 +1,20 @@
+-- +migrate Up
+-- Processed signature requests are kept so their outcome can be inspected
+ALTER TABLE signature_requests ADD COLUMN status character varying(20) NOT NULL DEFAULT 'pending';
+ALTER TABLE signature_requests ADD COLUMN created_at BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE signature_requests ADD COLUMN updated_at BIGINT NOT NULL DEFAULT 0;
+UPDATE signature_requests SET
+    created_at = extract(epoch from now())::bigint,
+    updated_at = extract(epoch from now())::bigint;
+DROP INDEX signature_requests_next_attempt_at;
+CREATE INDEX signature_requests_status_next_attempt_at ON signature_requests (status, next_attempt_at);
+CREATE INDEX signature_requests_updated_at ON signature_requests (updated_at);
+
+-- +migrate Down
+DELETE FROM signature_requests WHERE status != 'pending';
+DROP INDEX signature_requests_updated_at;
+DROP INDEX signature_requests_status_next_attempt_at;
+CREATE INDEX signature_requests_next_attempt_at ON signature_requests (next_attempt_at);
+ALTER TABLE signature_requests DROP COLUMN updated_at;
+ALTER TABLE signature_requests DROP COLUMN created_at;
+ALTER TABLE signature_requests DROP COLUMN status;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE ethereum_ingested_blocks ADD COLUMN time BIGINT NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE ethereum_ingested_blocks DROP COLUMN time;
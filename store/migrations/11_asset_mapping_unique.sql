@@ -0,0 +1,8 @@
+-- +migrate Up
+-- Concurrent additions through the admin api cannot map the same asset twice
+CREATE UNIQUE INDEX asset_mappings_stellar_asset_active ON asset_mappings (stellar_asset) WHERE deactivation_time IS NULL;
+CREATE UNIQUE INDEX asset_mappings_ethereum_token_active ON asset_mappings (ethereum_token) WHERE deactivation_time IS NULL;
+
+-- +migrate Down
+DROP INDEX asset_mappings_ethereum_token_active;
+DROP INDEX asset_mappings_stellar_asset_active;
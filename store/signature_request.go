@@ -2,14 +2,16 @@ package store
 
 import (
 	"context"
+	"database/sql"
 	"strings"
 
 	sq "github.com/Masterminds/squirrel"
 )
 
 type (
-	Blockchain string
-	Action     string
+	Blockchain             string
+	Action                 string
+	SignatureRequestStatus string
 )
 
 const (
@@ -22,44 +24,263 @@ const (
 	Refund   Action = "refund"
 )
 
+const (
+	// SignatureRequestPending requests are waiting to be processed by the
+	// worker, possibly after a failed attempt
+	SignatureRequestPending SignatureRequestStatus = "pending"
+	// SignatureRequestSucceeded requests were signed
+	SignatureRequestSucceeded SignatureRequestStatus = "succeeded"
+	// SignatureRequestRejected requests cannot be signed because the
+	// deposit is not eligible for the requested action
+	SignatureRequestRejected SignatureRequestStatus = "rejected"
+	// SignatureRequestFailed requests ran out of attempts. They are only
+	// processed again when retried through the admin api.
+	SignatureRequestFailed SignatureRequestStatus = "failed"
+	// SignatureRequestCancelled requests were cancelled through the admin
+	// api
+	SignatureRequestCancelled SignatureRequestStatus = "cancelled"
+)
+
+// ValidSignatureRequestStatus returns true if the given status is known
+func ValidSignatureRequestStatus(status SignatureRequestStatus) bool {
+	switch status {
+	case SignatureRequestPending, SignatureRequestSucceeded, SignatureRequestRejected,
+		SignatureRequestFailed, SignatureRequestCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
 type SignatureRequest struct {
-	DepositChain Blockchain `db:"deposit_chain"`
-	Action       Action     `db:"requested_action"`
-	DepositID    string     `db:"deposit_id"`
+	DepositChain Blockchain             `db:"deposit_chain"`
+	Action       Action                 `db:"requested_action"`
+	DepositID    string                 `db:"deposit_id"`
+	Status       SignatureRequestStatus `db:"status"`
+	// Attempts is the number of failed attempts to process the request
+	Attempts int `db:"attempts"`
+	// NextAttemptAt is the unix timestamp from which the request can be
+	// processed
+	NextAttemptAt int64 `db:"next_attempt_at"`
+	// LastError is the error of the last failed attempt
+	LastError string `db:"last_error"`
+	// CreatedAt and UpdatedAt are unix timestamps
+	CreatedAt int64 `db:"created_at"`
+	UpdatedAt int64 `db:"updated_at"`
 }
 
-func (m *DB) InsertSignatureRequest(ctx context.Context, request SignatureRequest) error {
+// ReorgedDepositError is the last error of signature requests rejected
+// because the block containing the deposit is no longer part of the
+// canonical Ethereum chain
+const ReorgedDepositError = "deposit reorged"
+
+// SignatureRequestChannel is the Postgres notification channel on which
+// processed signature requests are announced
+const SignatureRequestChannel = "signature_requests"
+
+// SignatureRequestPayload returns the notification payload identifying the
+// given signature request
+func SignatureRequestPayload(request SignatureRequest) string {
+	return string(request.DepositChain) + ":" + string(request.Action) + ":" + strings.ToLower(request.DepositID)
+}
+
+func signatureRequestKey(request SignatureRequest) map[string]interface{} {
+	return map[string]interface{}{
+		"deposit_chain":    request.DepositChain,
+		"deposit_id":       strings.ToLower(request.DepositID),
+		"requested_action": request.Action,
+	}
+}
+
+// InsertSignatureRequest queues the given signature request at the given
+// unix timestamp. Requests which were already processed successfully or
+// rejected are queued again, for example to sign a new transaction when the
+// previous one can no longer be submitted. Failed and cancelled requests
+// can only be queued again through the admin api.
+func (m *DB) InsertSignatureRequest(ctx context.Context, request SignatureRequest, now int64) error {
 	sql := sq.Insert("signature_requests").SetMap(map[string]interface{}{
 		"deposit_chain":    request.DepositChain,
 		"requested_action": request.Action,
 		"deposit_id":       strings.ToLower(request.DepositID),
-	})
+		"status":           SignatureRequestPending,
+		"created_at":       now,
+		"updated_at":       now,
+	}).Suffix(
+		"ON CONFLICT (deposit_id, deposit_chain, requested_action) DO UPDATE SET "+
+			"status = EXCLUDED.status, attempts = 0, next_attempt_at = 0, updated_at = EXCLUDED.updated_at "+
+			"WHERE signature_requests.status IN (?, ?)",
+		SignatureRequestSucceeded, SignatureRequestRejected,
+	)
 	_, err := m.Session.Exec(ctx, sql)
-	// Ignore duplicate violations
-	if err != nil && !IsDuplicateError(err) {
-		return err
-	}
+	return err
+}
 
-	return nil
+// GetSignatureRequest returns the given signature request. sql.ErrNoRows is
+// returned if it does not exist.
+func (m *DB) GetSignatureRequest(ctx context.Context, request SignatureRequest) (SignatureRequest, error) {
+	query := sq.Select("*").From("signature_requests").Where(signatureRequestKey(request))
+
+	var result SignatureRequest
+	err := m.Session.Get(ctx, &result, query)
+	return result, err
 }
 
-func (m *DB) GetSignatureRequests(ctx context.Context) ([]SignatureRequest, error) {
-	sql := sq.Select("*").From("signature_requests")
+// GetSignatureRequests returns the most recently updated signature requests
+// with the given status, or with any status if it is empty
+func (m *DB) GetSignatureRequests(ctx context.Context, status SignatureRequestStatus, limit uint64) ([]SignatureRequest, error) {
+	query := sq.Select("*").From("signature_requests").
+		OrderBy("updated_at DESC").
+		Limit(limit)
+	if status != "" {
+		query = query.Where(sq.Eq{"status": status})
+	}
 
 	var results []SignatureRequest
-	if err := m.Session.Select(ctx, &results, sql); err != nil {
+	if err := m.Session.Select(ctx, &results, query); err != nil {
 		return nil, err
 	}
-
 	return results, nil
 }
 
-func (m *DB) DeleteSignatureRequest(ctx context.Context, request SignatureRequest) error {
-	del := sq.Delete("signature_requests").Where(map[string]interface{}{
-		"deposit_chain":    request.DepositChain,
-		"deposit_id":       strings.ToLower(request.DepositID),
-		"requested_action": request.Action,
-	})
-	_, err := m.Session.Exec(ctx, del)
+// ClaimSignatureRequest locks the next signature request which is due at
+// the given unix timestamp, skipping requests locked by other workers. It
+// must be called within a transaction and the lock is held until the
+// transaction ends. sql.ErrNoRows is returned if no request is due.
+func (m *DB) ClaimSignatureRequest(ctx context.Context, now int64) (SignatureRequest, error) {
+	query := sq.Select("*").From("signature_requests").
+		Where(sq.Eq{"status": SignatureRequestPending}).
+		Where(sq.LtOrEq{"next_attempt_at": now}).
+		OrderBy("next_attempt_at").
+		Limit(1).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	var result SignatureRequest
+	err := m.Session.Get(ctx, &result, query)
+	return result, err
+}
+
+// LockDeposit blocks until it acquires a lock on the given deposit which is
+// held until the current transaction ends. It ensures that signing for a
+// deposit is serial across all workers sharing the database.
+func (m *DB) LockDeposit(ctx context.Context, chain Blockchain, depositID string) error {
+	query := sq.Select().Column(sq.Expr(
+		"pg_advisory_xact_lock(hashtext(?))",
+		string(chain)+":"+strings.ToLower(depositID),
+	))
+	_, err := m.Session.Exec(ctx, query)
+	return err
+}
+
+// UpdateSignatureRequest stores the status, attempts, next attempt time,
+// last error and update time of the given request
+func (m *DB) UpdateSignatureRequest(ctx context.Context, request SignatureRequest) error {
+	query := sq.Update("signature_requests").
+		SetMap(map[string]interface{}{
+			"status":          request.Status,
+			"attempts":        request.Attempts,
+			"next_attempt_at": request.NextAttemptAt,
+			"last_error":      request.LastError,
+			"updated_at":      request.UpdatedAt,
+		}).
+		Where(signatureRequestKey(request))
+	_, err := m.Session.Exec(ctx, query)
+	return err
+}
+
+// NotifySignatureRequest announces that the given signature request was
+// processed. Within a transaction the notification is only delivered when
+// the transaction is committed.
+func (m *DB) NotifySignatureRequest(ctx context.Context, request SignatureRequest) error {
+	query := sq.Select().Column(sq.Expr(
+		"pg_notify(?, ?)",
+		SignatureRequestChannel,
+		SignatureRequestPayload(request),
+	))
+	_, err := m.Session.Exec(ctx, query)
+	return err
+}
+
+// RetrySignatureRequest queues the given request again with no previous
+// attempts unless it was processed successfully. sql.ErrNoRows is returned
+// if no such request can be retried.
+func (m *DB) RetrySignatureRequest(ctx context.Context, request SignatureRequest, now int64) error {
+	query := sq.Update("signature_requests").
+		SetMap(map[string]interface{}{
+			"status":          SignatureRequestPending,
+			"attempts":        0,
+			"next_attempt_at": 0,
+			"updated_at":      now,
+		}).
+		Where(signatureRequestKey(request)).
+		Where(sq.NotEq{"status": SignatureRequestSucceeded})
+	return m.execSignatureRequestUpdate(ctx, query)
+}
+
+// CancelSignatureRequest cancels the given pending request. sql.ErrNoRows is
+// returned if no such request is pending.
+func (m *DB) CancelSignatureRequest(ctx context.Context, request SignatureRequest, now int64) error {
+	query := sq.Update("signature_requests").
+		SetMap(map[string]interface{}{
+			"status":     SignatureRequestCancelled,
+			"updated_at": now,
+		}).
+		Where(signatureRequestKey(request)).
+		Where(sq.Eq{"status": SignatureRequestPending})
+	return m.execSignatureRequestUpdate(ctx, query)
+}
+
+func (m *DB) execSignatureRequestUpdate(ctx context.Context, query sq.UpdateBuilder) error {
+	result, err := m.Session.Exec(ctx, query)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// rejectReorgedSignatureRequests rejects the signature requests of the
+// Ethereum deposits matching the given predicate. The requests are kept so
+// that the reason why they were never signed can be inspected.
+func (m *DB) rejectReorgedSignatureRequests(ctx context.Context, pred interface{}, args ...interface{}) error {
+	_, err := m.Session.Exec(ctx, rejectReorgedSignatureRequestsQuery(pred, args...))
 	return err
 }
+
+// rejectReorgedSignatureRequestsQuery only matches requests which can still be
+// processed. Requests in a final state keep their outcome, a request which
+// succeeded before the reorg must not be reported as never signed.
+func rejectReorgedSignatureRequestsQuery(pred interface{}, args ...interface{}) sq.UpdateBuilder {
+	return sq.Update("signature_requests").
+		SetMap(map[string]interface{}{
+			"status":          SignatureRequestRejected,
+			"next_attempt_at": 0,
+			"last_error":      ReorgedDepositError,
+			"updated_at":      sq.Expr("extract(epoch from now())::bigint"),
+		}).
+		Where(sq.Eq{"deposit_chain": Ethereum}).
+		Where(sq.Eq{"status": []SignatureRequestStatus{SignatureRequestPending, SignatureRequestFailed}}).
+		Where(pred, args...)
+}
+
+// SignatureRequestExists returns true if the given signature request is
+// pending and has not yet been processed by the worker.
+func (m *DB) SignatureRequestExists(ctx context.Context, request SignatureRequest) (bool, error) {
+	stmt := sq.Select("1").From("signature_requests").
+		Where(signatureRequestKey(request)).
+		Where(sq.Eq{"status": SignatureRequestPending})
+
+	var result int
+	err := m.Session.Get(ctx, &result, stmt)
+	if err == nil {
+		return true, nil
+	} else if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return false, err
+}
@@ -0,0 +1,31 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRejectReorgedSignatureRequestsQuery(t *testing.T) {
+	query, args, err := rejectReorgedSignatureRequestsQuery(
+		"deposit_id IN (SELECT id FROM ethereum_deposits WHERE block_number >= ?)",
+		uint64(12),
+	).ToSql()
+	require.NoError(t, err)
+
+	// Succeeded, rejected and cancelled requests keep their status
+	assert.Equal(t,
+		"UPDATE signature_requests SET last_error = ?, next_attempt_at = ?, status = ?, "+
+			"updated_at = extract(epoch from now())::bigint "+
+			"WHERE deposit_chain = ? AND status IN (?,?) "+
+			"AND deposit_id IN (SELECT id FROM ethereum_deposits WHERE block_number >= ?)",
+		query,
+	)
+	assert.Equal(t, []interface{}{
+		ReorgedDepositError, 0, SignatureRequestRejected,
+		Ethereum, SignatureRequestPending, SignatureRequestFailed,
+		uint64(12),
+	}, args)
+	assert.NotContains(t, args, SignatureRequestSucceeded)
+}
@@ -9,8 +9,9 @@ import (
 )
 
 type StellarDeposit struct {
-	// ID is the globally unique id for this deposit
-	// and is equal to deposit transaction hash
+	// ID is the globally unique id for this deposit which is
+	// derived from the deposit transaction hash and operation
+	// index, see txobserver.DepositID
 	ID string `db:"id"`
 	// Asset is the string encoding of the Stellar assets
 	// which were deposited to the bridge
@@ -24,8 +25,24 @@ type StellarDeposit struct {
 	Amount string `db:"amount"`
 	// LedgerTime is the unix timestamp of the deposit
 	LedgerTime int64 `db:"ledger_time"`
+	// InvalidReason is set if the memo of the deposit does not contain
+	// a valid Ethereum recipient. Such deposits can be refunded without
+	// waiting for the withdrawal window to expire.
+	InvalidReason string `db:"invalid_reason"`
 }
 
+const (
+	// InvalidReasonMissingMemo indicates that the deposit transaction has
+	// no memo
+	InvalidReasonMissingMemo = "missing_memo"
+	// InvalidReasonUnsupportedMemo indicates that the memo is neither a
+	// hash nor a text memo
+	InvalidReasonUnsupportedMemo = "unsupported_memo_type"
+	// InvalidReasonInvalidMemo indicates that the memo does not contain
+	// an Ethereum address
+	InvalidReasonInvalidMemo = "invalid_memo"
+)
+
 type HistoryStellarTransaction struct {
 	Hash     string `db:"hash"`
 	Envelope string `db:"envelope"`
@@ -56,16 +73,38 @@ func (m *DB) GetStellarDeposit(ctx context.Context, id string) (StellarDeposit,
 	return result, nil
 }
 
+// GetInvalidStellarDeposits returns the most recent deposits with an
+// invalid memo, optionally filtered by sender
+func (m *DB) GetInvalidStellarDeposits(ctx context.Context, sender string, limit uint64) ([]StellarDeposit, error) {
+	sql := sq.Select("*").From("stellar_deposits").
+		Where(sq.NotEq{"invalid_reason": ""}).
+		OrderBy("ledger_time DESC", "id").
+		Limit(limit)
+	if sender != "" {
+		sql = sql.Where(sq.Eq{"sender": sender})
+	}
+
+	var results []StellarDeposit
+	if err := m.Session.Select(ctx, &results, sql); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
 func (m *DB) InsertStellarDeposit(ctx context.Context, deposit StellarDeposit) error {
 	query := sq.Insert("stellar_deposits").
 		SetMap(map[string]interface{}{
-			"id":          strings.ToLower(deposit.ID),
-			"ledger_time": deposit.LedgerTime,
-			"amount":      deposit.Amount,
-			"destination": deposit.Destination,
-			"sender":      deposit.Sender,
-			"asset":       deposit.Asset,
-		})
+			"id":             strings.ToLower(deposit.ID),
+			"ledger_time":    deposit.LedgerTime,
+			"amount":         deposit.Amount,
+			"destination":    deposit.Destination,
+			"sender":         deposit.Sender,
+			"asset":          deposit.Asset,
+			"invalid_reason": deposit.InvalidReason,
+		}).
+		// ledgers can be replayed from a history checkpoint
+		Suffix("ON CONFLICT (id) DO NOTHING")
 
 	_, err := m.Session.Exec(ctx, query)
 	return err
@@ -77,7 +116,8 @@ func (m *DB) InsertHistoryStellarTransaction(ctx context.Context, tx HistoryStel
 			"hash":      strings.ToLower(tx.Hash),
 			"envelope":  tx.Envelope,
 			"memo_hash": strings.ToLower(tx.MemoHash),
-		})
+		}).
+		Suffix("ON CONFLICT (hash) DO NOTHING")
 
 	_, err := m.Session.Exec(ctx, query)
 	return err